@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"github.com/DjordjeVuckovic/weather-radar/internal/service"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/middleware"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/resp"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type ApiKeyApi struct {
+	keyService *service.ApiKeyService
+}
+
+func BindApiKeyApi(s *server.Server, keyService *service.ApiKeyService, auth *Authenticator) {
+	api := &ApiKeyApi{keyService: keyService}
+
+	s.POST("/api/v1/api-keys", api.handleApiKeyIssue, auth.requireRole(service.RoleAdmin))
+	s.GET("/api/v1/api-keys", api.handleApiKeyList, auth.requireRole(service.RoleAdmin))
+	s.DELETE("/api/v1/api-keys/{id}", api.handleApiKeyRevoke, auth.requireRole(service.RoleAdmin))
+}
+
+// TenantResolver resolves API keys to their tenant and plan for middleware.ApiKeyAuth.
+func TenantResolver(keyService *service.ApiKeyService) middleware.TenantResolver {
+	return func(ctx context.Context, key string) (middleware.Tenant, error) {
+		apiKey, rpm, err := keyService.Resolve(ctx, key)
+		if err != nil {
+			return middleware.Tenant{}, err
+		}
+		return middleware.Tenant{ID: apiKey.Tenant, Plan: apiKey.Plan, RequestsPerMinute: rpm}, nil
+	}
+}
+
+// handleApiKeyIssue issues an API key to a tenant.
+// @Summary Issue API key
+// @Description Issue an API key for a tenant on a plan. The key is only returned in this response.
+// @Description Requests with the key in the X-Api-Key header are rate limited by the plan, per tenant.
+// @Description Requires the admin role.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param key body dto.ApiKeyReq true "API key"
+// @Success 201 {object} dto.ApiKeyResp
+// @Failure 400 {object} result.Err "Validation error"
+// @Failure 401 {object} result.Err "Unauthorized"
+// @Failure 403 {object} result.Err "Forbidden"
+// @Router /api/v1/api-keys [post]
+// @Security BasicAuth
+// @Security BearerAuth
+func (api *ApiKeyApi) handleApiKeyIssue(w http.ResponseWriter, r *http.Request) error {
+	var req dto.ApiKeyReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return result.ValidationErr("Invalid request data")
+	}
+
+	issued, err := api.keyService.Issue(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return resp.WriteJSON(w, http.StatusCreated, issued)
+}
+
+// handleApiKeyList lists issued API keys, oldest first.
+// @Summary List API keys
+// @Description List the keys of a tenant, or of all tenants. Keys are identified by their prefix.
+// @Description Requires the admin role.
+// @Tags api-keys
+// @Produce json
+// @Param tenant query string false "Tenant"
+// @Success 200 {array} model.ApiKey
+// @Failure 401 {object} result.Err "Unauthorized"
+// @Failure 403 {object} result.Err "Forbidden"
+// @Router /api/v1/api-keys [get]
+// @Security BasicAuth
+// @Security BearerAuth
+func (api *ApiKeyApi) handleApiKeyList(w http.ResponseWriter, r *http.Request) error {
+	keys, err := api.keyService.List(r.Context(), r.URL.Query().Get("tenant"))
+	if err != nil {
+		return err
+	}
+
+	return resp.WriteJSON(w, http.StatusOK, keys)
+}
+
+// handleApiKeyRevoke revokes an API key.
+// @Summary Revoke API key
+// @Description Requires the admin role.
+// @Tags api-keys
+// @Param id path string true "API key id"
+// @Success 204
+// @Failure 400 {object} result.Err "Validation error"
+// @Failure 401 {object} result.Err "Unauthorized"
+// @Failure 403 {object} result.Err "Forbidden"
+// @Failure 404 {object} result.Err "API key not found"
+// @Router /api/v1/api-keys/{id} [delete]
+// @Security BasicAuth
+// @Security BearerAuth
+func (api *ApiKeyApi) handleApiKeyRevoke(w http.ResponseWriter, r *http.Request) error {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return result.ValidationErr("API key id must be a valid UUID")
+	}
+
+	if err := api.keyService.Revoke(r.Context(), id); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"github.com/DjordjeVuckovic/weather-radar/internal/service"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/middleware"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"net/http"
+)
+
+const authRealm = "weather-radar"
+
+// Authenticator authenticates requests to protected endpoints with a bearer token from our SSO,
+// when JWT is configured, or with Basic Auth.
+type Authenticator struct {
+	basic server.MiddlewareFunc
+	jwt   server.MiddlewareFunc
+}
+
+// NewAuthenticator creates an authenticator for the users of the auth service. Bearer tokens are
+// rejected when jwtConfig is nil.
+func NewAuthenticator(authService *service.AuthService, jwtConfig *middleware.JWTConfig) *Authenticator {
+	auth := &Authenticator{
+		basic: middleware.BasicAuth(authRealm, basicAuthenticator(authService)),
+	}
+	if jwtConfig != nil {
+		auth.jwt = middleware.JWT(*jwtConfig)
+	}
+	return auth
+}
+
+// requireRole authenticates the request and checks the caller has the role, or a role implying it.
+func (a *Authenticator) requireRole(role service.Role) server.MiddlewareFunc {
+	granting := service.RolesGranting(role)
+	roles := make([]string, 0, len(granting))
+	for _, r := range granting {
+		roles = append(roles, string(r))
+	}
+	authorize := middleware.RequireRole(roles...)
+
+	return func(next server.HandlerFunc) server.HandlerFunc {
+		basic := a.basic(authorize(next))
+		if a.jwt == nil {
+			return basic
+		}
+		bearer := a.jwt(authorize(next))
+		return func(w http.ResponseWriter, r *http.Request) error {
+			if _, ok := middleware.BearerToken(r); ok {
+				return bearer(w, r)
+			}
+			return basic(w, r)
+		}
+	}
+}
+
+func basicAuthenticator(authService *service.AuthService) middleware.BasicAuthFunc {
+	return func(username, password string) (middleware.Identity, bool) {
+		user, ok := authService.ValidateBasicAuth(service.AuthCredentials{Username: username, Password: password})
+		if !ok {
+			return middleware.Identity{}, false
+		}
+		granted := user.GrantedRoles()
+		roles := make([]string, 0, len(granted))
+		for _, role := range granted {
+			roles = append(roles, string(role))
+		}
+		return middleware.Identity{Subject: user.Username, Roles: roles}, true
+	}
+}
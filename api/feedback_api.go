@@ -0,0 +1,332 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/middleware"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/resp"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/util"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// handleWeatherFeedback handles feedback submission for weather.
+// @Summary Submit weather feedback
+// @Description Submit feedback about the weather in a specific city. The date must not be in the future,
+// @Description the city must be known to the weather providers and the optional rating must be between 1 and 5.
+// @Description Requires the feedback-writer role.
+// @Tags feedback
+// @Accept json
+// @Produce json
+// @Param feedback body dto.WeatherFeedbackReq true "Weather feedback"
+// @Success 200 {object} dto.WeatherFeedbackResp
+// @Failure 400 {object} result.Err "Invalid request data"
+// @Failure 401 {object} result.Err "Unauthorized"
+// @Failure 403 {object} result.Err "Forbidden"
+// @Router /api/v1/weather/feedback [post]
+// @Security BasicAuth
+// @Security BearerAuth
+func (api *WeatherApi) handleWeatherFeedback(w http.ResponseWriter, r *http.Request) error {
+	var feedback dto.WeatherFeedbackReq
+	if err := json.NewDecoder(r.Body).Decode(&feedback); err != nil {
+		return result.ValidationErr("Invalid request data")
+	}
+	ctx := r.Context()
+	identity, _ := middleware.IdentityFromContext(ctx)
+	fb, err := api.weatherService.SubmitFeedback(ctx, identity.Subject, &feedback)
+	if err != nil {
+		return err
+	}
+
+	response := dto.WeatherFeedbackResp{
+		Message: "Feedback submitted successfully",
+		ID:      fb.ID,
+	}
+	return resp.WriteJSON(w, http.StatusOK, response)
+}
+
+// handleFeedbackList lists submitted feedback, newest first.
+// @Summary List weather feedback
+// @Description List submitted feedback filtered by city and date range.
+// @Description Requires the reader role.
+// @Tags feedback
+// @Produce json
+// @Param city query string false "City name, case insensitive"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param offset query int false "Number of feedback entries to skip"
+// @Success 200 {object} model.FeedbackPage
+// @Failure 400 {object} result.Err "Validation error"
+// @Failure 401 {object} result.Err "Unauthorized"
+// @Failure 403 {object} result.Err "Forbidden"
+// @Router /api/v1/weather/feedback [get]
+// @Security BasicAuth
+// @Security BearerAuth
+func (api *WeatherApi) handleFeedbackList(w http.ResponseWriter, r *http.Request) error {
+	filter, err := parseFeedbackFilter(r)
+	if err != nil {
+		return err
+	}
+
+	page, err := api.weatherService.ListFeedback(r.Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	return resp.WriteJSON(w, http.StatusOK, page)
+}
+
+// handleFeedbackGet returns a single feedback entry.
+// @Summary Get weather feedback
+// @Description Requires the reader role.
+// @Tags feedback
+// @Produce json
+// @Param id path string true "Feedback id"
+// @Success 200 {object} model.Feedback
+// @Failure 400 {object} result.Err "Validation error"
+// @Failure 401 {object} result.Err "Unauthorized"
+// @Failure 403 {object} result.Err "Forbidden"
+// @Failure 404 {object} result.Err "Feedback not found"
+// @Router /api/v1/weather/feedback/{id} [get]
+// @Security BasicAuth
+// @Security BearerAuth
+func (api *WeatherApi) handleFeedbackGet(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseFeedbackID(r)
+	if err != nil {
+		return err
+	}
+
+	fb, err := api.weatherService.GetFeedback(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return resp.WriteJSON(w, http.StatusOK, fb)
+}
+
+// handleFeedbackUpdate replaces the content of a feedback entry.
+// @Summary Update weather feedback
+// @Description Requires the feedback-writer role.
+// @Tags feedback
+// @Accept json
+// @Produce json
+// @Param id path string true "Feedback id"
+// @Param feedback body dto.WeatherFeedbackReq true "Weather feedback"
+// @Success 200 {object} model.Feedback
+// @Failure 400 {object} result.Err "Validation error"
+// @Failure 401 {object} result.Err "Unauthorized"
+// @Failure 403 {object} result.Err "Forbidden"
+// @Failure 404 {object} result.Err "Feedback not found"
+// @Router /api/v1/weather/feedback/{id} [put]
+// @Security BasicAuth
+// @Security BearerAuth
+func (api *WeatherApi) handleFeedbackUpdate(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseFeedbackID(r)
+	if err != nil {
+		return err
+	}
+
+	var feedback dto.WeatherFeedbackReq
+	if err := json.NewDecoder(r.Body).Decode(&feedback); err != nil {
+		return result.ValidationErr("Invalid request data")
+	}
+
+	fb, err := api.weatherService.UpdateFeedback(r.Context(), id, &feedback)
+	if err != nil {
+		return err
+	}
+
+	return resp.WriteJSON(w, http.StatusOK, fb)
+}
+
+// handleFeedbackDelete deletes a feedback entry.
+// @Summary Delete weather feedback
+// @Description Requires the admin role.
+// @Tags feedback
+// @Param id path string true "Feedback id"
+// @Success 204
+// @Failure 400 {object} result.Err "Validation error"
+// @Failure 401 {object} result.Err "Unauthorized"
+// @Failure 403 {object} result.Err "Forbidden"
+// @Failure 404 {object} result.Err "Feedback not found"
+// @Router /api/v1/weather/feedback/{id} [delete]
+// @Security BasicAuth
+// @Security BearerAuth
+func (api *WeatherApi) handleFeedbackDelete(w http.ResponseWriter, r *http.Request) error {
+	id, err := parseFeedbackID(r)
+	if err != nil {
+		return err
+	}
+
+	if err := api.weatherService.DeleteFeedback(r.Context(), id); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func parseFeedbackID(r *http.Request) (uuid.UUID, error) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return uuid.Nil, result.ValidationErr("Feedback id must be a valid UUID")
+	}
+	return id, nil
+}
+
+// handleFeedbackReport compares feedback with the weather served for the same city and date.
+// @Summary Feedback accuracy report
+// @Description Aggregate rating distributions, reported vs served temperature deltas and condition
+// @Description mismatch rates per city and provider. Feedback is compared with the weather served
+// @Description on its date closest to the time it was submitted. Temperatures are in °C.
+// @Description Requires the reader role.
+// @Tags feedback
+// @Produce json
+// @Param city query string false "City name, case insensitive"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} model.FeedbackReport
+// @Failure 400 {object} result.Err "Validation error"
+// @Failure 401 {object} result.Err "Unauthorized"
+// @Failure 403 {object} result.Err "Forbidden"
+// @Router /api/v1/weather/feedback/report [get]
+// @Security BasicAuth
+// @Security BearerAuth
+func (api *WeatherApi) handleFeedbackReport(w http.ResponseWriter, r *http.Request) error {
+	filter, err := parseFeedbackScope(r)
+	if err != nil {
+		return err
+	}
+
+	report, err := api.weatherService.GetFeedbackReport(r.Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	return resp.WriteJSON(w, http.StatusOK, report)
+}
+
+// handleFeedbackExport streams all feedback matching the filters, oldest first.
+// @Summary Export weather feedback
+// @Description Stream feedback as CSV or newline delimited JSON, filtered by city and date range.
+// @Description CSV text starting with =, +, -, @, tab or carriage return is prefixed with ' so that
+// @Description spreadsheets do not run it as a formula. Requires the reader role.
+// @Tags feedback
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Export format: csv (default) or ndjson"
+// @Param city query string false "City name, case insensitive"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Success 200 {array} model.Feedback
+// @Failure 400 {object} result.Err "Validation error"
+// @Failure 401 {object} result.Err "Unauthorized"
+// @Failure 403 {object} result.Err "Forbidden"
+// @Router /api/v1/weather/feedback/export [get]
+// @Security BasicAuth
+// @Security BearerAuth
+func (api *WeatherApi) handleFeedbackExport(w http.ResponseWriter, r *http.Request) error {
+	filter, err := parseFeedbackScope(r)
+	if err != nil {
+		return err
+	}
+
+	format := r.URL.Query().Get("format")
+	writer, contentType, ok := newFeedbackWriter(format, w)
+	if !ok {
+		return result.ValidationErr("Format query param must be one of: csv, ndjson")
+	}
+	if format == "" {
+		format = ExportFormatCSV
+	}
+
+	flusher, _ := r.Context().Value(middleware.CtxFlusherKey).(http.Flusher)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="feedback.%s"`, format))
+	w.WriteHeader(http.StatusOK)
+
+	flush := func() error {
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		flusher.Flush() // Send the chunk immediately to the client
+		return nil
+	}
+
+	written := 0
+	err = api.weatherService.ExportFeedback(r.Context(), filter, func(fb *model.Feedback) error {
+		if err := writer.Write(fb); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		// The status is already sent, the client sees a truncated export.
+		slog.Error("Failed to export feedback", slog.Int("written", written), slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// parseFeedbackScope parses the city and date range query params.
+func parseFeedbackScope(r *http.Request) (model.FeedbackFilter, error) {
+	query := r.URL.Query()
+	filter := model.FeedbackFilter{
+		City: query.Get("city"),
+		From: query.Get("from"),
+		To:   query.Get("to"),
+	}
+
+	if filter.From != "" {
+		if _, err := util.ParseDate(filter.From); err != nil {
+			return filter, result.ValidationErr("From query param must be a date in YYYY-MM-DD format")
+		}
+	}
+	if filter.To != "" {
+		if _, err := util.ParseDate(filter.To); err != nil {
+			return filter, result.ValidationErr("To query param must be a date in YYYY-MM-DD format")
+		}
+	}
+	if filter.From != "" && filter.To != "" && filter.From > filter.To {
+		return filter, result.ValidationErr("From date must not be after to date")
+	}
+	return filter, nil
+}
+
+func parseFeedbackFilter(r *http.Request) (model.FeedbackFilter, error) {
+	filter, err := parseFeedbackScope(r)
+	if err != nil {
+		return filter, err
+	}
+	filter.Limit = model.DefaultFeedbackPageSize
+
+	query := r.URL.Query()
+	if limitParam := query.Get("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > model.MaxFeedbackPageSize {
+			return filter, result.ValidationErr(fmt.Sprintf("Limit query param must be a number between 1 and %d", model.MaxFeedbackPageSize))
+		}
+		filter.Limit = limit
+	}
+	if offsetParam := query.Get("offset"); offsetParam != "" {
+		offset, err := strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			return filter, result.ValidationErr("Offset query param must be a non-negative number")
+		}
+		filter.Offset = offset
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+
+	// exportFlushEvery is the number of feedback entries written per chunk.
+	exportFlushEvery = 100
+)
+
+var feedbackCSVHeader = []string{
+	"id",
+	"date",
+	"city",
+	"message",
+	"rating",
+	"observed_condition",
+	"reported_temp",
+	"subject",
+	"created_at",
+	"updated_at",
+}
+
+// feedbackWriter writes feedback in an export format. Flush writes buffered entries to the
+// underlying writer.
+type feedbackWriter interface {
+	Write(fb *model.Feedback) error
+	Flush() error
+}
+
+// newFeedbackWriter returns the writer and content type of an export format.
+func newFeedbackWriter(format string, w io.Writer) (feedbackWriter, string, bool) {
+	switch format {
+	case "", ExportFormatCSV:
+		return &csvFeedbackWriter{w: csv.NewWriter(w)}, "text/csv; charset=utf-8", true
+	case ExportFormatNDJSON:
+		return &ndjsonFeedbackWriter{enc: json.NewEncoder(w)}, "application/x-ndjson", true
+	default:
+		return nil, "", false
+	}
+}
+
+type csvFeedbackWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvFeedbackWriter) Write(fb *model.Feedback) error {
+	if !c.headerWritten {
+		if err := c.w.Write(feedbackCSVHeader); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	var reportedTemp, updatedAt string
+	if fb.ReportedTemp != nil {
+		reportedTemp = strconv.FormatFloat(*fb.ReportedTemp, 'f', -1, 64)
+	}
+	if fb.UpdatedAt != nil {
+		updatedAt = fb.UpdatedAt.Format(time.RFC3339)
+	}
+	return c.w.Write([]string{
+		fb.ID.String(),
+		fb.Date,
+		csvText(fb.City),
+		csvText(fb.Message),
+		strconv.Itoa(fb.Rating),
+		string(fb.ObservedCondition),
+		reportedTemp,
+		csvText(fb.Subject),
+		fb.CreatedAt.Format(time.RFC3339),
+		updatedAt,
+	})
+}
+
+// csvText keeps spreadsheets from running user text as a formula, by prefixing text that starts
+// like one with a quote.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// Flush writes the header even when there is no feedback, so an empty export is still a valid CSV file.
+func (c *csvFeedbackWriter) Flush() error {
+	if !c.headerWritten {
+		if err := c.w.Write(feedbackCSVHeader); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type ndjsonFeedbackWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonFeedbackWriter) Write(fb *model.Feedback) error {
+	return n.enc.Encode(fb)
+}
+
+func (n *ndjsonFeedbackWriter) Flush() error {
+	return nil
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+func TestCSVFeedbackWriter_EscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	writer, _, _ := newFeedbackWriter(ExportFormatCSV, &buf)
+	reportedTemp := -3.5
+	fb := &model.Feedback{
+		ID:           uuid.New(),
+		Date:         "2024-10-27",
+		City:         "=HYPERLINK(\"http://evil.example\")",
+		Message:      "-2+3",
+		Rating:       2,
+		ReportedTemp: &reportedTemp,
+		Subject:      "@admin",
+		CreatedAt:    time.Date(2024, 10, 27, 12, 0, 0, 0, time.UTC),
+	}
+
+	if err := writer.Write(fb); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	fb.City, fb.Message, fb.Subject = "London", "Colder than forecast", "+ana"
+	if err := writer.Write(fb); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected a valid CSV file, got %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected a header and 2 rows, got %d records", len(records))
+	}
+	first, second := records[1], records[2]
+	if first[2] != "'=HYPERLINK(\"http://evil.example\")" || first[3] != "'-2+3" || first[7] != "'@admin" {
+		t.Errorf("Expected formulas to be quoted, got city %q, message %q, subject %q", first[2], first[3], first[7])
+	}
+	if first[6] != "-3.5" {
+		t.Errorf("Expected numbers to stay unquoted, got reported temp %q", first[6])
+	}
+	if second[2] != "London" || second[3] != "Colder than forecast" || second[7] != "'+ana" {
+		t.Errorf("Unexpected second row %v", second)
+	}
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"net/http"
+)
+
+// parseLocale reads the unit system from the units query param and the language of
+// condition text from the Accept-Language header. Unsupported languages fall back to English.
+func parseLocale(r *http.Request) (model.UnitSystem, model.Language, error) {
+	units, ok := model.ParseUnits(r.URL.Query().Get("units"))
+	if !ok {
+		return "", "", result.ValidationErr("Units query param must be one of: metric, imperial, scientific")
+	}
+
+	lang, _ := model.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	return units, lang, nil
+}
+
+func writeLocaleHeaders(w http.ResponseWriter, lang model.Language) {
+	w.Header().Set("Content-Language", lang.Tag())
+	w.Header().Add("Vary", "Accept-Language")
+}
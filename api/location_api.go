@@ -0,0 +1,61 @@
+package api
+
+import (
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/resp"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	SearchCacheTTL = 24 * time.Hour
+
+	MinSearchQueryLength = 2
+	MaxSearchQueryLength = 100
+)
+
+// handleLocationSearch returns candidate locations for a search query.
+// @Summary Search locations
+// @Description Search locations by name for autocomplete. Request the weather of a candidate by its
+// @Description coordinates, the weather includes its timezone.
+// @Tags locations
+// @Param q query string true "Search query, at least 2 characters"
+// @Produce json
+// @Success 200 {array} model.LocationCandidate
+// @Failure 400 {object} result.Err "Validation error"
+// @Failure 429 {object} result.Err "Rate limit exceeded"
+// @Failure 500 {object} result.Err "Internal server error"
+// @Failure 504 {object} result.Err "Request Timeout"
+// @Router /api/v1/locations/search [get]
+func (api *WeatherApi) handleLocationSearch(w http.ResponseWriter, r *http.Request) error {
+	query := strings.Join(strings.Fields(r.URL.Query().Get("q")), " ")
+	if len(query) < MinSearchQueryLength || len(query) > MaxSearchQueryLength {
+		return result.ValidationErr(fmt.Sprintf(
+			"Q query param must be between %d and %d characters long",
+			MinSearchQueryLength,
+			MaxSearchQueryLength,
+		))
+	}
+
+	cacheKey := buildSearchCacheKey(query)
+	var candidates []model.LocationCandidate
+	if api.getFromCache(cacheKey, &candidates) {
+		return resp.WriteJSON(w, http.StatusOK, candidates)
+	}
+
+	candidates, err := api.weatherService.SearchLocations(r.Context(), query)
+	if err != nil {
+		return err
+	}
+
+	api.setToCache(cacheKey, candidates, SearchCacheTTL)
+
+	return resp.WriteJSON(w, http.StatusOK, candidates)
+}
+
+func buildSearchCacheKey(query string) string {
+	return "search:" + strings.ToLower(query)
+}
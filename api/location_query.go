@@ -0,0 +1,83 @@
+package api
+
+import (
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/middleware"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// LocationAliasTTL is how long a query remembers the location it resolved to.
+	LocationAliasTTL = 24 * time.Hour
+
+	autoIP = "auto:ip"
+)
+
+// parseLocationQuery reads the location from one of the city, lat/lon, zip or ip query params.
+// The ip param accepts an address or auto:ip, which resolves to the caller's address.
+func parseLocationQuery(r *http.Request) (model.LocationQuery, error) {
+	query := r.URL.Query()
+	lat, lon := query.Get("lat"), query.Get("lon")
+
+	switch {
+	case lat != "" || lon != "":
+		return parseCoordinatesQuery(lat, lon)
+	case query.Get("zip") != "":
+		return model.NewPostcodeQuery(query.Get("zip")), nil
+	case query.Get("ip") != "":
+		return parseIPQuery(r, query.Get("ip"))
+	case strings.TrimSpace(query.Get("city")) != "":
+		return model.NewCityQuery(query.Get("city")), nil
+	}
+
+	return model.LocationQuery{}, result.ValidationErr("One of city, lat and lon, zip or ip query params is required")
+}
+
+func parseCoordinatesQuery(latParam, lonParam string) (model.LocationQuery, error) {
+	lat, err := strconv.ParseFloat(latParam, 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return model.LocationQuery{}, result.ValidationErr("Lat query param must be a number between -90 and 90")
+	}
+	lon, err := strconv.ParseFloat(lonParam, 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return model.LocationQuery{}, result.ValidationErr("Lon query param must be a number between -180 and 180")
+	}
+	return model.NewCoordinatesQuery(lat, lon), nil
+}
+
+func parseIPQuery(r *http.Request, ipParam string) (model.LocationQuery, error) {
+	if ipParam == autoIP {
+		// weatherapi would resolve auto:ip to our own address, so send the caller's one instead.
+		ipParam = middleware.ClientFromRequest(r).Addr()
+	}
+	ip := net.ParseIP(ipParam)
+	if ip == nil {
+		return model.LocationQuery{}, result.ValidationErr("Ip query param must be an IP address or " + autoIP)
+	}
+	return model.NewIPQuery(ip.String()), nil
+}
+
+// getLocationKey returns the key of the location the query resolved to last time.
+func (api *WeatherApi) getLocationKey(q model.LocationQuery) (string, bool) {
+	locationKey, ok := api.cache.Get(buildLocationAliasCacheKey(q))
+	if !ok {
+		return "", false
+	}
+	return string(locationKey), true
+}
+
+// setLocationKey remembers the location the query resolved to and returns its key.
+func (api *WeatherApi) setLocationKey(q model.LocationQuery, location model.Location) string {
+	locationKey := location.Key()
+	api.cache.Set(buildLocationAliasCacheKey(q), []byte(locationKey), LocationAliasTTL)
+	return locationKey
+}
+
+func buildLocationAliasCacheKey(q model.LocationQuery) string {
+	return "location:" + q.Key()
+}
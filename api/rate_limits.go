@@ -0,0 +1,57 @@
+package api
+
+import (
+	"github.com/DjordjeVuckovic/weather-radar/pkg/middleware"
+	"net/http"
+	"time"
+)
+
+// DefaultRateLimitPolicy limits the routes when no policy file is configured.
+func DefaultRateLimitPolicy() *middleware.RateLimitPolicy {
+	return &middleware.RateLimitPolicy{
+		Rules: []middleware.RateLimitRule{
+			{
+				Name: "weather",
+				Routes: []string{
+					"/api/v1/weather",
+					"/api/v1/weather/forecast",
+					"/api/v1/weather/history",
+					"/api/v1/weather/alerts",
+				},
+				Methods:     []string{http.MethodGet},
+				Window:      1 * time.Minute,
+				MaxRequests: 10,
+			},
+			{
+				// Every city of a stream is a weather lookup, so streams get a smaller budget.
+				Name:        "stream",
+				Routes:      []string{"/api/v1/weather/stream"},
+				Methods:     []string{http.MethodGet},
+				Window:      1 * time.Minute,
+				MaxRequests: 5,
+			},
+			{
+				// A subscription is a long lived connection, the limit is on reconnects.
+				Name:        "subscribe",
+				Routes:      []string{"/api/v1/weather/subscribe"},
+				Methods:     []string{http.MethodGet},
+				Window:      1 * time.Minute,
+				MaxRequests: 5,
+			},
+			{
+				Name:        "feedback",
+				Routes:      []string{"/api/v1/weather/feedback", "/api/v1/weather/feedback/*"},
+				Window:      1 * time.Minute,
+				MaxRequests: 30,
+			},
+			{
+				// Autocomplete fires on every keystroke, so search gets its own, more generous budget.
+				Name:        "search",
+				Routes:      []string{"/api/v1/locations/search"},
+				Methods:     []string{http.MethodGet},
+				Window:      1 * time.Minute,
+				MaxRequests: 60,
+			},
+		},
+	}
+}
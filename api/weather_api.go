@@ -2,7 +2,7 @@ package api
 
 import (
 	"encoding/json"
-	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"fmt"
 	"github.com/DjordjeVuckovic/weather-radar/internal/model"
 	"github.com/DjordjeVuckovic/weather-radar/internal/service"
 	"github.com/DjordjeVuckovic/weather-radar/pkg/cache"
@@ -10,112 +10,333 @@ import (
 	"github.com/DjordjeVuckovic/weather-radar/pkg/resp"
 	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
 	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/util"
+	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const (
-	DefaultCacheTTL = 10 * time.Minute
+	DefaultCacheTTL  = 10 * time.Minute
+	ForecastCacheTTL = 1 * time.Hour
+	AlertsCacheTTL   = 5 * time.Minute
+
+	// HistoryCacheTTL applies to finished days, whose recorded weather never changes.
+	HistoryCacheTTL = 30 * 24 * time.Hour
+
+	DefaultForecastDays = 3
+	MaxForecastDays     = 14
+
+	MaxHistoryDays     = 7
+	MaxHistoryLookback = 365
+
+	ModeFallback = "fallback"
+	ModeBlend    = "blend"
+
+	MaxSubscribedCities = 20
+	// SubscribeHeartbeatInterval keeps proxies from closing idle event streams.
+	SubscribeHeartbeatInterval = 15 * time.Second
+	// SubscribeRetry is how long clients wait before reconnecting to a closed event stream.
+	SubscribeRetry = 5 * time.Second
 )
 
 type WeatherApi struct {
 	server         *server.Server
 	cache          cache.Cache
 	weatherService *service.WeatherService
-	authService    *service.AuthService
+	hub            *service.WeatherHub
+	auth           *Authenticator
 }
 
+// BindWeatherApi registers the weather routes. Their rate limits come from the rate limit policy,
+// see DefaultRateLimitPolicy.
 func BindWeatherApi(
 	s *server.Server,
 	wService *service.WeatherService,
-	authService *service.AuthService,
+	hub *service.WeatherHub,
+	auth *Authenticator,
 	c cache.Cache) {
 
 	api := &WeatherApi{
 		server:         s,
 		weatherService: wService,
-		authService:    authService,
+		hub:            hub,
+		auth:           auth,
 		cache:          c,
 	}
-	limiter := middleware.NewFixedWindowLimiter(middleware.FixedWindowLimiterConfig{
-		Window:      1 * time.Minute,
-		MaxRequests: 10,
-	})
-	s.GET("/api/v1/weather", api.handleWeatherByCity, middleware.RateLimit(limiter))
-	s.POST("/api/v1/weather/feedback", api.handleWeatherFeedback)
+	s.GET("/api/v1/weather", api.handleWeatherByLocation)
+	s.GET("/api/v1/weather/forecast", api.handleWeatherForecast)
+	s.GET("/api/v1/weather/history", api.handleWeatherHistory)
+	s.GET("/api/v1/weather/alerts", api.handleWeatherAlerts)
+	s.POST("/api/v1/weather/feedback", api.handleWeatherFeedback, auth.requireRole(service.RoleFeedbackWriter))
+	s.GET("/api/v1/weather/feedback", api.handleFeedbackList, auth.requireRole(service.RoleReader))
+	s.GET("/api/v1/weather/feedback/report", api.handleFeedbackReport, auth.requireRole(service.RoleReader))
+	s.GET("/api/v1/weather/feedback/export", api.handleFeedbackExport, auth.requireRole(service.RoleReader), middleware.HTTPStreaming())
+	s.GET("/api/v1/weather/feedback/{id}", api.handleFeedbackGet, auth.requireRole(service.RoleReader))
+	s.PUT("/api/v1/weather/feedback/{id}", api.handleFeedbackUpdate, auth.requireRole(service.RoleFeedbackWriter))
+	s.DELETE("/api/v1/weather/feedback/{id}", api.handleFeedbackDelete, auth.requireRole(service.RoleAdmin))
 	s.GET("/api/v1/weather/stream", api.handleWeatherStream, middleware.HTTPStreaming())
+	s.GET("/api/v1/weather/subscribe", api.handleWeatherSubscribe, middleware.ServerSentEvents())
+	s.GET("/api/v1/locations/search", api.handleLocationSearch)
 }
 
-// handleWeatherByCity retrieves weather information for a specified city.
-// @Summary Get weather by city
-// @Description Get weather data for a specific city.
+// handleWeatherByLocation retrieves weather information for a specified location.
+// @Summary Get weather by location
+// @Description Get weather data for a city, coordinates, postcode or IP address.
 // @Tags weather
-// @Param city query string true "City name"
+// @Param city query string false "City name"
+// @Param lat query number false "Latitude, requires lon"
+// @Param lon query number false "Longitude, requires lat"
+// @Param zip query string false "ZIP or postcode"
+// @Param ip query string false "IP address or auto:ip for the caller's address"
+// @Param include query string false "Comma separated optional sections: aqi, pollen, alerts"
+// @Param mode query string false "fallback (default) uses the first provider that answers, blend combines all providers"
+// @Param units query string false "Unit system: metric (default), imperial or scientific"
+// @Param Accept-Language header string false "Preferred language of condition text"
 // @Produce json
 // @Success 200 {object} model.Weather
 // @Failure 400 {object} result.Err "Validation error"
-// @Failure 404 {object} result.Err "City not found"
+// @Failure 404 {object} result.Err "Location not found"
 // @Failure 500 {object} result.Err "Internal server error"
 // @Failure 504 {object} result.Err "Request Timeout"
 // @Router /api/v1/weather [get]
-func (api *WeatherApi) handleWeatherByCity(w http.ResponseWriter, r *http.Request) error {
-	city := r.URL.Query().Get("city")
-	if city == "" {
-		return result.ValidationErr("City query param is required")
+func (api *WeatherApi) handleWeatherByLocation(w http.ResponseWriter, r *http.Request) error {
+	q, err := parseLocationQuery(r)
+	if err != nil {
+		return err
+	}
+
+	opts, ok := model.ParseSections(r.URL.Query().Get("include"))
+	if !ok {
+		return result.ValidationErr("Include query param must be a comma separated list of: aqi, pollen, alerts")
+	}
+
+	switch r.URL.Query().Get("mode") {
+	case "", ModeFallback:
+	case ModeBlend:
+		opts.Blend = true
+	default:
+		return result.ValidationErr("Mode query param must be one of: fallback, blend")
+	}
+
+	units, lang, err := parseLocale(r)
+	if err != nil {
+		return err
 	}
+	opts.Lang = lang
+	writeLocaleHeaders(w, lang)
 
-	weather, cacheHit := api.getWeatherFromCache(city)
-	if cacheHit {
+	var weather *model.Weather
+	if locationKey, ok := api.getLocationKey(q); ok && api.getFromCache(buildCacheKey(locationKey, opts), &weather) {
+		weather.Alerts = model.ActiveAlerts(weather.Alerts, time.Now())
+		weather.ConvertUnits(units)
 		return resp.WriteJSON(w, http.StatusOK, weather)
 	}
 
-	weather, err := api.weatherService.GetWeatherByCity(r.Context(), city)
+	weather, err = api.weatherService.GetWeatherByLocation(r.Context(), q, opts)
 
 	if err != nil {
 		return err
 	}
 
-	api.setWeatherToCache(city, weather)
+	locationKey := api.setLocationKey(q, weather.Location)
+	ttl := DefaultCacheTTL
+	if opts.Alerts {
+		ttl = model.AlertsTTL(weather.Alerts, time.Now(), AlertsCacheTTL)
+	}
+	api.setToCache(buildCacheKey(locationKey, opts), weather, ttl)
+	api.weatherService.RecordServed(weather)
 
+	weather.ConvertUnits(units)
 	return resp.WriteJSON(w, http.StatusOK, weather)
 }
 
-// handleWeatherFeedback handles feedback submission for weather.
-// @Summary Submit weather feedback
-// @Description Submit feedback about the weather in a specific city.
+// handleWeatherForecast retrieves daily and hourly forecast for a specified location.
+// @Summary Get weather forecast by location
+// @Description Get daily and hourly weather forecast for a city, coordinates, postcode or IP address.
+// @Tags weather
+// @Param city query string false "City name"
+// @Param lat query number false "Latitude, requires lon"
+// @Param lon query number false "Longitude, requires lat"
+// @Param zip query string false "ZIP or postcode"
+// @Param ip query string false "IP address or auto:ip for the caller's address"
+// @Param days query int false "Number of forecast days (1-14, default 3)"
+// @Param units query string false "Unit system: metric (default), imperial or scientific"
+// @Param Accept-Language header string false "Preferred language of condition text"
+// @Produce json
+// @Success 200 {object} model.Forecast
+// @Failure 400 {object} result.Err "Validation error"
+// @Failure 404 {object} result.Err "Location not found"
+// @Failure 500 {object} result.Err "Internal server error"
+// @Failure 504 {object} result.Err "Request Timeout"
+// @Router /api/v1/weather/forecast [get]
+func (api *WeatherApi) handleWeatherForecast(w http.ResponseWriter, r *http.Request) error {
+	q, err := parseLocationQuery(r)
+	if err != nil {
+		return err
+	}
+
+	days := DefaultForecastDays
+	if daysParam := r.URL.Query().Get("days"); daysParam != "" {
+		d, err := strconv.Atoi(daysParam)
+		if err != nil || d < 1 || d > MaxForecastDays {
+			return result.ValidationErr(fmt.Sprintf("Days query param must be a number between 1 and %d", MaxForecastDays))
+		}
+		days = d
+	}
+
+	units, lang, err := parseLocale(r)
+	if err != nil {
+		return err
+	}
+	writeLocaleHeaders(w, lang)
+
+	var forecast *model.Forecast
+	if locationKey, ok := api.getLocationKey(q); ok && api.getFromCache(buildForecastCacheKey(locationKey, days, lang), &forecast) {
+		forecast.ConvertUnits(units)
+		return resp.WriteJSON(w, http.StatusOK, forecast)
+	}
+
+	forecast, err = api.weatherService.GetForecastByLocation(r.Context(), q, days, lang)
+	if err != nil {
+		return err
+	}
+
+	locationKey := api.setLocationKey(q, forecast.Location)
+	api.setToCache(buildForecastCacheKey(locationKey, days, lang), forecast, ForecastCacheTTL)
+
+	forecast.ConvertUnits(units)
+	return resp.WriteJSON(w, http.StatusOK, forecast)
+}
+
+// handleWeatherHistory retrieves recorded weather for a specified location and date range.
+// @Summary Get weather history by location
+// @Description Get recorded daily and hourly weather for a location and date range.
+// @Tags weather
+// @Param city query string false "City name"
+// @Param lat query number false "Latitude, requires lon"
+// @Param lon query number false "Longitude, requires lat"
+// @Param zip query string false "ZIP or postcode"
+// @Param ip query string false "IP address or auto:ip for the caller's address"
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD), defaults to from"
+// @Param units query string false "Unit system: metric (default), imperial or scientific"
+// @Param Accept-Language header string false "Preferred language of condition text"
+// @Produce json
+// @Success 200 {object} model.WeatherHistory
+// @Failure 400 {object} result.Err "Validation error"
+// @Failure 404 {object} result.Err "Location not found"
+// @Failure 500 {object} result.Err "Internal server error"
+// @Failure 504 {object} result.Err "Request Timeout"
+// @Router /api/v1/weather/history [get]
+func (api *WeatherApi) handleWeatherHistory(w http.ResponseWriter, r *http.Request) error {
+	q, err := parseLocationQuery(r)
+	if err != nil {
+		return err
+	}
+
+	from, to, err := parseHistoryRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		return err
+	}
+
+	units, lang, err := parseLocale(r)
+	if err != nil {
+		return err
+	}
+	writeLocaleHeaders(w, lang)
+
+	history, missing := api.getHistoryFromCache(q, util.DatesBetween(from, to), lang)
+	if len(missing) == 0 {
+		history.ConvertUnits(units)
+		return resp.WriteJSON(w, http.StatusOK, history)
+	}
+
+	fetched, err := api.weatherService.GetHistoryByLocation(r.Context(), q, missing, lang)
+	if err != nil {
+		return err
+	}
+
+	api.setHistoryToCache(q, fetched, lang)
+	history.Merge(fetched)
+
+	history.ConvertUnits(units)
+	return resp.WriteJSON(w, http.StatusOK, history)
+}
+
+func parseHistoryRange(fromParam, toParam string) (time.Time, time.Time, error) {
+	if fromParam == "" {
+		return time.Time{}, time.Time{}, result.ValidationErr("From query param is required")
+	}
+	if toParam == "" {
+		toParam = fromParam
+	}
+
+	from, err := util.ParseDate(fromParam)
+	if err != nil {
+		return time.Time{}, time.Time{}, result.ValidationErr("From query param must be a date in YYYY-MM-DD format")
+	}
+	to, err := util.ParseDate(toParam)
+	if err != nil {
+		return time.Time{}, time.Time{}, result.ValidationErr("To query param must be a date in YYYY-MM-DD format")
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	switch {
+	case from.After(to):
+		return time.Time{}, time.Time{}, result.ValidationErr("From date must not be after to date")
+	case to.After(today):
+		return time.Time{}, time.Time{}, result.ValidationErr("To date must not be in the future")
+	case from.Before(today.AddDate(0, 0, -MaxHistoryLookback)):
+		return time.Time{}, time.Time{}, result.ValidationErr(fmt.Sprintf("From date must be within the last %d days", MaxHistoryLookback))
+	case to.Sub(from) >= MaxHistoryDays*24*time.Hour:
+		return time.Time{}, time.Time{}, result.ValidationErr(fmt.Sprintf("Date range must not exceed %d days", MaxHistoryDays))
+	}
+
+	return from, to, nil
+}
+
+// handleWeatherAlerts retrieves active severe weather alerts for a specified location.
+// @Summary Get weather alerts by location
+// @Description Get active official weather warnings for a city, coordinates, postcode or IP address.
 // @Tags weather
-// @Accept json
+// @Param city query string false "City name"
+// @Param lat query number false "Latitude, requires lon"
+// @Param lon query number false "Longitude, requires lat"
+// @Param zip query string false "ZIP or postcode"
+// @Param ip query string false "IP address or auto:ip for the caller's address"
 // @Produce json
-// @Param feedback body dto.WeatherFeedbackReq true "Weather feedback"
-// @Success 200 {object} dto.WeatherFeedbackResp
-// @Failure 400 {object} result.Err "Invalid request data"
-// @Failure 401 {object} result.Err "Unauthorized"
-// @Router /api/v1/weather/feedback [post]
-// @Security BasicAuth
-func (api *WeatherApi) handleWeatherFeedback(w http.ResponseWriter, r *http.Request) error {
-	username, password, ok := r.BasicAuth()
-	if !ok || api.authService.ValidateBasicAuth(service.AuthCredentials{
-		Username: username,
-		Password: password,
-	}) {
-		return result.UnauthorizedErr("Invalid credentials")
-	}
-
-	var feedback dto.WeatherFeedbackReq
-	if err := json.NewDecoder(r.Body).Decode(&feedback); err != nil {
-		return result.ValidationErr("Invalid request data")
-	}
-	ctx := r.Context()
-	if err := api.weatherService.SubmitFeedback(ctx, &feedback); err != nil {
+// @Success 200 {object} model.WeatherAlerts
+// @Failure 400 {object} result.Err "Validation error"
+// @Failure 404 {object} result.Err "Location not found"
+// @Failure 500 {object} result.Err "Internal server error"
+// @Failure 504 {object} result.Err "Request Timeout"
+// @Router /api/v1/weather/alerts [get]
+func (api *WeatherApi) handleWeatherAlerts(w http.ResponseWriter, r *http.Request) error {
+	q, err := parseLocationQuery(r)
+	if err != nil {
 		return err
 	}
 
-	response := dto.WeatherFeedbackResp{
-		Message: "Feedback submitted successfully",
+	var alerts *model.WeatherAlerts
+	if locationKey, ok := api.getLocationKey(q); ok && api.getFromCache(buildAlertsCacheKey(locationKey), &alerts) {
+		alerts.Alerts = model.ActiveAlerts(alerts.Alerts, time.Now())
+		return resp.WriteJSON(w, http.StatusOK, alerts)
 	}
-	return resp.WriteJSON(w, http.StatusOK, response)
+
+	alerts, err = api.weatherService.GetAlertsByLocation(r.Context(), q)
+	if err != nil {
+		return err
+	}
+
+	// Cached alerts must not outlive the earliest expiring one.
+	locationKey := api.setLocationKey(q, alerts.Location)
+	api.setToCache(buildAlertsCacheKey(locationKey), alerts, model.AlertsTTL(alerts.Alerts, time.Now(), AlertsCacheTTL))
+
+	return resp.WriteJSON(w, http.StatusOK, alerts)
 }
 
 // handleWeatherStream retrieves streamed weather information for a specified cities.
@@ -180,6 +401,7 @@ func (api *WeatherApi) handleWeatherStream(w http.ResponseWriter, r *http.Reques
 				return err
 			}
 			flusher.Flush() // Send the chunk immediately to the client
+			api.weatherService.RecordServed(weather.Weather)
 
 		case err := <-errCh:
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -192,31 +414,158 @@ func (api *WeatherApi) handleWeatherStream(w http.ResponseWriter, r *http.Reques
 	}
 }
 
-func (api *WeatherApi) getWeatherFromCache(city string) (*model.Weather, bool) {
-	cItem, cExist := api.cache.Get(buildCacheKey(city))
+// handleWeatherSubscribe sends the weather of the subscribed cities as Server-Sent Events whenever it changes.
+// @Summary Subscribe to weather changes
+// @Description Streams a weather event with the current weather of every city, then one whenever the weather of a city changes. Comments are sent as heartbeats. On reconnect, the Last-Event-ID header skips the cities whose weather did not change since.
+// @Tags weather
+// @Param cities query string true "Comma separated city names"
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Produce text/event-stream
+// @Success 200 {object} service.WeatherUpdate "Data of the weather events"
+// @Failure 400 {object} result.Err "Validation error or unknown city"
+// @Failure 429 {object} result.Err "Too many requests or subscriptions of the client"
+// @Failure 503 {object} result.Err "Too many cities are watched"
+// @Router /api/v1/weather/subscribe [get]
+func (api *WeatherApi) handleWeatherSubscribe(w http.ResponseWriter, r *http.Request) error {
+	cities, err := parseSubscribedCities(r.URL.Query().Get("cities"))
+	if err != nil {
+		return err
+	}
+
+	flusher, _ := r.Context().Value(middleware.CtxFlusherKey).(http.Flusher)
+
+	sub, err := api.hub.Subscribe(r.Context(), middleware.ClientID(r), cities, r.Header.Get("Last-Event-ID"))
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	w.WriteHeader(http.StatusOK)
+	_, err = fmt.Fprintf(w, "retry: %d\n\n", SubscribeRetry.Milliseconds())
+	heartbeat := time.NewTicker(SubscribeHeartbeatInterval)
+	defer heartbeat.Stop()
+	for err == nil {
+		flusher.Flush()
+		select {
+		case <-sub.Notify():
+			err = writeWeatherEvents(w, sub.Updates())
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		case <-r.Context().Done():
+			return nil
+		case <-api.server.ShutdownSig:
+			return nil
+		}
+	}
+	// The client is gone, and with the response started there is nothing left to answer.
+	slog.Debug("Weather subscription closed", slog.String("error", err.Error()))
+	return nil
+}
+
+func parseSubscribedCities(param string) ([]string, error) {
+	var cities []string
+	for _, city := range strings.Split(param, ",") {
+		if city = strings.TrimSpace(city); city != "" {
+			cities = append(cities, city)
+		}
+	}
+	if len(cities) == 0 {
+		return nil, result.ValidationErr("Cities query param is required")
+	}
+	if len(cities) > MaxSubscribedCities {
+		return nil, result.ValidationErr(fmt.Sprintf("Cities query param must not list more than %d cities", MaxSubscribedCities))
+	}
+	return cities, nil
+}
+
+func writeWeatherEvents(w io.Writer, updates []service.WeatherUpdate) error {
+	for _, update := range updates {
+		data, err := json.Marshal(update)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %s\nevent: weather\ndata: %s\n\n", update.ID, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (api *WeatherApi) getFromCache(key string, v any) bool {
+	cItem, cExist := api.cache.Get(key)
 	if !cExist {
-		slog.Debug("Cache miss for city", slog.String("city", city))
-		return nil, false
+		slog.Debug("Cache miss", slog.String("cache_key", key))
+		return false
 	}
-	var weather model.Weather
-	err := json.Unmarshal(cItem, &weather)
+	err := json.Unmarshal(cItem, v)
 	if err != nil {
-		slog.Error("Failed to unmarshal weather data", slog.String("city", city))
-		return nil, false
+		slog.Error("Failed to unmarshal cached data", slog.String("cache_key", key))
+		return false
 	}
-	slog.Debug("Cache hit for city", slog.String("city", city))
-	return &weather, true
+	slog.Debug("Cache hit", slog.String("cache_key", key))
+	return true
 }
 
-func (api *WeatherApi) setWeatherToCache(city string, weather *model.Weather) {
-	jsonBytes, err := json.Marshal(weather)
+func (api *WeatherApi) setToCache(key string, v any, ttl time.Duration) {
+	jsonBytes, err := json.Marshal(v)
 	if err != nil {
-		slog.Error("Failed to marshal weather data", slog.String("city", city))
+		slog.Error("Failed to marshal data for cache", slog.String("cache_key", key))
 		return
 	}
-	api.cache.Set(buildCacheKey(city), jsonBytes, DefaultCacheTTL)
+	api.cache.Set(key, jsonBytes, ttl)
+}
+
+// getHistoryFromCache collects cached history days and returns the dates that still have to be fetched.
+func (api *WeatherApi) getHistoryFromCache(q model.LocationQuery, dates []time.Time, lang model.Language) (*model.WeatherHistory, []time.Time) {
+	history := &model.WeatherHistory{}
+	locationKey, ok := api.getLocationKey(q)
+	if !ok {
+		return history, dates
+	}
+
+	var missing []time.Time
+	for _, date := range dates {
+		var day *model.WeatherHistory
+		if api.getFromCache(buildHistoryCacheKey(locationKey, date.Format(time.DateOnly), lang), &day) {
+			history.Merge(day)
+			continue
+		}
+		missing = append(missing, date)
+	}
+	return history, missing
+}
+
+// setHistoryToCache caches every day separately. Days that are already over at the location are
+// cached for long, while the current day can still change and uses the default TTL.
+func (api *WeatherApi) setHistoryToCache(q model.LocationQuery, history *model.WeatherHistory, lang model.Language) {
+	locationKey := api.setLocationKey(q, history.Location)
+	localDate := history.Location.LocalDate()
+	for _, day := range history.Days {
+		ttl := HistoryCacheTTL
+		if day.Date >= localDate {
+			ttl = DefaultCacheTTL
+		}
+		dayHistory := &model.WeatherHistory{
+			Location: history.Location,
+			Units:    history.Units,
+			Days:     []model.Day{day},
+		}
+		api.setToCache(buildHistoryCacheKey(locationKey, day.Date, lang), dayHistory, ttl)
+	}
+}
+
+func buildCacheKey(locationKey string, opts model.WeatherOptions) string {
+	return "weather:" + locationKey + opts.Key()
+}
+
+func buildForecastCacheKey(locationKey string, days int, lang model.Language) string {
+	return "forecast:" + locationKey + ":" + strconv.Itoa(days) + lang.Key()
+}
+
+func buildHistoryCacheKey(locationKey string, date string, lang model.Language) string {
+	return "history:" + locationKey + ":" + date + lang.Key()
 }
 
-func buildCacheKey(city string) string {
-	return "weather:" + city
+func buildAlertsCacheKey(locationKey string) string {
+	return "alerts:" + locationKey
 }
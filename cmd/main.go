@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github.com/DjordjeVuckovic/weather-radar/api"
 	"github.com/DjordjeVuckovic/weather-radar/internal/client"
 	"github.com/DjordjeVuckovic/weather-radar/internal/config"
@@ -9,6 +10,7 @@ import (
 	"github.com/DjordjeVuckovic/weather-radar/pkg/cache"
 	"github.com/DjordjeVuckovic/weather-radar/pkg/logger"
 	"github.com/DjordjeVuckovic/weather-radar/pkg/middleware"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/redis"
 	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
 	"log/slog"
 	"time"
@@ -32,26 +34,91 @@ func main() {
 	}
 	api.SetupHealthCheck(s)
 
+	clientResolver, err := middleware.NewClientResolver(middleware.ClientResolverConfig{
+		TrustedProxies: cfg.TrustedProxies,
+		TrustedHeader:  cfg.TrustedProxyHeader,
+		IPv6PrefixLen:  cfg.ClientIPv6PrefixLen,
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+	// Identifies clients first, the logger and rate limiters key on them.
+	s.Use(middleware.ClientIdentification(clientResolver))
 	s.Use(middleware.Logger())
 	s.Use(middleware.Recover())
 	s.Use(middleware.CORS(middleware.CORSConfig{Origin: cfg.CorsOrigins}))
 
-	wCl := client.NewWeatherAPIClient(
-		cfg.WeatherUrl,
-		cfg.WeatherApiKey,
-	)
+	providers := client.NewProviderRegistry()
+	for priority, name := range cfg.WeatherProviders {
+		switch name {
+		case client.ProviderWeatherAPI:
+			providers.Register(name, priority, client.NewWeatherAPIClient(
+				cfg.WeatherUrl,
+				cfg.WeatherApiKey,
+			))
+		case client.ProviderOpenWeather:
+			providers.Register(name, priority, client.NewOpenWeatherClient(
+				cfg.OpenWeatherUrl,
+				cfg.OpenWeatherApiKey,
+			))
+		default:
+			panic("unknown weather provider: " + name)
+		}
+	}
 	astroCl := client.NewAstroAPIClient(
 		cfg.OpenWeatherUrl,
 		cfg.OpenWeatherApiKey,
 	)
-	authService := service.NewAuthService(service.AuthCredentials{
-		Username: cfg.BasicAuthUsername,
-		Password: cfg.BasicAuthPassword,
-	})
+	authService, err := service.NewAuthService(loadUsers(cfg))
+	if err != nil {
+		panic("failed to set up authentication: " + err.Error())
+	}
 	st := storage.NewWeatherInMemStorage()
-	wService := service.NewWeatherService(wCl, astroCl, st)
+	if cfg.Storage == "sqlite" {
+		sqliteSt, err := storage.NewWeatherSQLiteStorage(context.Background(), cfg.SQLitePath)
+		if err != nil {
+			panic("failed to open sqlite storage: " + err.Error())
+		}
+		st = sqliteSt
+	}
+	wService := service.NewWeatherService(providers, astroCl, st)
+	keyService := service.NewApiKeyService(st, cfg.ApiKeyPlans)
+
+	rateLimit := middleware.LimiterConfig{
+		Algorithm:  cfg.RateLimitAlgorithm,
+		MaxClients: cfg.RateLimitMaxClients,
+	}
+	var redisCl *redis.Client
+	if cfg.RateLimitRedisAddr != "" {
+		redisCl = newRedisClient(cfg)
+		rateLimit.Store = middleware.NewRedisCounterStore(redisCl)
+	}
+
+	apiKeyLookups := newApiKeyLookupLimiter(rateLimit)
+	// Resolves API keys before the route middleware, so rate limits apply per tenant.
+	s.Use(middleware.ApiKeyAuth(middleware.ApiKeyAuthConfig{
+		Resolve:  api.TenantResolver(keyService),
+		Lookups:  apiKeyLookups,
+		CacheTTL: apiKeyCacheTTL,
+	}))
 
-	api.BindWeatherApi(s, wService, authService, c)
+	routeLimiter := newRouteLimiter(cfg, rateLimit)
+	// Applies the rate limit policy to the routes registered from here on.
+	s.UseRoute(routeLimiter.Route)
+
+	// Polls the weather of the cities clients subscribed to, once per city for all of them, and
+	// snapshots every update sent for the feedback report.
+	hub := service.NewWeatherHub(service.WeatherHubConfig{
+		Fetch:                  wService.GetWeatherByCity,
+		Record:                 wService.RecordServed,
+		Interval:               cfg.WeatherPollInterval,
+		MaxPolledCities:        cfg.WeatherMaxPolledCities,
+		MaxClientSubscriptions: cfg.WeatherMaxClientSubscriptions,
+	})
+
+	auth := api.NewAuthenticator(authService, loadJWTConfig(cfg))
+	api.BindWeatherApi(s, wService, hub, auth, c)
+	api.BindApiKeyApi(s, keyService, auth)
 
 	s.SetupNotFoundHandler()
 
@@ -59,9 +126,120 @@ func main() {
 		<-s.ShutdownSig
 		slog.Info("Shutdown started, cleaning up resources...")
 		c.Stop()
+		routeLimiter.Stop()
+		apiKeyLookups.Stop()
+		hub.Stop()
 	}()
 
 	if err := s.Start(); err != nil {
 		slog.Error(err.Error())
 	}
+
+	// Storage is closed once in-flight requests are done and the served weather is saved,
+	// not on the shutdown signal.
+	wService.Stop()
+	if err := st.Close(); err != nil {
+		slog.Error("Failed to close storage", slog.String("error", err.Error()))
+	}
+	if redisCl != nil {
+		_ = redisCl.Close()
+	}
+}
+
+const (
+	// apiKeyCacheTTL is how long resolved API keys are accepted without a storage lookup, and so
+	// how long a revoked key is still accepted.
+	apiKeyCacheTTL = 30 * time.Second
+	// apiKeyLookupsPerMinute are the API keys a client can have looked up per minute.
+	apiKeyLookupsPerMinute = 20
+)
+
+// newApiKeyLookupLimiter limits the API key lookups of clients, in the store of the rate limits if any.
+func newApiKeyLookupLimiter(rateLimit middleware.LimiterConfig) middleware.Limiter {
+	rateLimit.Name = "api-key-lookups"
+	rateLimit.Window = time.Minute
+	rateLimit.MaxRequests = apiKeyLookupsPerMinute
+	limiter, err := middleware.NewLimiter(rateLimit)
+	if err != nil {
+		panic("failed to set up API key lookup limit: " + err.Error())
+	}
+	return limiter
+}
+
+// policyReloadInterval is how often the rate limit policy file is checked for changes.
+const policyReloadInterval = 5 * time.Second
+
+// newRouteLimiter applies the policy file, or the default policy without one.
+func newRouteLimiter(cfg config.Env, rateLimit middleware.LimiterConfig) *middleware.RouteLimiter {
+	policy := api.DefaultRateLimitPolicy()
+	if cfg.RateLimitPolicyFile != "" {
+		var err error
+		if policy, err = middleware.LoadRateLimitPolicy(cfg.RateLimitPolicyFile); err != nil {
+			panic(err.Error())
+		}
+	}
+	routeLimiter, err := middleware.NewRouteLimiter(policy, rateLimit)
+	if err != nil {
+		panic("failed to set up rate limits: " + err.Error())
+	}
+	if cfg.RateLimitPolicyFile != "" {
+		routeLimiter.Watch(cfg.RateLimitPolicyFile, policyReloadInterval)
+	}
+	return routeLimiter
+}
+
+// newRedisClient connects to the rate limit store. An unreachable store is not fatal, requests
+// are limited per replica until it is reachable.
+func newRedisClient(cfg config.Env) *redis.Client {
+	redisCl := redis.NewClient(cfg.RateLimitRedisAddr, redis.WithPassword(cfg.RateLimitRedisPassword))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := redisCl.Ping(ctx); err != nil {
+		slog.Warn("Rate limit store unreachable, limiting locally until it is",
+			slog.String("addr", cfg.RateLimitRedisAddr),
+			slog.String("error", err.Error()))
+	}
+	return redisCl
+}
+
+// loadUsers reads the users file, or creates the single admin configured by env vars.
+func loadUsers(cfg config.Env) []service.User {
+	if cfg.AuthUsersFile != "" {
+		users, err := service.LoadUsersFile(cfg.AuthUsersFile)
+		if err != nil {
+			panic(err.Error())
+		}
+		return users
+	}
+
+	admin, err := service.NewUser(cfg.BasicAuthUsername, cfg.BasicAuthPassword, service.RoleAdmin)
+	if err != nil {
+		panic("failed to hash admin password: " + err.Error())
+	}
+	return []service.User{admin}
+}
+
+// jwtClockSkew is the clock difference tolerated between the SSO and us.
+const jwtClockSkew = 30 * time.Second
+
+// loadJWTConfig returns the bearer token config, or nil when JWT is not configured.
+func loadJWTConfig(cfg config.Env) *middleware.JWTConfig {
+	if cfg.JWTSecret == "" && cfg.JWTJWKSFile == "" {
+		return nil
+	}
+
+	jwtConfig := &middleware.JWTConfig{
+		Secret:   []byte(cfg.JWTSecret),
+		Audience: cfg.JWTAudience,
+		Issuer:   cfg.JWTIssuer,
+		Leeway:   jwtClockSkew,
+	}
+	if cfg.JWTJWKSFile != "" {
+		keys, err := middleware.LoadJWKS(cfg.JWTJWKSFile)
+		if err != nil {
+			panic(err.Error())
+		}
+		jwtConfig.Keys = keys
+	}
+	return jwtConfig
 }
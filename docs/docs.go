@@ -15,23 +15,290 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/v1/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the keys of a tenant, or of all tenants. Keys are identified by their prefix.\nRequires the admin role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "List API keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant",
+                        "name": "tenant",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ApiKey"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issue an API key for a tenant on a plan. The key is only returned in this response.\nRequests with the key in the X-Api-Key header are rate limited by the plan, per tenant.\nRequires the admin role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Issue API key",
+                "parameters": [
+                    {
+                        "description": "API key",
+                        "name": "key",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiKeyReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiKeyResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Requires the admin role.",
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Revoke API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "API key not found",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/locations/search": {
+            "get": {
+                "description": "Search locations by name for autocomplete. Request the weather of a candidate by its\ncoordinates, the weather includes its timezone.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "locations"
+                ],
+                "summary": "Search locations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query, at least 2 characters",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.LocationCandidate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "504": {
+                        "description": "Request Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/weather": {
             "get": {
-                "description": "Get weather data for a specific city.",
+                "description": "Get weather data for a city, coordinates, postcode or IP address.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "weather"
                 ],
-                "summary": "Get weather by city",
+                "summary": "Get weather by location",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "City name",
                         "name": "city",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude, requires lon",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude, requires lat",
+                        "name": "lon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ZIP or postcode",
+                        "name": "zip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IP address or auto:ip for the caller's address",
+                        "name": "ip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated optional sections: aqi, pollen, alerts",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "fallback (default) uses the first provider that answers, blend combines all providers",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit system: metric (default), imperial or scientific",
+                        "name": "units",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Preferred language of condition text",
+                        "name": "Accept-Language",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -48,7 +315,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "City not found",
+                        "description": "Location not found",
                         "schema": {
                             "$ref": "#/definitions/result.Err"
                         }
@@ -68,50 +335,75 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/weather/feedback": {
-            "post": {
-                "security": [
-                    {
-                        "BasicAuth": []
-                    }
-                ],
-                "description": "Submit feedback about the weather in a specific city.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/v1/weather/alerts": {
+            "get": {
+                "description": "Get active official weather warnings for a city, coordinates, postcode or IP address.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "weather"
                 ],
-                "summary": "Submit weather feedback",
+                "summary": "Get weather alerts by location",
                 "parameters": [
                     {
-                        "description": "Weather feedback",
-                        "name": "feedback",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.WeatherFeedbackReq"
-                        }
+                        "type": "string",
+                        "description": "City name",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude, requires lon",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude, requires lat",
+                        "name": "lon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ZIP or postcode",
+                        "name": "zip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IP address or auto:ip for the caller's address",
+                        "name": "ip",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.WeatherFeedbackResp"
+                            "$ref": "#/definitions/model.WeatherAlerts"
                         }
                     },
                     "400": {
-                        "description": "Invalid request data",
+                        "description": "Validation error",
                         "schema": {
                             "$ref": "#/definitions/result.Err"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Location not found",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "504": {
+                        "description": "Request Timeout",
                         "schema": {
                             "$ref": "#/definitions/result.Err"
                         }
@@ -119,116 +411,1392 @@ const docTemplate = `{
                 }
             }
         },
-        "/healthz": {
-            "get": {
-                "description": "This endpoint returns the health status of the application.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "health"
-                ],
-                "summary": "Health check endpoint",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "string"
-                        }
+        "/api/v1/weather/feedback": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List submitted feedback filtered by city and date range.\nRequires the reader role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feedback"
+                ],
+                "summary": "List weather feedback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "City name, case insensitive",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of feedback entries to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.FeedbackPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Submit feedback about the weather in a specific city. The date must not be in the future,\nthe city must be known to the weather providers and the optional rating must be between 1 and 5.\nRequires the feedback-writer role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feedback"
+                ],
+                "summary": "Submit weather feedback",
+                "parameters": [
+                    {
+                        "description": "Weather feedback",
+                        "name": "feedback",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.WeatherFeedbackReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WeatherFeedbackResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request data",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/weather/feedback/export": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stream feedback as CSV or newline delimited JSON, filtered by city and date range.\nCSV text starting with =, +, -, @, tab or carriage return is prefixed with ' so that\nspreadsheets do not run it as a formula. Requires the reader role.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "feedback"
+                ],
+                "summary": "Export weather feedback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export format: csv (default) or ndjson",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "City name, case insensitive",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Feedback"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/weather/feedback/report": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Aggregate rating distributions, reported vs served temperature deltas and condition\nmismatch rates per city and provider. Feedback is compared with the weather served\non its date closest to the time it was submitted. Temperatures are in °C.\nRequires the reader role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feedback"
+                ],
+                "summary": "Feedback accuracy report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "City name, case insensitive",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.FeedbackReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/weather/feedback/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Requires the reader role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feedback"
+                ],
+                "summary": "Get weather feedback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Feedback id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Feedback"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Feedback not found",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Requires the feedback-writer role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feedback"
+                ],
+                "summary": "Update weather feedback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Feedback id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Weather feedback",
+                        "name": "feedback",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.WeatherFeedbackReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Feedback"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Feedback not found",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Requires the admin role.",
+                "tags": [
+                    "feedback"
+                ],
+                "summary": "Delete weather feedback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Feedback id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Feedback not found",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/weather/forecast": {
+            "get": {
+                "description": "Get daily and hourly weather forecast for a city, coordinates, postcode or IP address.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weather"
+                ],
+                "summary": "Get weather forecast by location",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "City name",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude, requires lon",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude, requires lat",
+                        "name": "lon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ZIP or postcode",
+                        "name": "zip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IP address or auto:ip for the caller's address",
+                        "name": "ip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of forecast days (1-14, default 3)",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit system: metric (default), imperial or scientific",
+                        "name": "units",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Preferred language of condition text",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Forecast"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Location not found",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "504": {
+                        "description": "Request Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/weather/history": {
+            "get": {
+                "description": "Get recorded daily and hourly weather for a location and date range.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weather"
+                ],
+                "summary": "Get weather history by location",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "City name",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude, requires lon",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude, requires lat",
+                        "name": "lon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ZIP or postcode",
+                        "name": "zip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IP address or auto:ip for the caller's address",
+                        "name": "ip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD), defaults to from",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit system: metric (default), imperial or scientific",
+                        "name": "units",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Preferred language of condition text",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.WeatherHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "Location not found",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "504": {
+                        "description": "Request Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/weather/stream": {
+            "get": {
+                "description": "Get weather data for a specific city.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weather"
+                ],
+                "summary": "Get weather by city",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.AggregatedWeather"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "404": {
+                        "description": "City not found",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "504": {
+                        "description": "Request Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/weather/subscribe": {
+            "get": {
+                "description": "Streams a weather event with the current weather of every city, then one whenever the weather of a city changes. Comments are sent as heartbeats. On reconnect, the Last-Event-ID header skips the cities whose weather did not change since.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "weather"
+                ],
+                "summary": "Subscribe to weather changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated city names",
+                        "name": "cities",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data of the weather events",
+                        "schema": {
+                            "$ref": "#/definitions/service.WeatherUpdate"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error or unknown city",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests or subscriptions of the client",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many cities are watched",
+                        "schema": {
+                            "$ref": "#/definitions/result.Err"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "This endpoint returns the health status of the application.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ready": {
+            "get": {
+                "description": "This endpoint returns the readiness status of the application.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.ApiKeyReq": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "plan": {
+                    "type": "string"
+                },
+                "tenant": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ApiKeyResp": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "plan": {
+                    "type": "string"
+                },
+                "tenant": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.WeatherFeedbackReq": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "observed_condition": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "reported_temp": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.WeatherFeedbackResp": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.AirQuality": {
+            "type": "object",
+            "properties": {
+                "co": {
+                    "type": "number"
+                },
+                "no2": {
+                    "type": "number"
+                },
+                "o3": {
+                    "type": "number"
+                },
+                "pm10": {
+                    "type": "number"
+                },
+                "pm2_5": {
+                    "type": "number"
+                },
+                "so2": {
+                    "type": "number"
+                },
+                "us_epa_index": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Alert": {
+            "type": "object",
+            "properties": {
+                "areas": {
+                    "type": "string"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "certainty": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "effective": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "expires": {
+                    "type": "string"
+                },
+                "headline": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "instruction": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "urgency": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ApiKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "plan": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "tenant": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Astro": {
+            "type": "object",
+            "properties": {
+                "sunrise": {
+                    "type": "string"
+                },
+                "sunset": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Blend": {
+            "type": "object",
+            "properties": {
+                "disagreements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.BlendField"
+                    }
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.FieldSpread"
+                    }
+                },
+                "method": {
+                    "type": "string"
+                },
+                "outliers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.BlendField": {
+            "type": "string",
+            "enum": [
+                "temp",
+                "feelslike",
+                "humidity",
+                "pressure",
+                "wind_speed",
+                "cloud",
+                "precip",
+                "vis"
+            ],
+            "x-enum-varnames": [
+                "FieldTemp",
+                "FieldFeelsLike",
+                "FieldHumidity",
+                "FieldPressure",
+                "FieldWindSpeed",
+                "FieldCloud",
+                "FieldPrecip",
+                "FieldVisibility"
+            ]
+        },
+        "model.CityReport": {
+            "type": "object",
+            "properties": {
+                "avg_rating": {
+                    "type": "number"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "feedback": {
+                    "type": "integer"
+                },
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ProviderAccuracy"
+                    }
+                },
+                "ratings": {
+                    "$ref": "#/definitions/model.RatingDistribution"
+                }
+            }
+        },
+        "model.ConditionAccuracy": {
+            "type": "object",
+            "properties": {
+                "mismatch_rate": {
+                    "type": "number"
+                },
+                "mismatches": {
+                    "type": "integer"
+                },
+                "samples": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Current": {
+            "type": "object",
+            "properties": {
+                "cloud": {
+                    "type": "integer"
+                },
+                "condition": {
+                    "type": "string"
+                },
+                "condition_code": {
+                    "type": "integer"
+                },
+                "feelslike": {
+                    "type": "number"
+                },
+                "feelslike_c": {
+                    "type": "number"
+                },
+                "heatindex": {
+                    "type": "number"
+                },
+                "heatindex_c": {
+                    "type": "number"
+                },
+                "humidity": {
+                    "type": "integer"
+                },
+                "last_updated": {
+                    "type": "string"
+                },
+                "precip": {
+                    "type": "number"
+                },
+                "precip_mm": {
+                    "type": "integer"
+                },
+                "pressure": {
+                    "type": "number"
+                },
+                "pressure_mb": {
+                    "type": "integer"
+                },
+                "temp": {
+                    "type": "number"
+                },
+                "temp_c": {
+                    "type": "number"
+                },
+                "uv": {
+                    "type": "integer"
+                },
+                "uv_index": {
+                    "type": "number"
+                },
+                "vis": {
+                    "type": "number"
+                },
+                "vis_km": {
+                    "type": "integer"
+                },
+                "wind_degree": {
+                    "type": "integer"
+                },
+                "wind_dir": {
+                    "type": "string"
+                },
+                "wind_kph": {
+                    "type": "number"
+                },
+                "wind_speed": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.Day": {
+            "type": "object",
+            "properties": {
+                "avg_humidity": {
+                    "type": "integer"
+                },
+                "avg_temp": {
+                    "type": "number"
+                },
+                "avg_temp_c": {
+                    "type": "number"
+                },
+                "avg_vis": {
+                    "type": "number"
+                },
+                "avg_vis_km": {
+                    "type": "number"
+                },
+                "chance_of_rain": {
+                    "type": "integer"
+                },
+                "chance_of_snow": {
+                    "type": "integer"
+                },
+                "cloud": {
+                    "type": "integer"
+                },
+                "condition": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "hours": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Hour"
+                    }
+                },
+                "max_temp": {
+                    "type": "number"
+                },
+                "max_temp_c": {
+                    "type": "number"
+                },
+                "max_wind_kph": {
+                    "type": "number"
+                },
+                "max_wind_speed": {
+                    "type": "number"
+                },
+                "min_temp": {
+                    "type": "number"
+                },
+                "min_temp_c": {
+                    "type": "number"
+                },
+                "sunrise": {
+                    "type": "string"
+                },
+                "sunset": {
+                    "type": "string"
+                },
+                "total_precip": {
+                    "type": "number"
+                },
+                "total_precip_mm": {
+                    "type": "number"
+                },
+                "uv": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.Feedback": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "observed_condition": {
+                    "$ref": "#/definitions/model.ObservedCondition"
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "reported_temp": {
+                    "type": "number"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.FeedbackPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Feedback"
                     }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "/ready": {
-            "get": {
-                "description": "This endpoint returns the readiness status of the application.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "health"
-                ],
-                "summary": "Readiness check endpoint",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "dto.WeatherFeedbackReq": {
+        "model.FeedbackReport": {
             "type": "object",
             "properties": {
+                "cities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CityReport"
+                    }
+                },
                 "city": {
                     "type": "string"
                 },
-                "date": {
+                "from": {
                     "type": "string"
                 },
-                "message": {
+                "to": {
                     "type": "string"
                 }
             }
         },
-        "dto.WeatherFeedbackResp": {
+        "model.FieldSpread": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "disagree": {
+                    "type": "boolean"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "mean": {
+                    "type": "number"
+                },
+                "median": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "outliers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "spread": {
+                    "type": "number"
                 }
             }
         },
-        "model.Astro": {
+        "model.Forecast": {
             "type": "object",
             "properties": {
-                "sunrise": {
-                    "type": "string"
+                "astro": {
+                    "$ref": "#/definitions/model.Astro"
                 },
-                "sunset": {
-                    "type": "string"
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Day"
+                    }
+                },
+                "location": {
+                    "$ref": "#/definitions/model.Location"
+                },
+                "units": {
+                    "$ref": "#/definitions/model.Units"
                 }
             }
         },
-        "model.Current": {
+        "model.Hour": {
             "type": "object",
             "properties": {
+                "chance_of_rain": {
+                    "type": "integer"
+                },
+                "chance_of_snow": {
+                    "type": "integer"
+                },
                 "cloud": {
                     "type": "integer"
                 },
                 "condition": {
                     "type": "string"
                 },
-                "feelslike_c": {
+                "feelslike": {
                     "type": "number"
                 },
-                "heatindex_c": {
+                "feelslike_c": {
                     "type": "number"
                 },
                 "humidity": {
                     "type": "integer"
                 },
-                "last_updated": {
-                    "type": "string"
+                "precip": {
+                    "type": "number"
                 },
                 "precip_mm": {
-                    "type": "integer"
+                    "type": "number"
+                },
+                "pressure": {
+                    "type": "number"
                 },
                 "pressure_mb": {
-                    "type": "integer"
+                    "type": "number"
+                },
+                "temp": {
+                    "type": "number"
                 },
                 "temp_c": {
                     "type": "number"
                 },
+                "time": {
+                    "type": "string"
+                },
                 "uv": {
-                    "type": "integer"
+                    "type": "number"
+                },
+                "vis": {
+                    "type": "number"
                 },
                 "vis_km": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "wind_degree": {
                     "type": "integer"
@@ -238,6 +1806,9 @@ const docTemplate = `{
                 },
                 "wind_kph": {
                     "type": "number"
+                },
+                "wind_speed": {
+                    "type": "number"
                 }
             }
         },
@@ -270,17 +1841,236 @@ const docTemplate = `{
                 }
             }
         },
+        "model.LocationCandidate": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "type": "string"
+                },
+                "lat": {
+                    "type": "number"
+                },
+                "lon": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Meta": {
+            "type": "object",
+            "properties": {
+                "provider": {
+                    "type": "string"
+                },
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.ObservedCondition": {
+            "type": "string",
+            "enum": [
+                "clear",
+                "partly_cloudy",
+                "cloudy",
+                "fog",
+                "drizzle",
+                "rain",
+                "snow",
+                "sleet",
+                "thunderstorm"
+            ],
+            "x-enum-varnames": [
+                "ObservedClear",
+                "ObservedPartlyCloudy",
+                "ObservedCloudy",
+                "ObservedFog",
+                "ObservedDrizzle",
+                "ObservedRain",
+                "ObservedSnow",
+                "ObservedSleet",
+                "ObservedThunderstorm"
+            ]
+        },
+        "model.Pollen": {
+            "type": "object",
+            "properties": {
+                "alder": {
+                    "type": "number"
+                },
+                "birch": {
+                    "type": "number"
+                },
+                "grass": {
+                    "type": "number"
+                },
+                "hazel": {
+                    "type": "number"
+                },
+                "mugwort": {
+                    "type": "number"
+                },
+                "oak": {
+                    "type": "number"
+                },
+                "ragweed": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.ProviderAccuracy": {
+            "type": "object",
+            "properties": {
+                "avg_rating": {
+                    "type": "number"
+                },
+                "condition": {
+                    "$ref": "#/definitions/model.ConditionAccuracy"
+                },
+                "feedback": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "ratings": {
+                    "$ref": "#/definitions/model.RatingDistribution"
+                },
+                "temp": {
+                    "$ref": "#/definitions/model.TempDelta"
+                }
+            }
+        },
+        "model.RatingDistribution": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "integer"
+            }
+        },
+        "model.TempDelta": {
+            "type": "object",
+            "properties": {
+                "max_abs": {
+                    "type": "number"
+                },
+                "mean": {
+                    "type": "number"
+                },
+                "mean_abs": {
+                    "type": "number"
+                },
+                "samples": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.UnitSystem": {
+            "type": "string",
+            "enum": [
+                "metric",
+                "imperial",
+                "scientific"
+            ],
+            "x-enum-varnames": [
+                "UnitsMetric",
+                "UnitsImperial",
+                "UnitsScientific"
+            ]
+        },
+        "model.Units": {
+            "type": "object",
+            "properties": {
+                "precipitation": {
+                    "type": "string"
+                },
+                "pressure": {
+                    "type": "string"
+                },
+                "system": {
+                    "$ref": "#/definitions/model.UnitSystem"
+                },
+                "temperature": {
+                    "type": "string"
+                },
+                "visibility": {
+                    "type": "string"
+                },
+                "wind_speed": {
+                    "type": "string"
+                }
+            }
+        },
         "model.Weather": {
             "type": "object",
             "properties": {
+                "air_quality": {
+                    "$ref": "#/definitions/model.AirQuality"
+                },
+                "alerts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Alert"
+                    }
+                },
                 "astro": {
                     "$ref": "#/definitions/model.Astro"
                 },
+                "blend": {
+                    "$ref": "#/definitions/model.Blend"
+                },
                 "current": {
                     "$ref": "#/definitions/model.Current"
                 },
                 "location": {
                     "$ref": "#/definitions/model.Location"
+                },
+                "meta": {
+                    "$ref": "#/definitions/model.Meta"
+                },
+                "pollen": {
+                    "$ref": "#/definitions/model.Pollen"
+                },
+                "units": {
+                    "$ref": "#/definitions/model.Units"
+                }
+            }
+        },
+        "model.WeatherAlerts": {
+            "type": "object",
+            "properties": {
+                "alerts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Alert"
+                    }
+                },
+                "location": {
+                    "$ref": "#/definitions/model.Location"
+                }
+            }
+        },
+        "model.WeatherHistory": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Day"
+                    }
+                },
+                "location": {
+                    "$ref": "#/definitions/model.Location"
+                },
+                "units": {
+                    "$ref": "#/definitions/model.Units"
                 }
             }
         },
@@ -308,15 +2098,43 @@ const docTemplate = `{
                 "Not Found",
                 "Conflict",
                 "Unauthorized",
-                "Request Timeout"
+                "Forbidden",
+                "Request Timeout",
+                "Too Many Requests",
+                "Service Unavailable"
             ],
             "x-enum-varnames": [
                 "Validation",
                 "NotFound",
                 "Conflict",
                 "UnAuthorized",
-                "GatewayTimeout"
+                "Forbidden",
+                "GatewayTimeout",
+                "TooManyRequests",
+                "Unavailable"
             ]
+        },
+        "service.AggregatedWeather": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "weather": {
+                    "$ref": "#/definitions/model.Weather"
+                }
+            }
+        },
+        "service.WeatherUpdate": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "weather": {
+                    "$ref": "#/definitions/model.Weather"
+                }
+            }
         }
     }
 }`
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
 	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
 	"io"
 	"log/slog"
@@ -15,9 +16,17 @@ import (
 )
 
 type AstroClient interface {
-	GetByCity(ctx context.Context, city string) (*dto.AstroByCity, error)
+	GetByLocation(ctx context.Context, q model.LocationQuery) (*dto.AstroByCity, error)
+	GetForecastByLocation(ctx context.Context, q model.LocationQuery, days int) (*dto.AstroForecastByCity, error)
 }
 
+const (
+	// astroForecastStepsPerDay is the number of 3-hour steps OpenWeather returns per day.
+	astroForecastStepsPerDay = 8
+	// astroForecastMaxSteps caps the request to the 5-day range OpenWeather supports.
+	astroForecastMaxSteps = 40
+)
+
 type AstroAPIClient struct {
 	baseURL string
 	apiKey  string
@@ -33,9 +42,14 @@ func NewAstroAPIClient(openWeatherBaseURL, openWeatherApiKey string) AstroClient
 	}
 }
 
-func (api *AstroAPIClient) GetByCity(ctx context.Context, city string) (*dto.AstroByCity, error) {
+func (api *AstroAPIClient) GetByLocation(ctx context.Context, q model.LocationQuery) (*dto.AstroByCity, error) {
+
+	locationParams, err := astroLocationParams(q)
+	if err != nil {
+		return nil, err
+	}
 
-	astro, err := api.httpGetByCity(ctx, city)
+	astro, err := api.httpGetByLocation(ctx, q, locationParams)
 
 	if err != nil {
 		var apiErr AstroApiErr
@@ -49,10 +63,9 @@ func (api *AstroAPIClient) GetByCity(ctx context.Context, city string) (*dto.Ast
 	return astro, nil
 }
 
-func (api *AstroAPIClient) httpGetByCity(ctx context.Context, city string) (*dto.AstroByCity, error) {
+func (api *AstroAPIClient) httpGetByLocation(ctx context.Context, q model.LocationQuery, locationParams string) (*dto.AstroByCity, error) {
 
-	encodedCity := url.QueryEscape(city)
-	endpoint := fmt.Sprintf("/data/2.5/weather?q=%s&appid=%s", encodedCity, api.apiKey)
+	endpoint := fmt.Sprintf("/data/2.5/weather?%s&appid=%s", locationParams, api.apiKey)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.baseURL+endpoint, nil)
 	if err != nil {
@@ -68,8 +81,8 @@ func (api *AstroAPIClient) httpGetByCity(ctx context.Context, city string) (*dto
 	}(response.Body)
 
 	if response.StatusCode != http.StatusOK {
-		msg := "failed to get astro by city"
-		slog.Error(msg, slog.String("city", city), slog.String("status", response.Status))
+		msg := "failed to get astro by location"
+		slog.Error(msg, slog.String("location", q.String()), slog.String("status", response.Status))
 
 		var apiErr AstroApiErr
 		err = json.NewDecoder(response.Body).Decode(&apiErr)
@@ -89,6 +102,81 @@ func (api *AstroAPIClient) httpGetByCity(ctx context.Context, city string) (*dto
 	return &astro, nil
 }
 
+func (api *AstroAPIClient) GetForecastByLocation(ctx context.Context, q model.LocationQuery, days int) (*dto.AstroForecastByCity, error) {
+
+	locationParams, err := astroLocationParams(q)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast, err := api.httpGetForecastByLocation(ctx, q, locationParams, days)
+
+	if err != nil {
+		var apiErr AstroApiErr
+		ok := errors.As(err, &apiErr)
+		if ok && (apiErr.Cod == 0 || apiErr.Cod == 404) {
+			return nil, result.NotFoundErr(apiErr.Error())
+		}
+		return nil, result.InternalServerErr("Failed to fetch astronomy forecast data: " + err.Error())
+	}
+
+	return forecast, nil
+}
+
+func (api *AstroAPIClient) httpGetForecastByLocation(ctx context.Context, q model.LocationQuery, locationParams string, days int) (*dto.AstroForecastByCity, error) {
+
+	steps := min(days*astroForecastStepsPerDay, astroForecastMaxSteps)
+	endpoint := fmt.Sprintf("/data/2.5/forecast?%s&cnt=%d&appid=%s", locationParams, steps, api.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := api.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		msg := "failed to get astro forecast by location"
+		slog.Error(msg, slog.String("location", q.String()), slog.String("status", response.Status))
+
+		var apiErr AstroApiErr
+		err = json.NewDecoder(response.Body).Decode(&apiErr)
+		if err != nil {
+			return nil, apiErr
+		}
+
+		return nil, errors.New(msg)
+	}
+
+	var forecast dto.AstroForecastByCity
+	err = json.NewDecoder(response.Body).Decode(&forecast)
+	if err != nil {
+		return nil, err
+	}
+
+	return &forecast, nil
+}
+
+// astroLocationParams builds OpenWeather location query params. OpenWeather has no IP
+// geolocation, so IP queries have to be resolved to coordinates before calling it.
+func astroLocationParams(q model.LocationQuery) (string, error) {
+	switch q.Kind {
+	case model.LocationCity:
+		return "q=" + url.QueryEscape(q.City), nil
+	case model.LocationCoordinates:
+		return fmt.Sprintf("lat=%f&lon=%f", q.Lat, q.Lon), nil
+	case model.LocationPostcode:
+		return "zip=" + url.QueryEscape(q.Postcode), nil
+	}
+	return "", result.ValidationErr("Location query is not supported by OpenWeather: " + q.String())
+}
+
 // AstroApiErr represents an error response from the OpenWeather API.
 type AstroApiErr struct {
 	Cod     int    `json:"cod"`
@@ -3,57 +3,105 @@ package client
 import (
 	"context"
 	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
 	"time"
 )
 
 type MockWeatherClient struct {
-	Response *dto.WeatherByCity
-	Error    error
-	Delay    time.Duration
+	Response         *dto.WeatherByCity
+	ForecastResponse *dto.ForecastByCity
+	HistoryResponse  *dto.HistoryByCity
+	SearchResponse   []dto.SearchLocation
+	AlertsResponse   *dto.AlertsByCity
+	Error            error
+	Delay            time.Duration
 }
 
 func NewMockWeatherClient(err error, delay time.Duration) *MockWeatherClient {
+	location := dto.Location{
+		Name:           "London",
+		Region:         "City of London, Greater London",
+		Country:        "United Kingdom",
+		Lat:            12,
+		Lon:            12,
+		TzId:           "Europe/London",
+		LocaltimeEpoch: 1730668035,
+		Localtime:      "12:00",
+	}
 	return &MockWeatherClient{
 		Response: &dto.WeatherByCity{
-			Location: struct {
-				Name           string  `json:"name"`
-				Region         string  `json:"region"`
-				Country        string  `json:"country"`
-				Lat            float64 `json:"lat"`
-				Lon            float64 `json:"lon"`
-				TzId           string  `json:"tz_id"`
-				LocaltimeEpoch int     `json:"localtime_epoch"`
-				Localtime      string  `json:"localtime"`
-			}{
-				Name:           "London",
-				Region:         "City of London, Greater London",
-				Country:        "United Kingdom",
-				Lat:            12,
-				Lon:            12,
-				TzId:           "Europe/London",
-				LocaltimeEpoch: 1730668035,
-				Localtime:      "12:00",
-			},
+			Location: location,
+		},
+		ForecastResponse: &dto.ForecastByCity{
+			Location: location,
+		},
+		HistoryResponse: &dto.HistoryByCity{
+			Location: location,
+		},
+		AlertsResponse: &dto.AlertsByCity{
+			Location: location,
 		},
 		Error: err,
 		Delay: delay,
 	}
 }
 
-func (m *MockWeatherClient) GetByCity(ctx context.Context, _ string) (*dto.WeatherByCity, error) {
+func (m *MockWeatherClient) GetByLocation(ctx context.Context, _ model.LocationQuery, _ model.WeatherOptions) (*dto.WeatherByCity, error) {
+	if err := m.wait(ctx); err != nil {
+		return nil, err
+	}
+	return m.Response, m.Error
+}
+
+func (m *MockWeatherClient) GetForecastByLocation(ctx context.Context, _ model.LocationQuery, _ int, _ model.Language) (*dto.ForecastByCity, error) {
+	if err := m.wait(ctx); err != nil {
+		return nil, err
+	}
+	return m.ForecastResponse, m.Error
+}
+
+func (m *MockWeatherClient) GetHistoryByLocation(ctx context.Context, _ model.LocationQuery, date time.Time, _ model.Language) (*dto.HistoryByCity, error) {
+	if err := m.wait(ctx); err != nil {
+		return nil, err
+	}
+	if m.Error != nil {
+		return nil, m.Error
+	}
+	history := *m.HistoryResponse
+	history.Forecast.ForecastDay = []dto.ForecastDay{{Date: date.Format(time.DateOnly)}}
+	return &history, nil
+}
+
+func (m *MockWeatherClient) SearchLocations(ctx context.Context, _ string) ([]dto.SearchLocation, error) {
+	if err := m.wait(ctx); err != nil {
+		return nil, err
+	}
+	return m.SearchResponse, m.Error
+}
+
+func (m *MockWeatherClient) GetAlertsByLocation(ctx context.Context, _ model.LocationQuery) (*dto.AlertsByCity, error) {
+	if err := m.wait(ctx); err != nil {
+		return nil, err
+	}
+	return m.AlertsResponse, m.Error
+}
+
+func (m *MockWeatherClient) wait(ctx context.Context) error {
 	if m.Delay > 0 {
 		select {
 		case <-time.After(m.Delay):
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return ctx.Err()
 		}
 	}
-	return m.Response, m.Error
+	return nil
 }
 
 type MockAstroClient struct {
-	Response *dto.AstroByCity
-	Error    error
+	Response         *dto.AstroByCity
+	ForecastResponse *dto.AstroForecastByCity
+	Error            error
+	LastQuery        model.LocationQuery
 }
 
 func NewMockAstroClient(err error) *MockAstroClient {
@@ -67,10 +115,17 @@ func NewMockAstroClient(err error) *MockAstroClient {
 				Sunset  int    `json:"sunset"`
 			}{Type: 1, Id: 1, Country: "SRB", Sunrise: 1730668035, Sunset: 1730668035},
 		},
-		Error: err,
+		ForecastResponse: &dto.AstroForecastByCity{},
+		Error:            err,
 	}
 }
 
-func (m *MockAstroClient) GetByCity(_ context.Context, _ string) (*dto.AstroByCity, error) {
+func (m *MockAstroClient) GetByLocation(_ context.Context, q model.LocationQuery) (*dto.AstroByCity, error) {
+	m.LastQuery = q
 	return m.Response, m.Error
 }
+
+func (m *MockAstroClient) GetForecastByLocation(_ context.Context, q model.LocationQuery, _ int) (*dto.AstroForecastByCity, error) {
+	m.LastQuery = q
+	return m.ForecastResponse, m.Error
+}
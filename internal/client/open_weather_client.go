@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/util"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const ProviderOpenWeather = "openweather"
+
+// OpenWeatherClient serves current weather from OpenWeather. Forecast, history, search,
+// timezone and alerts are left to other providers and return ErrUnsupported.
+type OpenWeatherClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewOpenWeatherClient(openWeatherBaseURL, openWeatherApiKey string) WeatherClient {
+	cl := NewHttpClient(WithTimeout(3 * time.Second))
+	return &OpenWeatherClient{
+		baseURL: openWeatherBaseURL,
+		apiKey:  openWeatherApiKey,
+		client:  cl,
+	}
+}
+
+// GetByLocation fetches current weather. OpenWeather has no IP geolocation, pollen or air quality
+// in this endpoint, so such requests return ErrUnsupported and are served by the next provider.
+func (api *OpenWeatherClient) GetByLocation(ctx context.Context, q model.LocationQuery, opts model.WeatherOptions) (*dto.WeatherByCity, error) {
+	if q.Kind == model.LocationIP || opts.AirQuality || opts.Pollen {
+		return nil, unsupported("current weather for " + q.String() + opts.Key())
+	}
+
+	locationParams, err := astroLocationParams(q)
+	if err != nil {
+		return nil, err
+	}
+
+	weather, err := api.httpGetByLocation(ctx, q, locationParams, opts.Lang)
+
+	if err != nil {
+		var apiErr AstroApiErr
+		ok := errors.As(err, &apiErr)
+		if ok && apiErr.Cod == http.StatusNotFound {
+			return nil, result.NotFoundErr(apiErr.Error())
+		}
+		return nil, result.InternalServerErr("Failed to fetch weather data: " + err.Error())
+	}
+
+	return newWeatherByCityFromOpenWeather(weather), nil
+}
+
+func (api *OpenWeatherClient) httpGetByLocation(ctx context.Context, q model.LocationQuery, locationParams string, lang model.Language) (*dto.OpenWeatherByCity, error) {
+
+	endpoint := fmt.Sprintf("/data/2.5/weather?%s&units=metric&appid=%s%s", locationParams, api.apiKey, langParam(lang))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := api.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		msg := "failed to get open weather by location"
+		slog.Error(msg, slog.String("location", q.String()), slog.String("status", response.Status))
+
+		// OpenWeather reports cod as a string for this endpoint, so the HTTP status is used instead.
+		apiErr := AstroApiErr{Cod: response.StatusCode, Message: msg}
+		var body struct {
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(response.Body).Decode(&body); err == nil && body.Message != "" {
+			apiErr.Message = body.Message
+		}
+		return nil, apiErr
+	}
+
+	var weather dto.OpenWeatherByCity
+	if err := json.NewDecoder(response.Body).Decode(&weather); err != nil {
+		return nil, err
+	}
+
+	return &weather, nil
+}
+
+func (api *OpenWeatherClient) GetForecastByLocation(_ context.Context, q model.LocationQuery, _ int, _ model.Language) (*dto.ForecastByCity, error) {
+	return nil, unsupported("forecast for " + q.String())
+}
+
+func (api *OpenWeatherClient) GetHistoryByLocation(_ context.Context, q model.LocationQuery, _ time.Time, _ model.Language) (*dto.HistoryByCity, error) {
+	return nil, unsupported("history for " + q.String())
+}
+
+func (api *OpenWeatherClient) SearchLocations(_ context.Context, query string) ([]dto.SearchLocation, error) {
+	return nil, unsupported("location search for " + query)
+}
+
+func (api *OpenWeatherClient) GetAlertsByLocation(_ context.Context, q model.LocationQuery) (*dto.AlertsByCity, error) {
+	return nil, unsupported("alerts for " + q.String())
+}
+
+func unsupported(operation string) error {
+	return fmt.Errorf("%s %s: %w", ProviderOpenWeather, operation, ErrUnsupported)
+}
+
+// newWeatherByCityFromOpenWeather maps metric OpenWeather data to the weatherapi shape used by the model.
+// OpenWeather reports no heat index, UV index, region or timezone name, so those stay empty.
+func newWeatherByCityFromOpenWeather(ow *dto.OpenWeatherByCity) *dto.WeatherByCity {
+	var weather dto.WeatherByCity
+
+	localtime := util.UnixToLocal(int64(ow.Dt), ow.Timezone)
+	weather.Location = dto.Location{
+		Name:           ow.Name,
+		Country:        ow.Sys.Country,
+		Lat:            ow.Coord.Lat,
+		Lon:            ow.Coord.Lon,
+		LocaltimeEpoch: ow.Dt,
+		Localtime:      localtime,
+	}
+
+	c := &weather.Current
+	c.LastUpdatedEpoch = ow.Dt
+	c.LastUpdated = localtime
+	if ow.Dt >= ow.Sys.Sunrise && ow.Dt < ow.Sys.Sunset {
+		c.IsDay = 1
+	}
+	if len(ow.Weather) > 0 {
+		c.Condition = dto.Condition{
+			Text: capitalize(ow.Weather[0].Description),
+			Icon: ow.Weather[0].Icon,
+			Code: weatherAPIConditionCode(ow.Weather[0].Id),
+		}
+	}
+
+	c.TempC = ow.Main.Temp
+	c.TempF = roundTo(celsiusToFahrenheit(ow.Main.Temp), 1)
+	c.FeelslikeC = ow.Main.FeelsLike
+	c.FeelslikeF = roundTo(celsiusToFahrenheit(ow.Main.FeelsLike), 1)
+	c.Humidity = ow.Main.Humidity
+	c.Cloud = ow.Clouds.All
+
+	c.WindKph = roundTo(ow.Wind.Speed*3.6, 1)
+	c.WindMph = roundTo(ow.Wind.Speed*2.236936, 1)
+	c.GustKph = roundTo(ow.Wind.Gust*3.6, 1)
+	c.GustMph = roundTo(ow.Wind.Gust*2.236936, 1)
+	c.WindDegree = ow.Wind.Deg
+	c.WindDir = compassDirection(ow.Wind.Deg)
+
+	c.PressureMb = ow.Main.Pressure
+	c.PressureIn = roundTo(ow.Main.Pressure*0.02953, 2)
+	c.PrecipMm = ow.Rain.OneHour + ow.Snow.OneHour
+	c.PrecipIn = roundTo(c.PrecipMm/25.4, 2)
+	c.VisKm = float64(ow.Visibility) / 1000
+	c.VisMiles = roundTo(c.VisKm/1.609344, 1)
+
+	return &weather
+}
+
+var compassPoints = []string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// weatherAPIConditionCode maps an OpenWeather condition id to the closest weatherapi condition code.
+func weatherAPIConditionCode(id int) int {
+	switch {
+	case id >= 200 && id < 300:
+		return 1087 // thundery outbreaks
+	case id >= 300 && id < 400:
+		return 1153 // light drizzle
+	case id == 500:
+		return 1183 // light rain
+	case id == 501:
+		return 1189 // moderate rain
+	case id >= 502 && id <= 504:
+		return 1195 // heavy rain
+	case id == 511:
+		return 1201 // freezing rain
+	case id >= 520 && id < 600:
+		return 1243 // rain showers
+	case id == 600:
+		return 1213 // light snow
+	case id == 601:
+		return 1219 // moderate snow
+	case id == 602:
+		return 1225 // heavy snow
+	case id >= 611 && id <= 616:
+		return 1204 // sleet
+	case id >= 620 && id < 700:
+		return 1255 // snow showers
+	case id == 741:
+		return 1135 // fog
+	case id >= 700 && id < 800:
+		return 1030 // mist
+	case id == 800:
+		return 1000 // clear
+	case id == 801 || id == 802:
+		return 1003 // partly cloudy
+	case id == 803:
+		return 1006 // cloudy
+	default:
+		return 1009 // overcast
+	}
+}
+
+// compassDirection converts wind degrees to a 16-point compass direction, as weatherapi reports it.
+func compassDirection(deg int) string {
+	i := int(float64(deg%360)/22.5+0.5) % len(compassPoints)
+	return compassPoints[i]
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+func celsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+func roundTo(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
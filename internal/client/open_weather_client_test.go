@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const openWeatherLondon = `{
+	"coord": {"lon": -0.13, "lat": 51.51},
+	"weather": [{"id": 500, "main": "Rain", "description": "light rain", "icon": "10d"}],
+	"main": {"temp": 12.5, "feels_like": 11.2, "pressure": 1012, "humidity": 81},
+	"visibility": 8000,
+	"wind": {"speed": 5, "deg": 230, "gust": 9},
+	"clouds": {"all": 75},
+	"rain": {"1h": 0.4},
+	"dt": 1730668035,
+	"sys": {"country": "GB", "sunrise": 1730617200, "sunset": 1730652000},
+	"timezone": 0,
+	"name": "London",
+	"cod": 200
+}`
+
+func TestOpenWeatherClient_GetByLocation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/data/2.5/weather" || r.URL.Query().Get("units") != "metric" || r.URL.Query().Get("q") != "London" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		_, _ = w.Write([]byte(openWeatherLondon))
+	}))
+	defer srv.Close()
+
+	cl := NewOpenWeatherClient(srv.URL, "key")
+
+	weather, err := cl.GetByLocation(context.Background(), model.NewCityQuery("London"), model.WeatherOptions{})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	c := weather.Current
+	if weather.Location.Name != "London" || weather.Location.Country != "GB" {
+		t.Errorf("Unexpected location %+v", weather.Location)
+	}
+	if c.TempC != 12.5 || c.FeelslikeC != 11.2 || c.Humidity != 81 || c.PressureMb != 1012 || c.Cloud != 75 {
+		t.Errorf("Unexpected measurements %+v", c)
+	}
+	if c.WindKph != 18 || c.WindDir != "SW" || c.VisKm != 8 || c.PrecipMm != 0.4 {
+		t.Errorf("Unexpected converted values: wind %v %s, vis %v, precip %v", c.WindKph, c.WindDir, c.VisKm, c.PrecipMm)
+	}
+	if c.Condition.Text != "Light rain" || c.Condition.Code != 1183 || c.IsDay != 0 {
+		t.Errorf("Unexpected condition %+v, is day %d", c.Condition, c.IsDay)
+	}
+	if c.HeatindexC != nil || c.HeatindexF != nil || c.Uv != nil {
+		t.Errorf("Expected no heat index and UV index, got %v, %v, %v", c.HeatindexC, c.HeatindexF, c.Uv)
+	}
+}
+
+func TestOpenWeatherClient_GetByLocation_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"cod": "404", "message": "city not found"}`))
+	}))
+	defer srv.Close()
+
+	cl := NewOpenWeatherClient(srv.URL, "key")
+
+	_, err := cl.GetByLocation(context.Background(), model.NewCityQuery("Atlantis"), model.WeatherOptions{})
+
+	var resErr *result.Err
+	if !errors.As(err, &resErr) || resErr.Status != http.StatusNotFound {
+		t.Fatalf("Expected not found error, got %v", err)
+	}
+}
+
+func TestOpenWeatherClient_Unsupported(t *testing.T) {
+	cl := NewOpenWeatherClient("http://localhost", "key")
+
+	_, err := cl.GetByLocation(context.Background(), model.NewIPQuery(""), model.WeatherOptions{})
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected unsupported error for IP query, got %v", err)
+	}
+
+	_, err = cl.GetForecastByLocation(context.Background(), model.NewCityQuery("London"), 3, "")
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected unsupported error for forecast, got %v", err)
+	}
+}
+
+func TestCompassDirection(t *testing.T) {
+	tests := map[int]string{0: "N", 11: "N", 12: "NNE", 90: "E", 230: "SW", 340: "NNW", 355: "N", 360: "N"}
+	for deg, expected := range tests {
+		if dir := compassDirection(deg); dir != expected {
+			t.Errorf("compassDirection(%d) = %s; want %s", deg, dir, expected)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+const ProviderWeatherAPI = "weatherapi"
+
+// ErrUnsupported is returned by providers for operations their upstream API does not offer,
+// so callers can move on to the next provider.
+var ErrUnsupported = errors.New("operation not supported by provider")
+
+// Provider is a named weather source. Providers with a lower priority value are asked first.
+type Provider struct {
+	Name     string
+	Priority int
+	Client   WeatherClient
+}
+
+// ProviderRegistry keeps the registered weather providers ordered by priority.
+type ProviderRegistry struct {
+	mu        sync.RWMutex
+	providers []Provider
+}
+
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{}
+}
+
+// Register adds a provider, replacing any provider registered under the same name.
+// Providers with equal priority keep their registration order.
+func (r *ProviderRegistry) Register(name string, priority int, cl WeatherClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	providers := make([]Provider, 0, len(r.providers)+1)
+	for _, p := range r.providers {
+		if p.Name != name {
+			providers = append(providers, p)
+		}
+	}
+	providers = append(providers, Provider{Name: name, Priority: priority, Client: cl})
+	sort.SliceStable(providers, func(i, j int) bool {
+		return providers[i].Priority < providers[j].Priority
+	})
+	r.providers = providers
+}
+
+// Providers returns the providers in the order they should be asked.
+func (r *ProviderRegistry) Providers() []Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]Provider(nil), r.providers...)
+}
@@ -0,0 +1,34 @@
+package client
+
+import "testing"
+
+func TestProviderRegistry_OrdersByPriority(t *testing.T) {
+	r := NewProviderRegistry()
+	r.Register("backup", 2, nil)
+	r.Register("primary", 1, nil)
+	r.Register("fallback", 2, nil)
+
+	providers := r.Providers()
+
+	expected := []string{"primary", "backup", "fallback"}
+	if len(providers) != len(expected) {
+		t.Fatalf("Expected %d providers, got %d", len(expected), len(providers))
+	}
+	for i, name := range expected {
+		if providers[i].Name != name {
+			t.Errorf("Expected provider %d to be %q, got %q", i, name, providers[i].Name)
+		}
+	}
+}
+
+func TestProviderRegistry_RegisterReplacesByName(t *testing.T) {
+	r := NewProviderRegistry()
+	r.Register("primary", 1, nil)
+	r.Register("primary", 3, nil)
+
+	providers := r.Providers()
+
+	if len(providers) != 1 || providers[0].Priority != 3 {
+		t.Fatalf("Expected a single re-registered provider, got %+v", providers)
+	}
+}
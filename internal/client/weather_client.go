@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
 	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
 	"io"
 	"log/slog"
@@ -15,7 +16,11 @@ import (
 )
 
 type WeatherClient interface {
-	GetByCity(ctx context.Context, city string) (*dto.WeatherByCity, error)
+	GetByLocation(ctx context.Context, q model.LocationQuery, opts model.WeatherOptions) (*dto.WeatherByCity, error)
+	GetForecastByLocation(ctx context.Context, q model.LocationQuery, days int, lang model.Language) (*dto.ForecastByCity, error)
+	GetHistoryByLocation(ctx context.Context, q model.LocationQuery, date time.Time, lang model.Language) (*dto.HistoryByCity, error)
+	SearchLocations(ctx context.Context, query string) ([]dto.SearchLocation, error)
+	GetAlertsByLocation(ctx context.Context, q model.LocationQuery) (*dto.AlertsByCity, error)
 }
 
 type APIWeatherClient struct {
@@ -33,9 +38,9 @@ func NewWeatherAPIClient(weatherBaseURL, weatherApiKey string) WeatherClient {
 	}
 }
 
-func (api *APIWeatherClient) GetByCity(ctx context.Context, city string) (*dto.WeatherByCity, error) {
+func (api *APIWeatherClient) GetByLocation(ctx context.Context, q model.LocationQuery, opts model.WeatherOptions) (*dto.WeatherByCity, error) {
 
-	weather, err := api.httpGetByCity(ctx, city)
+	weather, err := api.httpGetByLocation(ctx, q, opts)
 
 	if err != nil {
 		var apiErr WeatherApiErr
@@ -49,10 +54,17 @@ func (api *APIWeatherClient) GetByCity(ctx context.Context, city string) (*dto.W
 	return weather, nil
 }
 
-func (api *APIWeatherClient) httpGetByCity(ctx context.Context, city string) (*dto.WeatherByCity, error) {
+func (api *APIWeatherClient) httpGetByLocation(ctx context.Context, q model.LocationQuery, opts model.WeatherOptions) (*dto.WeatherByCity, error) {
 
-	encodedCity := url.QueryEscape(city)
-	endpoint := fmt.Sprintf("/current.json?key=%s&q=%s&aqi=no", api.apiKey, encodedCity)
+	encodedQuery := url.QueryEscape(q.String())
+	endpoint := fmt.Sprintf(
+		"/current.json?key=%s&q=%s&aqi=%s&pollen=%s%s",
+		api.apiKey,
+		encodedQuery,
+		yesNo(opts.AirQuality),
+		yesNo(opts.Pollen),
+		langParam(opts.Lang),
+	)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.baseURL+endpoint, nil)
 
@@ -70,8 +82,8 @@ func (api *APIWeatherClient) httpGetByCity(ctx context.Context, city string) (*d
 	}(response.Body)
 
 	if response.StatusCode != http.StatusOK {
-		msg := "failed to get weather by city"
-		slog.Error(msg, slog.String("city", city), slog.String("status", response.Status))
+		msg := "failed to get weather by location"
+		slog.Error(msg, slog.String("location", q.String()), slog.String("status", response.Status))
 		var apiErr WeatherApiErr
 		if err := json.NewDecoder(response.Body).Decode(&apiErr); err != nil {
 			return nil, err
@@ -87,6 +99,214 @@ func (api *APIWeatherClient) httpGetByCity(ctx context.Context, city string) (*d
 	return &weather, nil
 }
 
+func (api *APIWeatherClient) GetForecastByLocation(ctx context.Context, q model.LocationQuery, days int, lang model.Language) (*dto.ForecastByCity, error) {
+
+	forecast, err := api.httpGetForecastByLocation(ctx, q, days, lang)
+
+	if err != nil {
+		var apiErr WeatherApiErr
+		ok := errors.As(err, &apiErr)
+		if ok && apiErr.Err.Code == 1006 {
+			return nil, result.NotFoundErr(apiErr.Error())
+		}
+		return nil, result.InternalServerErr("Failed to fetch forecast data: " + err.Error())
+	}
+
+	return forecast, nil
+}
+
+func (api *APIWeatherClient) httpGetForecastByLocation(ctx context.Context, q model.LocationQuery, days int, lang model.Language) (*dto.ForecastByCity, error) {
+
+	encodedQuery := url.QueryEscape(q.String())
+	endpoint := fmt.Sprintf("/forecast.json?key=%s&q=%s&days=%d&aqi=no&alerts=no%s", api.apiKey, encodedQuery, days, langParam(lang))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := api.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		msg := "failed to get forecast by location"
+		slog.Error(msg, slog.String("location", q.String()), slog.String("status", response.Status))
+		var apiErr WeatherApiErr
+		if err := json.NewDecoder(response.Body).Decode(&apiErr); err != nil {
+			return nil, err
+		}
+		return nil, apiErr
+	}
+
+	var forecast dto.ForecastByCity
+	if err := json.NewDecoder(response.Body).Decode(&forecast); err != nil {
+		return nil, err
+	}
+
+	return &forecast, nil
+}
+
+func (api *APIWeatherClient) GetHistoryByLocation(ctx context.Context, q model.LocationQuery, date time.Time, lang model.Language) (*dto.HistoryByCity, error) {
+
+	history, err := api.httpGetHistoryByLocation(ctx, q, date, lang)
+
+	if err != nil {
+		var apiErr WeatherApiErr
+		ok := errors.As(err, &apiErr)
+		if ok && apiErr.Err.Code == 1006 {
+			return nil, result.NotFoundErr(apiErr.Error())
+		}
+		return nil, result.InternalServerErr("Failed to fetch history data: " + err.Error())
+	}
+
+	return history, nil
+}
+
+func (api *APIWeatherClient) httpGetHistoryByLocation(ctx context.Context, q model.LocationQuery, date time.Time, lang model.Language) (*dto.HistoryByCity, error) {
+
+	encodedQuery := url.QueryEscape(q.String())
+	endpoint := fmt.Sprintf("/history.json?key=%s&q=%s&dt=%s%s", api.apiKey, encodedQuery, date.Format(time.DateOnly), langParam(lang))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := api.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		msg := "failed to get history by location"
+		slog.Error(msg, slog.String("location", q.String()), slog.String("status", response.Status))
+		var apiErr WeatherApiErr
+		if err := json.NewDecoder(response.Body).Decode(&apiErr); err != nil {
+			return nil, err
+		}
+		return nil, apiErr
+	}
+
+	var history dto.HistoryByCity
+	if err := json.NewDecoder(response.Body).Decode(&history); err != nil {
+		return nil, err
+	}
+
+	return &history, nil
+}
+
+func (api *APIWeatherClient) SearchLocations(ctx context.Context, query string) ([]dto.SearchLocation, error) {
+
+	locations, err := api.httpSearchLocations(ctx, query)
+
+	if err != nil {
+		return nil, result.InternalServerErr("Failed to search locations: " + err.Error())
+	}
+
+	return locations, nil
+}
+
+func (api *APIWeatherClient) httpSearchLocations(ctx context.Context, query string) ([]dto.SearchLocation, error) {
+
+	encodedQuery := url.QueryEscape(query)
+	endpoint := fmt.Sprintf("/search.json?key=%s&q=%s", api.apiKey, encodedQuery)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := api.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		msg := "failed to search locations"
+		slog.Error(msg, slog.String("query", query), slog.String("status", response.Status))
+		var apiErr WeatherApiErr
+		if err := json.NewDecoder(response.Body).Decode(&apiErr); err != nil {
+			return nil, err
+		}
+		return nil, apiErr
+	}
+
+	var locations []dto.SearchLocation
+	if err := json.NewDecoder(response.Body).Decode(&locations); err != nil {
+		return nil, err
+	}
+
+	return locations, nil
+}
+
+func (api *APIWeatherClient) GetAlertsByLocation(ctx context.Context, q model.LocationQuery) (*dto.AlertsByCity, error) {
+
+	alerts, err := api.httpGetAlertsByLocation(ctx, q)
+
+	if err != nil {
+		var apiErr WeatherApiErr
+		ok := errors.As(err, &apiErr)
+		if ok && apiErr.Err.Code == 1006 {
+			return nil, result.NotFoundErr(apiErr.Error())
+		}
+		return nil, result.InternalServerErr("Failed to fetch alerts data: " + err.Error())
+	}
+
+	return alerts, nil
+}
+
+// httpGetAlertsByLocation uses the forecast endpoint, as weatherapi only serves alerts with forecasts.
+func (api *APIWeatherClient) httpGetAlertsByLocation(ctx context.Context, q model.LocationQuery) (*dto.AlertsByCity, error) {
+
+	encodedQuery := url.QueryEscape(q.String())
+	endpoint := fmt.Sprintf("/forecast.json?key=%s&q=%s&days=1&aqi=no&alerts=yes", api.apiKey, encodedQuery)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := api.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		msg := "failed to get alerts by location"
+		slog.Error(msg, slog.String("location", q.String()), slog.String("status", response.Status))
+		var apiErr WeatherApiErr
+		if err := json.NewDecoder(response.Body).Decode(&apiErr); err != nil {
+			return nil, err
+		}
+		return nil, apiErr
+	}
+
+	var alerts dto.AlertsByCity
+	if err := json.NewDecoder(response.Body).Decode(&alerts); err != nil {
+		return nil, err
+	}
+
+	return &alerts, nil
+}
+
 // WeatherApiErr
 // code: 1006 No matching location found
 type WeatherApiErr struct {
@@ -99,3 +319,18 @@ type WeatherApiErr struct {
 func (w WeatherApiErr) Error() string {
 	return w.Err.Message
 }
+
+func yesNo(enabled bool) string {
+	if enabled {
+		return "yes"
+	}
+	return "no"
+}
+
+// langParam returns the lang query param localizing condition text, empty for English.
+func langParam(lang model.Language) string {
+	if lang == "" {
+		return ""
+	}
+	return "&lang=" + string(lang)
+}
@@ -1,9 +1,15 @@
 package config
 
 import (
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/internal/service"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/middleware"
 	"log/slog"
+	"net/netip"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 import (
 	"github.com/joho/godotenv"
@@ -14,14 +20,59 @@ type Env struct {
 	Port        string
 	CorsOrigins string
 
+	// TrustedProxies are the proxies whose forwarding header, TrustedProxyHeader, identifies
+	// clients, see middleware.ClientResolver. ClientIPv6PrefixLen groups IPv6 clients by network.
+	TrustedProxies      []netip.Prefix
+	TrustedProxyHeader  string
+	ClientIPv6PrefixLen int
+
 	WeatherUrl    string
 	WeatherApiKey string
 
 	OpenWeatherUrl    string
 	OpenWeatherApiKey string
 
+	// WeatherProviders lists current weather providers by priority, the first one is asked first.
+	WeatherProviders []string
+	// WeatherPollInterval is how often the weather of subscribed cities is fetched.
+	WeatherPollInterval time.Duration
+	// WeatherMaxPolledCities caps the cities polled for subscribers at once.
+	WeatherMaxPolledCities int
+	// WeatherMaxClientSubscriptions caps the weather subscriptions a client can keep open.
+	WeatherMaxClientSubscriptions int
+
+	// AuthUsersFile is a JSON file of users with bcrypt password hashes and roles. Without it,
+	// the Basic Auth username and password configure a single admin.
+	AuthUsersFile     string
 	BasicAuthUsername string
 	BasicAuthPassword string
+
+	// JWTSecret verifies HS256 and JWTJWKSFile RS256 and ES256 bearer tokens from our SSO.
+	// Bearer tokens are rejected when neither is set.
+	JWTSecret   string
+	JWTJWKSFile string
+	JWTAudience string
+	JWTIssuer   string
+
+	// RateLimitPolicyFile maps routes to rate limits, see middleware.RateLimitPolicy. It is reloaded
+	// when it changes. Without it the default policy of the api applies.
+	RateLimitPolicyFile string
+	// RateLimitAlgorithm selects how the rate limiters count requests, unless a rule sets its own.
+	RateLimitAlgorithm middleware.LimiterAlgorithm
+	// RateLimitMaxClients caps the clients every rate limiter tracks.
+	RateLimitMaxClients int
+	// RateLimitRedisAddr shares rate limits between replicas through Redis. Without it every
+	// replica limits on its own.
+	RateLimitRedisAddr     string
+	RateLimitRedisPassword string
+
+	// ApiKeyPlans maps the plans API keys are issued on to the requests per minute they allow.
+	ApiKeyPlans map[string]int
+
+	// Storage selects the feedback storage, either "sqlite" or "memory". Neither is shared between
+	// replicas, sqlite needs SQLitePath on a persistent volume to outlive the container.
+	Storage    string
+	SQLitePath string
 }
 
 func Load() Env {
@@ -40,6 +91,27 @@ func Load() Env {
 		origins = []string{"*"}
 	}
 
+	trustedProxies, err := middleware.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		panic("TRUSTED_PROXIES must be a comma separated list of CIDRs and IP addresses: " + err.Error())
+	}
+	trustedProxyHeader := middleware.HeaderXForwardedFor
+	if header := os.Getenv("TRUSTED_PROXY_HEADER"); header != "" {
+		parsed, ok := middleware.ParseTrustedHeader(header)
+		if !ok {
+			panic(fmt.Sprintf("TRUSTED_PROXY_HEADER must be one of: %s, %s, %s", middleware.HeaderForwarded, middleware.HeaderXForwardedFor, middleware.HeaderXRealIP))
+		}
+		trustedProxyHeader = parsed
+	}
+	clientIPv6PrefixLen := middleware.DefaultIPv6PrefixLen
+	if prefixLen := os.Getenv("CLIENT_IPV6_PREFIX"); prefixLen != "" {
+		parsed, err := strconv.Atoi(prefixLen)
+		if err != nil || parsed < 1 || parsed > 128 {
+			panic("CLIENT_IPV6_PREFIX must be a prefix length between 1 and 128")
+		}
+		clientIPv6PrefixLen = parsed
+	}
+
 	wUrl := os.Getenv("WEATHER_API_URL")
 	if wUrl == "" {
 		panic("WEATHER_API_URL is required")
@@ -58,22 +130,137 @@ func Load() Env {
 		panic("OPEN_WEATHER_API_KEY is required")
 	}
 
-	basicAuthUsername := os.Getenv("BASIC_AUTH_USERNAME")
-	if basicAuthUsername == "" {
-		panic("BASIC_AUTH_USERNAME is required")
+	providers := os.Getenv("WEATHER_PROVIDERS")
+	if providers == "" {
+		providers = "weatherapi,openweather"
+	}
+	var weatherProviders []string
+	for _, provider := range strings.Split(providers, ",") {
+		if provider = strings.TrimSpace(provider); provider != "" {
+			weatherProviders = append(weatherProviders, provider)
+		}
+	}
+
+	weatherPollInterval := time.Minute
+	if interval := os.Getenv("WEATHER_POLL_INTERVAL"); interval != "" {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil || parsed <= 0 {
+			panic("WEATHER_POLL_INTERVAL must be a positive duration, e.g. 30s or 2m")
+		}
+		weatherPollInterval = parsed
+	}
+
+	weatherMaxPolledCities := service.DefaultMaxPolledCities
+	if maxCities := os.Getenv("WEATHER_MAX_POLLED_CITIES"); maxCities != "" {
+		parsed, err := strconv.Atoi(maxCities)
+		if err != nil || parsed < 1 {
+			panic("WEATHER_MAX_POLLED_CITIES must be a positive number")
+		}
+		weatherMaxPolledCities = parsed
+	}
+
+	weatherMaxClientSubscriptions := service.DefaultMaxClientSubscriptions
+	if maxSubscriptions := os.Getenv("WEATHER_MAX_CLIENT_SUBSCRIPTIONS"); maxSubscriptions != "" {
+		parsed, err := strconv.Atoi(maxSubscriptions)
+		if err != nil || parsed < 1 {
+			panic("WEATHER_MAX_CLIENT_SUBSCRIPTIONS must be a positive number")
+		}
+		weatherMaxClientSubscriptions = parsed
 	}
+
+	storage := os.Getenv("STORAGE")
+	if storage == "" {
+		storage = "sqlite"
+	}
+	if storage != "sqlite" && storage != "memory" {
+		panic("STORAGE must be one of: sqlite, memory")
+	}
+	sqlitePath := os.Getenv("SQLITE_PATH")
+	if sqlitePath == "" {
+		sqlitePath = "weather-radar.db"
+	}
+
+	authUsersFile := os.Getenv("AUTH_USERS_FILE")
+	basicAuthUsername := os.Getenv("BASIC_AUTH_USERNAME")
 	basicAuthPassword := os.Getenv("BASIC_AUTH_PASSWORD")
-	if basicAuthPassword == "" {
-		panic("BASIC_AUTH_PASSWORD is required")
+	if authUsersFile == "" && (basicAuthUsername == "" || basicAuthPassword == "") {
+		panic("AUTH_USERS_FILE or BASIC_AUTH_USERNAME and BASIC_AUTH_PASSWORD are required")
+	}
+
+	jwtSecret := os.Getenv("JWT_SECRET")
+	jwtJWKSFile := os.Getenv("JWT_JWKS_FILE")
+	jwtAudience := os.Getenv("JWT_AUDIENCE")
+	jwtIssuer := os.Getenv("JWT_ISSUER")
+	if (jwtSecret != "" || jwtJWKSFile != "") && (jwtAudience == "" || jwtIssuer == "") {
+		panic("JWT_AUDIENCE and JWT_ISSUER are required with JWT_SECRET or JWT_JWKS_FILE")
+	}
+
+	rateLimitAlgorithm := middleware.AlgorithmFixedWindow
+	if algorithm := os.Getenv("RATE_LIMIT_ALGORITHM"); algorithm != "" {
+		parsed, ok := middleware.ParseLimiterAlgorithm(algorithm)
+		if !ok {
+			panic(fmt.Sprintf("RATE_LIMIT_ALGORITHM must be one of: %v", middleware.LimiterAlgorithms()))
+		}
+		rateLimitAlgorithm = parsed
+	}
+
+	rateLimitMaxClients := middleware.DefaultMaxClients
+	if maxClients := os.Getenv("RATE_LIMIT_MAX_CLIENTS"); maxClients != "" {
+		parsed, err := strconv.Atoi(maxClients)
+		if err != nil || parsed < 1 {
+			panic("RATE_LIMIT_MAX_CLIENTS must be a positive number")
+		}
+		rateLimitMaxClients = parsed
+	}
+
+	rateLimitRedisAddr := os.Getenv("RATE_LIMIT_REDIS_ADDR")
+	if rateLimitRedisAddr != "" && rateLimitAlgorithm != middleware.AlgorithmFixedWindow && rateLimitAlgorithm != middleware.AlgorithmSlidingWindow {
+		panic("RATE_LIMIT_REDIS_ADDR requires the fixed-window or sliding-window RATE_LIMIT_ALGORITHM")
+	}
+
+	plans := os.Getenv("API_KEY_PLANS")
+	if plans == "" {
+		plans = "free:60,standard:600,partner:6000"
+	}
+	apiKeyPlans := make(map[string]int)
+	for _, plan := range strings.Split(plans, ",") {
+		name, rpm, ok := strings.Cut(strings.TrimSpace(plan), ":")
+		limit, err := strconv.Atoi(rpm)
+		if !ok || name == "" || err != nil || limit < 1 {
+			panic("API_KEY_PLANS must be a comma separated list of plan:requests_per_minute")
+		}
+		apiKeyPlans[strings.ToLower(name)] = limit
 	}
 
 	return Env{
-		ENV:               os.Getenv("ENV"),
-		CorsOrigins:       strings.Join(origins, ","),
-		Port:              port,
-		WeatherUrl:        wUrl,
-		WeatherApiKey:     wApiKey,
-		OpenWeatherUrl:    owUrl,
-		OpenWeatherApiKey: owApiKey,
+		ENV:                           os.Getenv("ENV"),
+		CorsOrigins:                   strings.Join(origins, ","),
+		Port:                          port,
+		TrustedProxies:                trustedProxies,
+		TrustedProxyHeader:            trustedProxyHeader,
+		ClientIPv6PrefixLen:           clientIPv6PrefixLen,
+		WeatherUrl:                    wUrl,
+		WeatherApiKey:                 wApiKey,
+		OpenWeatherUrl:                owUrl,
+		OpenWeatherApiKey:             owApiKey,
+		WeatherProviders:              weatherProviders,
+		WeatherPollInterval:           weatherPollInterval,
+		WeatherMaxPolledCities:        weatherMaxPolledCities,
+		WeatherMaxClientSubscriptions: weatherMaxClientSubscriptions,
+		AuthUsersFile:                 authUsersFile,
+		BasicAuthUsername:             basicAuthUsername,
+		BasicAuthPassword:             basicAuthPassword,
+		JWTSecret:                     jwtSecret,
+		JWTJWKSFile:                   jwtJWKSFile,
+		JWTAudience:                   jwtAudience,
+		JWTIssuer:                     jwtIssuer,
+		RateLimitPolicyFile:           os.Getenv("RATE_LIMIT_POLICY_FILE"),
+		RateLimitAlgorithm:            rateLimitAlgorithm,
+		RateLimitMaxClients:           rateLimitMaxClients,
+		RateLimitRedisAddr:            rateLimitRedisAddr,
+		RateLimitRedisPassword:        os.Getenv("RATE_LIMIT_REDIS_PASSWORD"),
+		ApiKeyPlans:                   apiKeyPlans,
+		Storage:                       storage,
+		SQLitePath:                    sqlitePath,
 	}
 }
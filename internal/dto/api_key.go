@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ApiKeyReq struct {
+	Tenant string `json:"tenant"`
+	Name   string `json:"name"`
+	Plan   string `json:"plan"`
+}
+
+// ApiKeyResp is returned once, when a key is issued. Key cannot be retrieved later.
+type ApiKeyResp struct {
+	ID        uuid.UUID `json:"id"`
+	Key       string    `json:"key"`
+	Tenant    string    `json:"tenant"`
+	Name      string    `json:"name"`
+	Plan      string    `json:"plan"`
+	CreatedAt time.Time `json:"created_at"`
+}
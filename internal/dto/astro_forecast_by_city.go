@@ -0,0 +1,30 @@
+package dto
+
+type AstroForecastByCity struct {
+	Cnt  int `json:"cnt"`
+	List []struct {
+		Dt     int `json:"dt"`
+		Clouds struct {
+			All int `json:"all"`
+		} `json:"clouds"`
+		Wind struct {
+			Speed float64 `json:"speed"`
+			Deg   int     `json:"deg"`
+			Gust  float64 `json:"gust"`
+		} `json:"wind"`
+		Pop   float64 `json:"pop"`
+		DtTxt string  `json:"dt_txt"`
+	} `json:"list"`
+	City struct {
+		Id      int    `json:"id"`
+		Name    string `json:"name"`
+		Country string `json:"country"`
+		Coord   struct {
+			Lat float64 `json:"lat"`
+			Lon float64 `json:"lon"`
+		} `json:"coord"`
+		Timezone int `json:"timezone"`
+		Sunrise  int `json:"sunrise"`
+		Sunset   int `json:"sunset"`
+	} `json:"city"`
+}
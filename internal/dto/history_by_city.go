@@ -0,0 +1,8 @@
+package dto
+
+type HistoryByCity struct {
+	Location Location `json:"location"`
+	Forecast struct {
+		ForecastDay []ForecastDay `json:"forecastday"`
+	} `json:"forecast"`
+}
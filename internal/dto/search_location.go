@@ -0,0 +1,11 @@
+package dto
+
+type SearchLocation struct {
+	Id      int     `json:"id"`
+	Name    string  `json:"name"`
+	Region  string  `json:"region"`
+	Country string  `json:"country"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Url     string  `json:"url"`
+}
@@ -1,11 +1,17 @@
 package dto
 
+import "github.com/google/uuid"
+
 type WeatherFeedbackResp struct {
 	Message string
+	ID      uuid.UUID
 }
 
 type WeatherFeedbackReq struct {
-	Date    string `json:"date"`
-	City    string `json:"city"`
-	Message string `json:"message"`
+	Date              string   `json:"date"`
+	City              string   `json:"city"`
+	Message           string   `json:"message"`
+	Rating            int      `json:"rating,omitempty"`
+	ObservedCondition string   `json:"observed_condition,omitempty"`
+	ReportedTemp      *float64 `json:"reported_temp,omitempty"`
 }
@@ -1,49 +1,76 @@
 package dto
 
+type Location struct {
+	Name           string  `json:"name"`
+	Region         string  `json:"region"`
+	Country        string  `json:"country"`
+	Lat            float64 `json:"lat"`
+	Lon            float64 `json:"lon"`
+	TzId           string  `json:"tz_id"`
+	LocaltimeEpoch int     `json:"localtime_epoch"`
+	Localtime      string  `json:"localtime"`
+}
+
+type Condition struct {
+	Text string `json:"text"`
+	Icon string `json:"icon"`
+	Code int    `json:"code"`
+}
+
 type WeatherByCity struct {
-	Location struct {
-		Name           string  `json:"name"`
-		Region         string  `json:"region"`
-		Country        string  `json:"country"`
-		Lat            float64 `json:"lat"`
-		Lon            float64 `json:"lon"`
-		TzId           string  `json:"tz_id"`
-		LocaltimeEpoch int     `json:"localtime_epoch"`
-		Localtime      string  `json:"localtime"`
-	} `json:"location"`
-	Current struct {
-		LastUpdatedEpoch int     `json:"last_updated_epoch"`
-		LastUpdated      string  `json:"last_updated"`
-		TempC            float64 `json:"temp_c"`
-		TempF            float64 `json:"temp_f"`
-		IsDay            int     `json:"is_day"`
-		Condition        struct {
-			Text string `json:"text"`
-			Icon string `json:"icon"`
-			Code int    `json:"code"`
-		} `json:"condition"`
-		WindMph    float64 `json:"wind_mph"`
-		WindKph    float64 `json:"wind_kph"`
-		WindDegree int     `json:"wind_degree"`
-		WindDir    string  `json:"wind_dir"`
-		PressureMb float64 `json:"pressure_mb"`
-		PressureIn float64 `json:"pressure_in"`
-		PrecipMm   float64 `json:"precip_mm"`
-		PrecipIn   float64 `json:"precip_in"`
-		Humidity   int     `json:"humidity"`
-		Cloud      int     `json:"cloud"`
-		FeelslikeC float64 `json:"feelslike_c"`
-		FeelslikeF float64 `json:"feelslike_f"`
-		WindchillC float64 `json:"windchill_c"`
-		WindchillF float64 `json:"windchill_f"`
-		HeatindexC float64 `json:"heatindex_c"`
-		HeatindexF float64 `json:"heatindex_f"`
-		DewpointC  float64 `json:"dewpoint_c"`
-		DewpointF  float64 `json:"dewpoint_f"`
-		VisKm      float64 `json:"vis_km"`
-		VisMiles   float64 `json:"vis_miles"`
-		Uv         float64 `json:"uv"`
-		GustMph    float64 `json:"gust_mph"`
-		GustKph    float64 `json:"gust_kph"`
+	Location Location `json:"location"`
+	Current  struct {
+		LastUpdatedEpoch int         `json:"last_updated_epoch"`
+		LastUpdated      string      `json:"last_updated"`
+		TempC            float64     `json:"temp_c"`
+		TempF            float64     `json:"temp_f"`
+		IsDay            int         `json:"is_day"`
+		Condition        Condition   `json:"condition"`
+		WindMph          float64     `json:"wind_mph"`
+		WindKph          float64     `json:"wind_kph"`
+		WindDegree       int         `json:"wind_degree"`
+		WindDir          string      `json:"wind_dir"`
+		PressureMb       float64     `json:"pressure_mb"`
+		PressureIn       float64     `json:"pressure_in"`
+		PrecipMm         float64     `json:"precip_mm"`
+		PrecipIn         float64     `json:"precip_in"`
+		Humidity         int         `json:"humidity"`
+		Cloud            int         `json:"cloud"`
+		FeelslikeC       float64     `json:"feelslike_c"`
+		FeelslikeF       float64     `json:"feelslike_f"`
+		WindchillC       float64     `json:"windchill_c"`
+		WindchillF       float64     `json:"windchill_f"`
+		HeatindexC       *float64    `json:"heatindex_c"`
+		HeatindexF       *float64    `json:"heatindex_f"`
+		DewpointC        float64     `json:"dewpoint_c"`
+		DewpointF        float64     `json:"dewpoint_f"`
+		VisKm            float64     `json:"vis_km"`
+		VisMiles         float64     `json:"vis_miles"`
+		Uv               *float64    `json:"uv"`
+		GustMph          float64     `json:"gust_mph"`
+		GustKph          float64     `json:"gust_kph"`
+		AirQuality       *AirQuality `json:"air_quality,omitempty"`
+		Pollen           *Pollen     `json:"pollen,omitempty"`
 	} `json:"current"`
 }
+
+type AirQuality struct {
+	Co           float64 `json:"co"`
+	No2          float64 `json:"no2"`
+	O3           float64 `json:"o3"`
+	So2          float64 `json:"so2"`
+	Pm25         float64 `json:"pm2_5"`
+	Pm10         float64 `json:"pm10"`
+	UsEpaIndex   int     `json:"us-epa-index"`
+	GbDefraIndex int     `json:"gb-defra-index"`
+}
+
+type Pollen struct {
+	Hazel   float64 `json:"Hazel"`
+	Alder   float64 `json:"Alder"`
+	Birch   float64 `json:"Birch"`
+	Oak     float64 `json:"Oak"`
+	Grass   float64 `json:"Grass"`
+	Mugwort float64 `json:"Mugwort"`
+	Ragweed float64 `json:"Ragweed"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import "github.com/DjordjeVuckovic/weather-radar/internal/dto"
+
+// AirQuality holds pollutant concentrations in μg/m3 and the US EPA index (1 good - 6 hazardous).
+type AirQuality struct {
+	Pm25       float64 `json:"pm2_5"`
+	Pm10       float64 `json:"pm10"`
+	O3         float64 `json:"o3"`
+	No2        float64 `json:"no2"`
+	Co         float64 `json:"co"`
+	So2        float64 `json:"so2"`
+	UsEpaIndex int     `json:"us_epa_index"`
+}
+
+// Pollen holds pollen counts in grains/m3.
+type Pollen struct {
+	Hazel   float64 `json:"hazel"`
+	Alder   float64 `json:"alder"`
+	Birch   float64 `json:"birch"`
+	Oak     float64 `json:"oak"`
+	Grass   float64 `json:"grass"`
+	Mugwort float64 `json:"mugwort"`
+	Ragweed float64 `json:"ragweed"`
+}
+
+func newAirQualityFromDto(aqDto *dto.AirQuality) *AirQuality {
+	if aqDto == nil {
+		return nil
+	}
+	return &AirQuality{
+		Pm25:       aqDto.Pm25,
+		Pm10:       aqDto.Pm10,
+		O3:         aqDto.O3,
+		No2:        aqDto.No2,
+		Co:         aqDto.Co,
+		So2:        aqDto.So2,
+		UsEpaIndex: aqDto.UsEpaIndex,
+	}
+}
+
+func newPollenFromDto(pollenDto *dto.Pollen) *Pollen {
+	if pollenDto == nil {
+		return nil
+	}
+	return &Pollen{
+		Hazel:   pollenDto.Hazel,
+		Alder:   pollenDto.Alder,
+		Birch:   pollenDto.Birch,
+		Oak:     pollenDto.Oak,
+		Grass:   pollenDto.Grass,
+		Mugwort: pollenDto.Mugwort,
+		Ragweed: pollenDto.Ragweed,
+	}
+}
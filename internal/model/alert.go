@@ -0,0 +1,123 @@
+package model
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Alert struct {
+	ID          string    `json:"id"`
+	Headline    string    `json:"headline"`
+	Event       string    `json:"event"`
+	Severity    string    `json:"severity"`
+	Urgency     string    `json:"urgency"`
+	Certainty   string    `json:"certainty"`
+	Category    string    `json:"category"`
+	Areas       string    `json:"areas"`
+	Effective   time.Time `json:"effective"`
+	Expires     time.Time `json:"expires"`
+	Description string    `json:"description"`
+	Instruction string    `json:"instruction"`
+}
+
+type WeatherAlerts struct {
+	Location `json:"location"`
+	Alerts   []Alert `json:"alerts"`
+}
+
+func NewWeatherAlertsFromDto(alertsDto *dto.AlertsByCity, now time.Time) *WeatherAlerts {
+	return &WeatherAlerts{
+		Location: newLocationFromDto(alertsDto.Location, 0),
+		Alerts:   NewAlertsFromDto(alertsDto, now),
+	}
+}
+
+// NewAlertsFromDto maps active alerts, dropping expired ones and duplicates, which weatherapi
+// returns for example when the same warning is issued for several areas or languages.
+func NewAlertsFromDto(alertsDto *dto.AlertsByCity, now time.Time) []Alert {
+	alerts := make([]Alert, 0, len(alertsDto.Alerts.Alert))
+	for _, a := range alertsDto.Alerts.Alert {
+		alerts = append(alerts, newAlertFromDto(a))
+	}
+	return ActiveAlerts(DedupeAlerts(alerts), now)
+}
+
+func newAlertFromDto(alertDto dto.Alert) Alert {
+	effective, _ := time.Parse(time.RFC3339, alertDto.Effective)
+	expires, _ := time.Parse(time.RFC3339, alertDto.Expires)
+
+	alert := Alert{
+		Headline:    alertDto.Headline,
+		Event:       alertDto.Event,
+		Severity:    alertDto.Severity,
+		Urgency:     alertDto.Urgency,
+		Certainty:   alertDto.Certainty,
+		Category:    alertDto.Category,
+		Areas:       alertDto.Areas,
+		Effective:   effective,
+		Expires:     expires,
+		Description: alertDto.Desc,
+		Instruction: alertDto.Instruction,
+	}
+	alert.ID = alertID(alert)
+	return alert
+}
+
+// alertID derives a stable identifier, so the same alert has the same ID across calls.
+func alertID(a Alert) string {
+	parts := []string{
+		strings.ToLower(strings.TrimSpace(a.Event)),
+		strings.ToLower(strings.TrimSpace(a.Areas)),
+		a.Effective.UTC().Format(time.RFC3339),
+		a.Expires.UTC().Format(time.RFC3339),
+	}
+	sum := sha1.Sum([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// DedupeAlerts removes alerts with the same ID, keeping the first occurrence.
+func DedupeAlerts(alerts []Alert) []Alert {
+	seen := make(map[string]struct{}, len(alerts))
+	deduped := make([]Alert, 0, len(alerts))
+	for _, a := range alerts {
+		if _, ok := seen[a.ID]; ok {
+			continue
+		}
+		seen[a.ID] = struct{}{}
+		deduped = append(deduped, a)
+	}
+	return deduped
+}
+
+// ActiveAlerts returns the alerts that have not expired yet, ordered by expiry.
+// Alerts without a known expiry are kept.
+func ActiveAlerts(alerts []Alert, now time.Time) []Alert {
+	active := make([]Alert, 0, len(alerts))
+	for _, a := range alerts {
+		if a.Expires.IsZero() || a.Expires.After(now) {
+			active = append(active, a)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].Expires.Before(active[j].Expires)
+	})
+	return active
+}
+
+// AlertsTTL returns how long the alerts stay valid, capped by maxTTL.
+func AlertsTTL(alerts []Alert, now time.Time, maxTTL time.Duration) time.Duration {
+	ttl := maxTTL
+	for _, a := range alerts {
+		if a.Expires.IsZero() {
+			continue
+		}
+		if untilExpiry := a.Expires.Sub(now); untilExpiry < ttl {
+			ttl = untilExpiry
+		}
+	}
+	return max(ttl, 0)
+}
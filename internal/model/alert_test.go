@@ -0,0 +1,47 @@
+package model
+
+import (
+	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"testing"
+	"time"
+)
+
+func TestNewAlertsFromDto(t *testing.T) {
+	now := time.Date(2024, 10, 27, 12, 0, 0, 0, time.UTC)
+
+	var alertsDto dto.AlertsByCity
+	alertsDto.Alerts.Alert = []dto.Alert{
+		{Event: "Wind warning", Areas: "Belgrade", Effective: "2024-10-27T10:00:00+01:00", Expires: "2024-10-27T20:00:00+01:00", Headline: "Wind"},
+		{Event: "Wind Warning ", Areas: "belgrade", Effective: "2024-10-27T09:00:00Z", Expires: "2024-10-27T19:00:00Z", Headline: "Vetar"},
+		{Event: "Fog", Areas: "Belgrade", Effective: "2024-10-27T09:00:00Z", Expires: "2024-10-27T14:00:00Z"},
+		{Event: "Frost", Areas: "Belgrade", Effective: "2024-10-26T20:00:00Z", Expires: "2024-10-27T08:00:00Z"},
+	}
+
+	alerts := NewAlertsFromDto(&alertsDto, now)
+
+	if len(alerts) != 2 {
+		t.Fatalf("Expected 2 active unique alerts, got %d: %+v", len(alerts), alerts)
+	}
+	if alerts[0].Event != "Fog" {
+		t.Errorf("Expected alerts ordered by expiry, got %s first", alerts[0].Event)
+	}
+	if alerts[1].Headline != "Wind" {
+		t.Errorf("Expected first occurrence of duplicate alert to be kept, got %s", alerts[1].Headline)
+	}
+}
+
+func TestAlertsTTL(t *testing.T) {
+	now := time.Date(2024, 10, 27, 12, 0, 0, 0, time.UTC)
+	alerts := []Alert{
+		{Expires: now.Add(2 * time.Hour)},
+		{Expires: now.Add(3 * time.Minute)},
+		{},
+	}
+
+	if ttl := AlertsTTL(alerts, now, 5*time.Minute); ttl != 3*time.Minute {
+		t.Errorf("Expected TTL to end with the earliest expiry, got %v", ttl)
+	}
+	if ttl := AlertsTTL(nil, now, 5*time.Minute); ttl != 5*time.Minute {
+		t.Errorf("Expected max TTL without alerts, got %v", ttl)
+	}
+}
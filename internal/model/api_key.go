@@ -0,0 +1,32 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/google/uuid"
+	"time"
+)
+
+// ApiKey identifies a partner app, its tenant and the plan its requests are limited by.
+// Only the SHA-256 hash of the key is stored, the key itself is shown once when issued.
+type ApiKey struct {
+	ID        uuid.UUID  `json:"id"`
+	Prefix    string     `json:"prefix"`
+	KeyHash   string     `json:"-"`
+	Tenant    string     `json:"tenant"`
+	Name      string     `json:"name"`
+	Plan      string     `json:"plan"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (k *ApiKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// HashApiKey hashes a key for storage and lookup. API keys are random and long, so a fast hash
+// is enough, unlike for passwords.
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,206 @@
+package model
+
+import (
+	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"math"
+	"slices"
+	"sort"
+)
+
+const BlendMethodMedian = "median"
+
+// BlendField names a current weather field that is blended across providers.
+type BlendField string
+
+const (
+	FieldTemp       BlendField = "temp"
+	FieldFeelsLike  BlendField = "feelslike"
+	FieldHumidity   BlendField = "humidity"
+	FieldPressure   BlendField = "pressure"
+	FieldWindSpeed  BlendField = "wind_speed"
+	FieldCloud      BlendField = "cloud"
+	FieldPrecip     BlendField = "precip"
+	FieldVisibility BlendField = "vis"
+)
+
+// outlierTolerance is the metric distance from the median beyond which a provider is an outlier,
+// and the spread beyond which providers disagree on a field.
+var outlierTolerance = map[BlendField]float64{
+	FieldTemp:       3,
+	FieldFeelsLike:  4,
+	FieldHumidity:   15,
+	FieldPressure:   5,
+	FieldWindSpeed:  10,
+	FieldCloud:      30,
+	FieldPrecip:     2,
+	FieldVisibility: 5,
+}
+
+// minOutlierSamples is the number of samples needed to tell which provider disagrees,
+// with two samples both are equally far from the median and only the disagreement is flagged.
+const minOutlierSamples = 3
+
+// BlendSource holds the metric values a single provider reported. Fields a provider does not
+// report are left out, so they do not pull the blended value towards zero.
+type BlendSource struct {
+	Provider string
+	Values   map[BlendField]float64
+}
+
+// FieldSpread describes how much providers agree on a single field. Disagree is set when the
+// spread is beyond the tolerance of the field, Outliers only once there are enough samples
+// to tell which providers are off.
+type FieldSpread struct {
+	Median   float64  `json:"median"`
+	Mean     float64  `json:"mean"`
+	Min      float64  `json:"min"`
+	Max      float64  `json:"max"`
+	Spread   float64  `json:"spread"`
+	Samples  int      `json:"samples"`
+	Disagree bool     `json:"disagree,omitempty"`
+	Outliers []string `json:"outliers,omitempty"`
+}
+
+// Blend reports how blended values were computed, on which fields providers disagreed and
+// which providers were outliers.
+type Blend struct {
+	Method        string                     `json:"method"`
+	Fields        map[BlendField]FieldSpread `json:"fields"`
+	Disagreements []BlendField               `json:"disagreements,omitempty"`
+	Outliers      []string                   `json:"outliers,omitempty"`
+}
+
+func NewBlendSourceFromDto(provider string, weatherDto *dto.WeatherByCity) BlendSource {
+	c := weatherDto.Current
+	return BlendSource{
+		Provider: provider,
+		Values: map[BlendField]float64{
+			FieldTemp:       c.TempC,
+			FieldFeelsLike:  c.FeelslikeC,
+			FieldHumidity:   float64(c.Humidity),
+			FieldPressure:   c.PressureMb,
+			FieldWindSpeed:  c.WindKph,
+			FieldCloud:      float64(c.Cloud),
+			FieldPrecip:     c.PrecipMm,
+			FieldVisibility: c.VisKm,
+		},
+	}
+}
+
+// NewBlendSourceFromAstro uses the wind and cloud cover OpenWeather returns with astronomy data.
+// Its wind speed is in m/s.
+func NewBlendSourceFromAstro(provider string, astroDto *dto.AstroByCity) BlendSource {
+	return BlendSource{
+		Provider: provider,
+		Values: map[BlendField]float64{
+			FieldWindSpeed: round(astroDto.Wind.Speed*3.6, 1),
+			FieldCloud:     float64(astroDto.Clouds.All),
+		},
+	}
+}
+
+// ApplyBlend replaces the current values with the median across sources and records the spread.
+func (w *Weather) ApplyBlend(sources []BlendSource) {
+	blend := &Blend{
+		Method: BlendMethodMedian,
+		Fields: make(map[BlendField]FieldSpread),
+	}
+
+	outliers := make(map[string]struct{})
+	for field := range outlierTolerance {
+		spread, ok := blendField(field, sources)
+		if !ok {
+			continue
+		}
+		blend.Fields[field] = spread
+		if spread.Disagree {
+			blend.Disagreements = append(blend.Disagreements, field)
+		}
+		for _, provider := range spread.Outliers {
+			outliers[provider] = struct{}{}
+		}
+		w.Current.setField(field, spread.Median)
+	}
+	w.Current.setMetric()
+
+	for provider := range outliers {
+		blend.Outliers = append(blend.Outliers, provider)
+	}
+	sort.Strings(blend.Outliers)
+	slices.Sort(blend.Disagreements)
+
+	w.Blend = blend
+}
+
+func blendField(field BlendField, sources []BlendSource) (FieldSpread, bool) {
+	var (
+		values    []float64
+		providers []string
+	)
+	for _, s := range sources {
+		if v, ok := s.Values[field]; ok {
+			values = append(values, v)
+			providers = append(providers, s.Provider)
+		}
+	}
+	if len(values) == 0 {
+		return FieldSpread{}, false
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+
+	spread := FieldSpread{
+		Median:  median(sorted),
+		Mean:    round(sum/float64(len(values)), 1),
+		Min:     sorted[0],
+		Max:     sorted[len(sorted)-1],
+		Spread:  round(sorted[len(sorted)-1]-sorted[0], 2),
+		Samples: len(values),
+		// Two samples are enough to tell that providers disagree.
+		Disagree: sorted[len(sorted)-1]-sorted[0] > outlierTolerance[field],
+	}
+
+	if len(values) >= minOutlierSamples {
+		for i, v := range values {
+			if math.Abs(v-spread.Median) > outlierTolerance[field] {
+				spread.Outliers = append(spread.Outliers, providers[i])
+			}
+		}
+	}
+	return spread, true
+}
+
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return round((sorted[n/2-1]+sorted[n/2])/2, 2)
+}
+
+func (c *Current) setField(field BlendField, v float64) {
+	switch field {
+	case FieldTemp:
+		c.Temp = v
+	case FieldFeelsLike:
+		c.FeelsLike = v
+	case FieldHumidity:
+		c.Humidity = int(math.Round(v))
+	case FieldPressure:
+		c.Pressure = v
+	case FieldWindSpeed:
+		c.WindSpeed = v
+	case FieldCloud:
+		c.Cloud = int(math.Round(v))
+	case FieldPrecip:
+		c.Precip = v
+	case FieldVisibility:
+		c.Visibility = v
+	}
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyBlend(t *testing.T) {
+	weather := &Weather{Current: Current{Temp: 10, Uv: 3}}
+	sources := []BlendSource{
+		{Provider: "a", Values: map[BlendField]float64{FieldTemp: 10, FieldWindSpeed: 12}},
+		{Provider: "b", Values: map[BlendField]float64{FieldTemp: 11, FieldWindSpeed: 14}},
+		{Provider: "c", Values: map[BlendField]float64{FieldTemp: 18}},
+	}
+
+	weather.ApplyBlend(sources)
+
+	if weather.Current.Temp != 11 {
+		t.Errorf("Expected median temperature 11, got %v", weather.Current.Temp)
+	}
+	if weather.Current.WindSpeed != 13 {
+		t.Errorf("Expected median wind speed 13 from two samples, got %v", weather.Current.WindSpeed)
+	}
+	if weather.Current.Uv != 3 {
+		t.Errorf("Expected fields that are not blended to stay, got uv %v", weather.Current.Uv)
+	}
+
+	temp := weather.Blend.Fields[FieldTemp]
+	if temp.Spread != 8 || temp.Mean != 13 || temp.Samples != 3 {
+		t.Errorf("Unexpected temperature spread %+v", temp)
+	}
+	if !reflect.DeepEqual(temp.Outliers, []string{"c"}) {
+		t.Errorf("Expected provider c to be a temperature outlier, got %v", temp.Outliers)
+	}
+	if wind := weather.Blend.Fields[FieldWindSpeed]; len(wind.Outliers) != 0 || wind.Disagree {
+		t.Errorf("Expected agreeing wind speeds without outliers, got %+v", wind)
+	}
+	if !temp.Disagree || !reflect.DeepEqual(weather.Blend.Disagreements, []BlendField{FieldTemp}) {
+		t.Errorf("Expected providers to disagree on temperature only, got %v", weather.Blend.Disagreements)
+	}
+	if !reflect.DeepEqual(weather.Blend.Outliers, []string{"c"}) {
+		t.Errorf("Expected outlier providers [c], got %v", weather.Blend.Outliers)
+	}
+	if _, ok := weather.Blend.Fields[FieldHumidity]; ok {
+		t.Error("Expected no humidity field without samples")
+	}
+}
+
+func TestApplyBlend_TwoSamplesDisagree(t *testing.T) {
+	weather := &Weather{}
+	weather.ApplyBlend([]BlendSource{
+		{Provider: "weatherapi", Values: map[BlendField]float64{FieldTemp: 10, FieldHumidity: 60}},
+		{Provider: "openweather", Values: map[BlendField]float64{FieldTemp: 16, FieldHumidity: 70}},
+	})
+
+	temp := weather.Blend.Fields[FieldTemp]
+	if !temp.Disagree || len(temp.Outliers) != 0 {
+		t.Errorf("Expected a disagreement without outliers from two samples, got %+v", temp)
+	}
+	if humidity := weather.Blend.Fields[FieldHumidity]; humidity.Disagree {
+		t.Errorf("Expected humidity within tolerance, got %+v", humidity)
+	}
+	if !reflect.DeepEqual(weather.Blend.Disagreements, []BlendField{FieldTemp}) || len(weather.Blend.Outliers) != 0 {
+		t.Errorf("Expected a temperature disagreement only, got %+v", weather.Blend)
+	}
+}
+
+func TestBlendConvertUnits(t *testing.T) {
+	weather := &Weather{Units: UnitsMetric.Units()}
+	weather.ApplyBlend([]BlendSource{
+		{Provider: "a", Values: map[BlendField]float64{FieldTemp: 0}},
+		{Provider: "b", Values: map[BlendField]float64{FieldTemp: 10}},
+	})
+
+	weather.ConvertUnits(UnitsImperial)
+
+	temp := weather.Blend.Fields[FieldTemp]
+	if temp.Min != 32 || temp.Max != 50 || temp.Median != 41 || temp.Spread != 18 {
+		t.Errorf("Unexpected converted temperature spread %+v", temp)
+	}
+}
@@ -3,20 +3,135 @@ package model
 import (
 	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
 	"github.com/google/uuid"
+	"strings"
+	"time"
 )
 
+const (
+	DefaultFeedbackPageSize = 20
+	MaxFeedbackPageSize     = 100
+
+	MaxFeedbackMessageLength = 1000
+	MaxFeedbackCityLength    = 100
+	MinFeedbackRating        = 1
+	MaxFeedbackRating        = 5
+	// MinReportedTemp and MaxReportedTemp bound a plausible reported temperature in °C.
+	MinReportedTemp = -90.0
+	MaxReportedTemp = 60.0
+)
+
+// ObservedCondition is the weather a user observed, coarse enough to compare with reported conditions.
+type ObservedCondition string
+
+const (
+	ObservedClear        ObservedCondition = "clear"
+	ObservedPartlyCloudy ObservedCondition = "partly_cloudy"
+	ObservedCloudy       ObservedCondition = "cloudy"
+	ObservedFog          ObservedCondition = "fog"
+	ObservedDrizzle      ObservedCondition = "drizzle"
+	ObservedRain         ObservedCondition = "rain"
+	ObservedSnow         ObservedCondition = "snow"
+	ObservedSleet        ObservedCondition = "sleet"
+	ObservedThunderstorm ObservedCondition = "thunderstorm"
+)
+
+var observedConditions = []ObservedCondition{
+	ObservedClear,
+	ObservedPartlyCloudy,
+	ObservedCloudy,
+	ObservedFog,
+	ObservedDrizzle,
+	ObservedRain,
+	ObservedSnow,
+	ObservedSleet,
+	ObservedThunderstorm,
+}
+
+// ObservedConditions lists the accepted observed conditions.
+func ObservedConditions() []ObservedCondition {
+	return append([]ObservedCondition(nil), observedConditions...)
+}
+
+// ParseObservedCondition parses an observed condition case insensitively.
+func ParseObservedCondition(s string) (ObservedCondition, bool) {
+	c := ObservedCondition(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range observedConditions {
+		if c == known {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// Feedback is a user's report on the weather in a city on a date. ReportedTemp is the
+// temperature the user measured, in °C. Subject is the authenticated user who submitted it.
 type Feedback struct {
-	ID      uuid.UUID
-	Date    string
-	City    string
-	Message string
+	ID                uuid.UUID         `json:"id"`
+	Date              string            `json:"date"`
+	City              string            `json:"city"`
+	Message           string            `json:"message"`
+	Rating            int               `json:"rating,omitempty"`
+	ObservedCondition ObservedCondition `json:"observed_condition,omitempty"`
+	ReportedTemp      *float64          `json:"reported_temp,omitempty"`
+	Subject           string            `json:"subject,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         *time.Time        `json:"updated_at,omitempty"`
 }
 
-func NewFeedbackFromDto(dto *dto.WeatherFeedbackReq) *Feedback {
+// NewFeedbackFromDto creates feedback the subject submitted from a request that has already been validated.
+func NewFeedbackFromDto(dto *dto.WeatherFeedbackReq, subject string) *Feedback {
 	return &Feedback{
-		ID:      uuid.New(),
-		Date:    dto.Date,
-		City:    dto.City,
-		Message: dto.Message,
+		ID:                uuid.New(),
+		Date:              dto.Date,
+		City:              dto.City,
+		Message:           dto.Message,
+		Rating:            dto.Rating,
+		ObservedCondition: ObservedCondition(dto.ObservedCondition),
+		ReportedTemp:      dto.ReportedTemp,
+		Subject:           subject,
+		CreatedAt:         time.Now().UTC(),
+	}
+}
+
+// Update replaces the user provided fields of the feedback and marks it as updated.
+func (f *Feedback) Update(dto *dto.WeatherFeedbackReq) {
+	now := time.Now().UTC()
+	f.Date = dto.Date
+	f.City = dto.City
+	f.Message = dto.Message
+	f.Rating = dto.Rating
+	f.ObservedCondition = ObservedCondition(dto.ObservedCondition)
+	f.ReportedTemp = dto.ReportedTemp
+	f.UpdatedAt = &now
+}
+
+// FeedbackFilter selects feedback by city and an inclusive date range, newest first.
+// Empty fields do not filter.
+type FeedbackFilter struct {
+	City   string
+	From   string
+	To     string
+	Limit  int
+	Offset int
+}
+
+// Matches reports whether the feedback passes the city and date filters, ignoring pagination.
+func (f FeedbackFilter) Matches(fb *Feedback) bool {
+	if f.City != "" && !strings.EqualFold(f.City, fb.City) {
+		return false
+	}
+	if f.From != "" && fb.Date < f.From {
+		return false
 	}
+	if f.To != "" && fb.Date > f.To {
+		return false
+	}
+	return true
+}
+
+type FeedbackPage struct {
+	Items  []*Feedback `json:"items"`
+	Total  int         `json:"total"`
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
 }
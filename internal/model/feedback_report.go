@@ -0,0 +1,313 @@
+package model
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SnapshotProviderBlend is the provider recorded for blended weather.
+const SnapshotProviderBlend = "blend"
+
+// WeatherSnapshot is the metric current weather served for a city at a local hour, by provider.
+// Only the latest snapshot of an hour is kept.
+type WeatherSnapshot struct {
+	City          string            `json:"city"`
+	Date          string            `json:"date"`
+	Hour          int               `json:"hour"`
+	Provider      string            `json:"provider"`
+	Temp          float64           `json:"temp"`
+	Condition     ObservedCondition `json:"condition"`
+	ConditionText string            `json:"condition_text"`
+	ServedAt      time.Time         `json:"served_at"`
+}
+
+// NewWeatherSnapshot snapshots metric weather. The date and hour are local to the location,
+// the date users refer to in feedback.
+func NewWeatherSnapshot(w *Weather, servedAt time.Time) WeatherSnapshot {
+	date, clock, _ := strings.Cut(w.Location.Localtime, " ")
+	hourText, _, _ := strings.Cut(clock, ":")
+	hour, err := strconv.Atoi(hourText)
+	if date == "" || err != nil {
+		date = servedAt.UTC().Format(time.DateOnly)
+		hour = servedAt.UTC().Hour()
+	}
+
+	provider := w.Meta.Provider
+	if w.Blend != nil {
+		provider = SnapshotProviderBlend
+	}
+
+	return WeatherSnapshot{
+		City:          w.Location.Name,
+		Date:          date,
+		Hour:          hour,
+		Provider:      provider,
+		Temp:          w.Current.Temp,
+		Condition:     ConditionCategory(w.Current.ConditionCode),
+		ConditionText: w.Current.Condition,
+		ServedAt:      servedAt.UTC(),
+	}
+}
+
+// ConditionCategory maps a weatherapi condition code to the observed condition it corresponds to.
+// Unknown codes map to an empty condition.
+func ConditionCategory(code int) ObservedCondition {
+	switch code {
+	case 1000:
+		return ObservedClear
+	case 1003:
+		return ObservedPartlyCloudy
+	case 1006, 1009:
+		return ObservedCloudy
+	case 1030, 1135, 1147:
+		return ObservedFog
+	case 1072, 1150, 1153, 1168, 1171:
+		return ObservedDrizzle
+	case 1063, 1180, 1183, 1186, 1189, 1192, 1195, 1198, 1201, 1240, 1243, 1246:
+		return ObservedRain
+	case 1066, 1114, 1117, 1210, 1213, 1216, 1219, 1222, 1225, 1255, 1258:
+		return ObservedSnow
+	case 1069, 1204, 1207, 1237, 1249, 1252, 1261, 1264:
+		return ObservedSleet
+	case 1087, 1273, 1276, 1279, 1282:
+		return ObservedThunderstorm
+	default:
+		return ""
+	}
+}
+
+// SnapshotFilter selects snapshots by city and an inclusive date range. Empty fields do not filter.
+type SnapshotFilter struct {
+	City string
+	From string
+	To   string
+}
+
+// Matches reports whether the snapshot passes the city and date filters.
+func (f SnapshotFilter) Matches(s WeatherSnapshot) bool {
+	if f.City != "" && !strings.EqualFold(f.City, s.City) {
+		return false
+	}
+	if f.From != "" && s.Date < f.From {
+		return false
+	}
+	if f.To != "" && s.Date > f.To {
+		return false
+	}
+	return true
+}
+
+// RatingDistribution counts feedback by rating, from 1 to 5.
+type RatingDistribution map[int]int
+
+// TempDelta describes how far reported temperatures were from served ones, in °C.
+// Mean is reported minus served, so a positive mean means we served too low temperatures.
+type TempDelta struct {
+	Samples int     `json:"samples"`
+	Mean    float64 `json:"mean"`
+	MeanAbs float64 `json:"mean_abs"`
+	MaxAbs  float64 `json:"max_abs"`
+}
+
+// ConditionAccuracy describes how often the observed condition differed from the served one.
+type ConditionAccuracy struct {
+	Samples      int     `json:"samples"`
+	Mismatches   int     `json:"mismatches"`
+	MismatchRate float64 `json:"mismatch_rate"`
+}
+
+// ProviderAccuracy compares the feedback of a city with the weather a provider served for it.
+type ProviderAccuracy struct {
+	Provider  string             `json:"provider"`
+	Feedback  int                `json:"feedback"`
+	Ratings   RatingDistribution `json:"ratings"`
+	AvgRating float64            `json:"avg_rating"`
+	Temp      TempDelta          `json:"temp"`
+	Condition ConditionAccuracy  `json:"condition"`
+}
+
+type CityReport struct {
+	City      string             `json:"city"`
+	Feedback  int                `json:"feedback"`
+	Ratings   RatingDistribution `json:"ratings"`
+	AvgRating float64            `json:"avg_rating"`
+	Providers []ProviderAccuracy `json:"providers"`
+}
+
+// FeedbackReport aggregates feedback per city and compares it with the weather served by each provider
+// on the same date, closest to the time the feedback was submitted. Feedback without a snapshot only
+// counts towards the city ratings.
+type FeedbackReport struct {
+	City   string       `json:"city,omitempty"`
+	From   string       `json:"from,omitempty"`
+	To     string       `json:"to,omitempty"`
+	Cities []CityReport `json:"cities"`
+}
+
+type accuracyAcc struct {
+	feedback     int
+	ratings      RatingDistribution
+	ratingSum    int
+	ratingCount  int
+	tempSamples  int
+	tempSum      float64
+	tempAbsSum   float64
+	tempMaxAbs   float64
+	condSamples  int
+	condMismatch int
+}
+
+func newAccuracyAcc() *accuracyAcc {
+	ratings := make(RatingDistribution, MaxFeedbackRating)
+	for r := MinFeedbackRating; r <= MaxFeedbackRating; r++ {
+		ratings[r] = 0
+	}
+	return &accuracyAcc{ratings: ratings}
+}
+
+func (a *accuracyAcc) addRating(fb *Feedback) {
+	a.feedback++
+	// Rating is optional, and feedback submitted before ratings were introduced has none.
+	if fb.Rating < MinFeedbackRating || fb.Rating > MaxFeedbackRating {
+		return
+	}
+	a.ratings[fb.Rating]++
+	a.ratingSum += fb.Rating
+	a.ratingCount++
+}
+
+func (a *accuracyAcc) addSnapshot(fb *Feedback, s WeatherSnapshot) {
+	if fb.ReportedTemp != nil {
+		delta := *fb.ReportedTemp - s.Temp
+		a.tempSamples++
+		a.tempSum += delta
+		a.tempAbsSum += math.Abs(delta)
+		a.tempMaxAbs = math.Max(a.tempMaxAbs, math.Abs(delta))
+	}
+	if fb.ObservedCondition != "" && s.Condition != "" {
+		a.condSamples++
+		if fb.ObservedCondition != s.Condition {
+			a.condMismatch++
+		}
+	}
+}
+
+func (a *accuracyAcc) avgRating() float64 {
+	if a.ratingCount == 0 {
+		return 0
+	}
+	return round(float64(a.ratingSum)/float64(a.ratingCount), 2)
+}
+
+func (a *accuracyAcc) provider(name string) ProviderAccuracy {
+	accuracy := ProviderAccuracy{
+		Provider:  name,
+		Feedback:  a.feedback,
+		Ratings:   a.ratings,
+		AvgRating: a.avgRating(),
+		Condition: ConditionAccuracy{Samples: a.condSamples, Mismatches: a.condMismatch},
+	}
+	if a.tempSamples > 0 {
+		accuracy.Temp = TempDelta{
+			Samples: a.tempSamples,
+			Mean:    round(a.tempSum/float64(a.tempSamples), 2),
+			MeanAbs: round(a.tempAbsSum/float64(a.tempSamples), 2),
+			MaxAbs:  round(a.tempMaxAbs, 2),
+		}
+	}
+	if a.condSamples > 0 {
+		accuracy.Condition.MismatchRate = round(float64(a.condMismatch)/float64(a.condSamples), 3)
+	}
+	return accuracy
+}
+
+type cityAcc struct {
+	name      string
+	total     *accuracyAcc
+	providers map[string]*accuracyAcc
+}
+
+// NewFeedbackReport matches feedback with the snapshots of the same city and date, one per provider
+// served closest to the feedback. Cities are grouped case insensitively and sorted by name, providers
+// are sorted by name.
+func NewFeedbackReport(filter FeedbackFilter, feedbacks []*Feedback, snapshots []WeatherSnapshot) *FeedbackReport {
+	snapshotsByDay := make(map[string][]WeatherSnapshot)
+	for _, s := range snapshots {
+		key := reportKey(s.City, s.Date)
+		snapshotsByDay[key] = append(snapshotsByDay[key], s)
+	}
+
+	cities := make(map[string]*cityAcc)
+	for _, fb := range feedbacks {
+		cityKey := strings.ToLower(fb.City)
+		city, ok := cities[cityKey]
+		if !ok {
+			city = &cityAcc{name: fb.City, total: newAccuracyAcc(), providers: make(map[string]*accuracyAcc)}
+			cities[cityKey] = city
+		}
+		city.total.addRating(fb)
+
+		for _, s := range closestSnapshots(snapshotsByDay[reportKey(fb.City, fb.Date)], fb.CreatedAt) {
+			acc, ok := city.providers[s.Provider]
+			if !ok {
+				acc = newAccuracyAcc()
+				city.providers[s.Provider] = acc
+			}
+			acc.addRating(fb)
+			acc.addSnapshot(fb, s)
+		}
+	}
+
+	report := &FeedbackReport{
+		City:   filter.City,
+		From:   filter.From,
+		To:     filter.To,
+		Cities: make([]CityReport, 0, len(cities)),
+	}
+	for _, city := range cities {
+		cityReport := CityReport{
+			City:      city.name,
+			Feedback:  city.total.feedback,
+			Ratings:   city.total.ratings,
+			AvgRating: city.total.avgRating(),
+			Providers: make([]ProviderAccuracy, 0, len(city.providers)),
+		}
+		for name, acc := range city.providers {
+			cityReport.Providers = append(cityReport.Providers, acc.provider(name))
+		}
+		sort.Slice(cityReport.Providers, func(i, j int) bool {
+			return cityReport.Providers[i].Provider < cityReport.Providers[j].Provider
+		})
+		report.Cities = append(report.Cities, cityReport)
+	}
+	sort.Slice(report.Cities, func(i, j int) bool {
+		return strings.ToLower(report.Cities[i].City) < strings.ToLower(report.Cities[j].City)
+	})
+	return report
+}
+
+// closestSnapshots picks the snapshot of each provider served closest to t. Feedback about a past day
+// is compared with the last weather served that day.
+func closestSnapshots(snapshots []WeatherSnapshot, t time.Time) []WeatherSnapshot {
+	var closest []WeatherSnapshot
+	for _, s := range snapshots {
+		i := slices.IndexFunc(closest, func(c WeatherSnapshot) bool {
+			return c.Provider == s.Provider
+		})
+		switch {
+		case i < 0:
+			closest = append(closest, s)
+		case s.ServedAt.Sub(t).Abs() < closest[i].ServedAt.Sub(t).Abs():
+			closest[i] = s
+		}
+	}
+	return closest
+}
+
+func reportKey(city, date string) string {
+	return strings.ToLower(city) + "|" + date
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewWeatherSnapshot(t *testing.T) {
+	served := time.Date(2024, 10, 27, 23, 30, 0, 0, time.UTC)
+	weather := &Weather{
+		Meta:     Meta{Provider: "weatherapi"},
+		Location: Location{Name: "Tokyo", Localtime: "2024-10-28 08:30"},
+		Current:  Current{Temp: 15.2, Condition: "Patchy rain nearby", ConditionCode: 1063},
+	}
+
+	snapshot := NewWeatherSnapshot(weather, served)
+
+	if snapshot.Date != "2024-10-28" || snapshot.Hour != 8 {
+		t.Errorf("Expected the local date 2024-10-28 at hour 8, got %s at %d", snapshot.Date, snapshot.Hour)
+	}
+	if snapshot.Provider != "weatherapi" || snapshot.Temp != 15.2 || snapshot.Condition != ObservedRain {
+		t.Errorf("Unexpected snapshot %+v", snapshot)
+	}
+
+	weather.Blend = &Blend{}
+	if snapshot := NewWeatherSnapshot(weather, served); snapshot.Provider != SnapshotProviderBlend {
+		t.Errorf("Expected blended weather to be recorded as %s, got %s", SnapshotProviderBlend, snapshot.Provider)
+	}
+}
+
+func TestNewFeedbackReport(t *testing.T) {
+	temp := func(v float64) *float64 {
+		return &v
+	}
+	feedbacks := []*Feedback{
+		{City: "London", Date: "2024-10-27", Rating: 2, ObservedCondition: ObservedRain, ReportedTemp: temp(8)},
+		{City: "london", Date: "2024-10-27", Rating: 4, ObservedCondition: ObservedCloudy, ReportedTemp: temp(13)},
+		{City: "London", Date: "2024-10-20", Rating: 5},
+		{City: "Paris", Date: "2024-10-27", Rating: 0, ObservedCondition: ObservedClear},
+	}
+	snapshots := []WeatherSnapshot{
+		{City: "London", Date: "2024-10-27", Provider: "weatherapi", Temp: 10, Condition: ObservedRain},
+		{City: "London", Date: "2024-10-27", Provider: "openweather", Temp: 12, Condition: ObservedCloudy},
+		{City: "Paris", Date: "2024-10-26", Provider: "weatherapi", Temp: 15, Condition: ObservedClear},
+	}
+
+	report := NewFeedbackReport(FeedbackFilter{From: "2024-10-01"}, feedbacks, snapshots)
+
+	if report.From != "2024-10-01" || len(report.Cities) != 2 {
+		t.Fatalf("Expected 2 cities, got %+v", report)
+	}
+
+	london := report.Cities[0]
+	if london.City != "London" || london.Feedback != 3 || london.AvgRating != 3.67 {
+		t.Errorf("Unexpected London report %+v", london)
+	}
+	if london.Ratings[2] != 1 || london.Ratings[4] != 1 || london.Ratings[5] != 1 || london.Ratings[1] != 0 {
+		t.Errorf("Unexpected London ratings %v", london.Ratings)
+	}
+	if len(london.Providers) != 2 {
+		t.Fatalf("Expected 2 providers for London, got %+v", london.Providers)
+	}
+
+	ow, wa := london.Providers[0], london.Providers[1]
+	if ow.Provider != "openweather" || wa.Provider != "weatherapi" {
+		t.Fatalf("Expected providers sorted by name, got %s, %s", ow.Provider, wa.Provider)
+	}
+	if wa.Feedback != 2 || wa.AvgRating != 3 {
+		t.Errorf("Unexpected weatherapi ratings %+v", wa)
+	}
+	if wa.Temp != (TempDelta{Samples: 2, Mean: 0.5, MeanAbs: 2.5, MaxAbs: 3}) {
+		t.Errorf("Unexpected weatherapi temperature delta %+v", wa.Temp)
+	}
+	if wa.Condition != (ConditionAccuracy{Samples: 2, Mismatches: 1, MismatchRate: 0.5}) {
+		t.Errorf("Unexpected weatherapi condition accuracy %+v", wa.Condition)
+	}
+	if ow.Temp.Mean != -1.5 || ow.Condition.Mismatches != 1 {
+		t.Errorf("Unexpected openweather accuracy %+v", ow)
+	}
+
+	paris := report.Cities[1]
+	if paris.Feedback != 1 || paris.AvgRating != 0 || len(paris.Providers) != 0 {
+		t.Errorf("Expected unrated Paris feedback without snapshots, got %+v", paris)
+	}
+}
+
+func TestNewFeedbackReport_ComparesClosestSnapshot(t *testing.T) {
+	morning := time.Date(2024, 10, 27, 8, 0, 0, 0, time.UTC)
+	temp := 6.0
+	feedbacks := []*Feedback{
+		{City: "London", Date: "2024-10-27", Rating: 3, ReportedTemp: &temp, CreatedAt: morning.Add(30 * time.Minute)},
+	}
+	snapshots := []WeatherSnapshot{
+		{City: "London", Date: "2024-10-27", Hour: 8, Provider: "weatherapi", Temp: 5, ServedAt: morning},
+		{City: "London", Date: "2024-10-27", Hour: 15, Provider: "weatherapi", Temp: 14, ServedAt: morning.Add(7 * time.Hour)},
+	}
+
+	report := NewFeedbackReport(FeedbackFilter{}, feedbacks, snapshots)
+
+	if len(report.Cities) != 1 || len(report.Cities[0].Providers) != 1 {
+		t.Fatalf("Expected a London report with one provider, got %+v", report)
+	}
+	if delta := report.Cities[0].Providers[0].Temp; delta.Samples != 1 || delta.Mean != 1 {
+		t.Errorf("Expected feedback compared with the morning snapshot, got %+v", delta)
+	}
+}
+
+func TestConditionCategory(t *testing.T) {
+	tests := map[int]ObservedCondition{
+		1000: ObservedClear,
+		1009: ObservedCloudy,
+		1153: ObservedDrizzle,
+		1195: ObservedRain,
+		1204: ObservedSleet,
+		1225: ObservedSnow,
+		1276: ObservedThunderstorm,
+		42:   "",
+	}
+	for code, want := range tests {
+		if got := ConditionCategory(code); got != want {
+			t.Errorf("ConditionCategory(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
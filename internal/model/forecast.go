@@ -0,0 +1,178 @@
+package model
+
+import (
+	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/util"
+)
+
+// Hour holds an hourly forecast. Like Current, the metric fields keep the names of the v1
+// contract and the unit-neutral fields are measured in the units of the response.
+type Hour struct {
+	Time         string  `json:"time"`
+	TempC        float64 `json:"temp_c"`
+	Condition    string  `json:"condition"`
+	WindKph      float64 `json:"wind_kph"`
+	WindDegree   int     `json:"wind_degree"`
+	WindDir      string  `json:"wind_dir"`
+	PressureMb   float64 `json:"pressure_mb"`
+	PrecipMm     float64 `json:"precip_mm"`
+	Humidity     int     `json:"humidity"`
+	Cloud        int     `json:"cloud"`
+	FeelslikeC   float64 `json:"feelslike_c"`
+	ChanceOfRain int     `json:"chance_of_rain"`
+	ChanceOfSnow int     `json:"chance_of_snow"`
+	VisKm        float64 `json:"vis_km"`
+	Uv           float64 `json:"uv"`
+
+	Temp       float64 `json:"temp"`
+	WindSpeed  float64 `json:"wind_speed"`
+	Pressure   float64 `json:"pressure"`
+	Precip     float64 `json:"precip"`
+	FeelsLike  float64 `json:"feelslike"`
+	Visibility float64 `json:"vis"`
+}
+
+// Day holds a daily forecast or recorded day, with metric and unit-neutral fields like Hour.
+type Day struct {
+	Date          string  `json:"date"`
+	MaxTempC      float64 `json:"max_temp_c"`
+	MinTempC      float64 `json:"min_temp_c"`
+	AvgTempC      float64 `json:"avg_temp_c"`
+	Condition     string  `json:"condition"`
+	MaxWindKph    float64 `json:"max_wind_kph"`
+	TotalPrecipMm float64 `json:"total_precip_mm"`
+	AvgHumidity   int     `json:"avg_humidity"`
+	AvgVisKm      float64 `json:"avg_vis_km"`
+	Cloud         int     `json:"cloud"`
+	ChanceOfRain  int     `json:"chance_of_rain"`
+	ChanceOfSnow  int     `json:"chance_of_snow"`
+	Uv            float64 `json:"uv"`
+	Sunrise       string  `json:"sunrise"`
+	Sunset        string  `json:"sunset"`
+	Hours         []Hour  `json:"hours"`
+
+	MaxTemp       float64 `json:"max_temp"`
+	MinTemp       float64 `json:"min_temp"`
+	AvgTemp       float64 `json:"avg_temp"`
+	MaxWindSpeed  float64 `json:"max_wind_speed"`
+	TotalPrecip   float64 `json:"total_precip"`
+	AvgVisibility float64 `json:"avg_vis"`
+}
+
+type Forecast struct {
+	Location `json:"location"`
+	Units    Units `json:"units"`
+	Astro    `json:"astro"`
+	Days     []Day `json:"days"`
+}
+
+// NewForecastFromDto builds daily and hourly forecast from weatherapi data. OpenWeather data
+// provides the timezone offset, today's sunrise/sunset and the daily cloud cover average,
+// which weatherapi does not report per day. Without OpenWeather data those are left empty.
+func NewForecastFromDto(forecastDto *dto.ForecastByCity, astroDto *dto.AstroForecastByCity) *Forecast {
+	var (
+		tzOffset  int
+		astroData Astro
+		clouds    map[string]int
+	)
+	if astroDto != nil {
+		tzOffset = astroDto.City.Timezone
+		astroData = Astro{
+			Sunrise: util.UnixToLocal(int64(astroDto.City.Sunrise), tzOffset),
+			Sunset:  util.UnixToLocal(int64(astroDto.City.Sunset), tzOffset),
+		}
+		clouds = dailyCloudAverage(astroDto)
+	}
+
+	location := newLocationFromDto(forecastDto.Location, tzOffset)
+
+	days := make([]Day, 0, len(forecastDto.Forecast.ForecastDay))
+	for _, fd := range forecastDto.Forecast.ForecastDay {
+		day := newDayFromDto(fd)
+		if cloud, ok := clouds[fd.Date]; ok {
+			day.Cloud = cloud
+		}
+		days = append(days, day)
+	}
+
+	return &Forecast{
+		Location: location,
+		Units:    UnitsMetric.Units(),
+		Astro:    astroData,
+		Days:     days,
+	}
+}
+
+func newDayFromDto(fd dto.ForecastDay) Day {
+	hours := make([]Hour, 0, len(fd.Hour))
+	for _, h := range fd.Hour {
+		hours = append(hours, newHourFromDto(h))
+	}
+
+	return Day{
+		Date:          fd.Date,
+		MaxTempC:      fd.Day.MaxtempC,
+		MinTempC:      fd.Day.MintempC,
+		AvgTempC:      fd.Day.AvgtempC,
+		Condition:     fd.Day.Condition.Text,
+		MaxWindKph:    fd.Day.MaxwindKph,
+		TotalPrecipMm: fd.Day.TotalprecipMm,
+		AvgHumidity:   int(fd.Day.Avghumidity),
+		AvgVisKm:      fd.Day.AvgvisKm,
+		MaxTemp:       fd.Day.MaxtempC,
+		MinTemp:       fd.Day.MintempC,
+		AvgTemp:       fd.Day.AvgtempC,
+		MaxWindSpeed:  fd.Day.MaxwindKph,
+		TotalPrecip:   fd.Day.TotalprecipMm,
+		AvgVisibility: fd.Day.AvgvisKm,
+		ChanceOfRain:  fd.Day.DailyChanceOfRain,
+		ChanceOfSnow:  fd.Day.DailyChanceOfSnow,
+		Uv:            fd.Day.Uv,
+		Sunrise:       fd.Astro.Sunrise,
+		Sunset:        fd.Astro.Sunset,
+		Hours:         hours,
+	}
+}
+
+func newHourFromDto(h dto.ForecastHour) Hour {
+	return Hour{
+		Time:         h.Time,
+		TempC:        h.TempC,
+		Condition:    h.Condition.Text,
+		WindKph:      h.WindKph,
+		WindDegree:   h.WindDegree,
+		WindDir:      h.WindDir,
+		PressureMb:   h.PressureMb,
+		PrecipMm:     h.PrecipMm,
+		Humidity:     h.Humidity,
+		Cloud:        h.Cloud,
+		FeelslikeC:   h.FeelslikeC,
+		ChanceOfRain: h.ChanceOfRain,
+		ChanceOfSnow: h.ChanceOfSnow,
+		VisKm:        h.VisKm,
+		Uv:           h.Uv,
+		Temp:         h.TempC,
+		WindSpeed:    h.WindKph,
+		Pressure:     h.PressureMb,
+		Precip:       h.PrecipMm,
+		FeelsLike:    h.FeelslikeC,
+		Visibility:   h.VisKm,
+	}
+}
+
+// dailyCloudAverage averages OpenWeather 3-hour cloud cover by local date.
+func dailyCloudAverage(astroDto *dto.AstroForecastByCity) map[string]int {
+	sums := make(map[string]int)
+	counts := make(map[string]int)
+	for _, item := range astroDto.List {
+		date := util.UnixToLocalDate(int64(item.Dt), astroDto.City.Timezone)
+		sums[date] += item.Clouds.All
+		counts[date]++
+	}
+
+	averages := make(map[string]int, len(sums))
+	for date, sum := range sums {
+		averages[date] = sum / counts[date]
+	}
+	return averages
+}
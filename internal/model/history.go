@@ -0,0 +1,47 @@
+package model
+
+import (
+	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"sort"
+)
+
+type WeatherHistory struct {
+	Location `json:"location"`
+	Units    Units `json:"units"`
+	Days     []Day `json:"days"`
+}
+
+func NewWeatherHistoryFromDto(historyDtos []*dto.HistoryByCity) *WeatherHistory {
+	history := &WeatherHistory{Units: UnitsMetric.Units()}
+	for _, h := range historyDtos {
+		history.Location = newLocationFromDto(h.Location, 0)
+		for _, fd := range h.Forecast.ForecastDay {
+			history.Days = append(history.Days, newDayFromDto(fd))
+		}
+	}
+	history.SortDays()
+	return history
+}
+
+// Merge appends days of another history, keeping the days sorted by date.
+func (h *WeatherHistory) Merge(other *WeatherHistory) {
+	if h.Location.Name == "" {
+		h.Location = other.Location
+	}
+	h.Days = append(h.Days, other.Days...)
+	h.SortDays()
+}
+
+func (h *WeatherHistory) SortDays() {
+	sort.Slice(h.Days, func(i, j int) bool {
+		return h.Days[i].Date < h.Days[j].Date
+	})
+}
+
+// LocalDate returns the current date at the location, as reported by the provider.
+func (l Location) LocalDate() string {
+	if len(l.Localtime) < len("2006-01-02") {
+		return ""
+	}
+	return l.Localtime[:len("2006-01-02")]
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Language is a weatherapi language code used to localize condition text.
+// The zero value stands for English, the provider default.
+type Language string
+
+// supportedLanguages maps lowercase BCP 47 tags to weatherapi language codes.
+var supportedLanguages = map[string]Language{
+	"ar": "ar", "bn": "bn", "bg": "bg", "zh": "zh", "zh-tw": "zh_tw", "zh-hant": "zh_tw",
+	"cs": "cs", "da": "da", "nl": "nl", "fi": "fi", "fr": "fr", "de": "de", "el": "el",
+	"hi": "hi", "hu": "hu", "it": "it", "ja": "ja", "jv": "jv", "ko": "ko", "mr": "mr",
+	"pl": "pl", "pt": "pt", "pa": "pa", "ro": "ro", "ru": "ru", "sr": "sr", "si": "si",
+	"sk": "sk", "es": "es", "sv": "sv", "ta": "ta", "te": "te", "tr": "tr", "uk": "uk",
+	"ur": "ur", "vi": "vi", "zu": "zu", "en": "",
+}
+
+// ParseAcceptLanguage picks the preferred supported language from an Accept-Language header.
+// It reports false when none of the requested languages is supported.
+func ParseAcceptLanguage(header string) (Language, bool) {
+	type candidate struct {
+		tag     string
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if tag == "" || tag == "*" || quality <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{tag: strings.ToLower(tag), quality: quality})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	for _, c := range candidates {
+		if lang, ok := matchLanguage(c.tag); ok {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// matchLanguage matches a tag, falling back to shorter prefixes, e.g. zh-hant-tw, zh-hant, zh.
+func matchLanguage(tag string) (Language, bool) {
+	for {
+		if lang, ok := supportedLanguages[tag]; ok {
+			return lang, true
+		}
+		i := strings.LastIndex(tag, "-")
+		if i < 0 {
+			return "", false
+		}
+		tag = tag[:i]
+	}
+}
+
+// Tag returns the BCP 47 tag of the language, suitable for the Content-Language header.
+func (l Language) Tag() string {
+	if l == "" {
+		return "en"
+	}
+	return strings.ReplaceAll(string(l), "_", "-")
+}
+
+// Key returns a cache key suffix, empty for the default language.
+func (l Language) Key() string {
+	if l == "" {
+		return ""
+	}
+	return ":" + string(l)
+}
@@ -0,0 +1,36 @@
+package model
+
+import "testing"
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected Language
+		matched  bool
+	}{
+		{"", "", false},
+		{"fr-CH, fr;q=0.9, en;q=0.8", "fr", true},
+		{"en-US,en;q=0.9", "", true},
+		{"xx, de;q=0.5", "de", true},
+		{"es;q=0.2, it;q=0.7", "it", true},
+		{"zh-Hant-TW", "zh_tw", true},
+		{"sr-Latn-RS;q=0", "", false},
+		{"*", "", false},
+	}
+
+	for _, tt := range tests {
+		lang, ok := ParseAcceptLanguage(tt.header)
+		if lang != tt.expected || ok != tt.matched {
+			t.Errorf("ParseAcceptLanguage(%q) = %q, %v; want %q, %v", tt.header, lang, ok, tt.expected, tt.matched)
+		}
+	}
+}
+
+func TestLanguageTag(t *testing.T) {
+	if tag := Language("").Tag(); tag != "en" {
+		t.Errorf("Expected en for default language, got %q", tag)
+	}
+	if tag := Language("zh_tw").Tag(); tag != "zh-tw" {
+		t.Errorf("Expected zh-tw, got %q", tag)
+	}
+}
@@ -0,0 +1,23 @@
+package model
+
+import "github.com/DjordjeVuckovic/weather-radar/internal/dto"
+
+// LocationCandidate is a location matching a search. Its timezone comes with the weather of the
+// location, requested by its coordinates.
+type LocationCandidate struct {
+	Name    string  `json:"name"`
+	Region  string  `json:"region"`
+	Country string  `json:"country"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+}
+
+func NewLocationCandidateFromDto(locationDto dto.SearchLocation) LocationCandidate {
+	return LocationCandidate{
+		Name:    locationDto.Name,
+		Region:  locationDto.Region,
+		Country: locationDto.Country,
+		Lat:     locationDto.Lat,
+		Lon:     locationDto.Lon,
+	}
+}
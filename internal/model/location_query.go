@@ -0,0 +1,92 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+type LocationKind string
+
+const (
+	LocationCity        LocationKind = "city"
+	LocationCoordinates LocationKind = "coordinates"
+	LocationPostcode    LocationKind = "postcode"
+	LocationIP          LocationKind = "ip"
+)
+
+// coordinatesPrecision is the number of decimals kept for coordinates, roughly 11 meters.
+const coordinatesPrecision = 4
+
+// locationKeyPrecision is the number of decimals of the coordinates in a location key, roughly
+// 11 km. Providers place the same city a few hundred meters apart.
+const locationKeyPrecision = 1
+
+// LocationQuery describes a location the way a client asked for it.
+type LocationQuery struct {
+	Kind     LocationKind
+	City     string
+	Lat      float64
+	Lon      float64
+	Postcode string
+	IP       string
+}
+
+func NewCityQuery(city string) LocationQuery {
+	return LocationQuery{Kind: LocationCity, City: strings.Join(strings.Fields(city), " ")}
+}
+
+func NewCoordinatesQuery(lat, lon float64) LocationQuery {
+	return LocationQuery{Kind: LocationCoordinates, Lat: lat, Lon: lon}
+}
+
+func NewPostcodeQuery(postcode string) LocationQuery {
+	return LocationQuery{Kind: LocationPostcode, Postcode: strings.ToUpper(strings.Join(strings.Fields(postcode), " "))}
+}
+
+func NewIPQuery(ip string) LocationQuery {
+	return LocationQuery{Kind: LocationIP, IP: ip}
+}
+
+// String returns the query in the `q` format understood by weatherapi.
+func (q LocationQuery) String() string {
+	switch q.Kind {
+	case LocationCoordinates:
+		return formatCoordinate(q.Lat) + "," + formatCoordinate(q.Lon)
+	case LocationPostcode:
+		return q.Postcode
+	case LocationIP:
+		if q.IP == "" {
+			return "auto:ip"
+		}
+		return q.IP
+	default:
+		return q.City
+	}
+}
+
+// Key returns a normalized representation of the query, suitable for cache keys.
+func (q LocationQuery) Key() string {
+	switch q.Kind {
+	case LocationCoordinates:
+		return "coord:" + q.String()
+	case LocationPostcode:
+		return "postcode:" + strings.ReplaceAll(q.Postcode, " ", "")
+	case LocationIP:
+		return "ip:" + q.String()
+	default:
+		return "city:" + strings.ToLower(q.City)
+	}
+}
+
+// Key identifies the location a query was resolved to, so that the same place
+// requested by name, coordinates or postcode shares one cache entry. It uses the name and
+// the rounded coordinates, as providers name regions and countries differently.
+func (l Location) Key() string {
+	return strings.ToLower(l.Name) + "|" +
+		strconv.FormatFloat(l.Lat, 'f', locationKeyPrecision, 64) + "," +
+		strconv.FormatFloat(l.Lon, 'f', locationKeyPrecision, 64)
+}
+
+func formatCoordinate(c float64) string {
+	return strconv.FormatFloat(c, 'f', coordinatesPrecision, 64)
+}
@@ -0,0 +1,62 @@
+package model
+
+import "testing"
+
+func TestLocationQueryString(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    LocationQuery
+		expected string
+	}{
+		{"City", NewCityQuery("  New   York "), "New York"},
+		{"Coordinates", NewCoordinatesQuery(48.856613, 2.352222), "48.8566,2.3522"},
+		{"Postcode", NewPostcodeQuery("sw1a  1aa"), "SW1A 1AA"},
+		{"IP", NewIPQuery("100.0.0.1"), "100.0.0.1"},
+		{"Auto IP", NewIPQuery(""), "auto:ip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLocationQueryKey(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b LocationQuery
+	}{
+		{"City case and spaces", NewCityQuery("new york"), NewCityQuery(" New  York")},
+		{"Coordinates precision", NewCoordinatesQuery(48.85661, 2.35222), NewCoordinatesQuery(48.856613, 2.352224)},
+		{"Postcode spacing", NewPostcodeQuery("sw1a1aa"), NewPostcodeQuery("SW1A 1AA")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.a.Key() != tt.b.Key() {
+				t.Errorf("Expected equal keys, got %q and %q", tt.a.Key(), tt.b.Key())
+			}
+		})
+	}
+
+	if NewCityQuery("Paris").Key() == NewPostcodeQuery("Paris").Key() {
+		t.Error("Expected different keys for different query kinds")
+	}
+}
+
+func TestLocationKey(t *testing.T) {
+	weatherApi := Location{Name: "London", Region: "City of London, Greater London", Country: "United Kingdom", Lat: 51.52, Lon: -0.11}
+	openWeather := Location{Name: "London", Country: "GB", Lat: 51.5085, Lon: -0.1257}
+	if weatherApi.Key() != openWeather.Key() {
+		t.Errorf("Expected providers to share the key of London, got %q and %q", weatherApi.Key(), openWeather.Key())
+	}
+
+	springfieldIL := Location{Name: "Springfield", Region: "Illinois", Country: "United States of America", Lat: 39.8, Lon: -89.64}
+	springfieldMO := Location{Name: "Springfield", Region: "Missouri", Country: "United States of America", Lat: 37.22, Lon: -93.3}
+	if springfieldIL.Key() == springfieldMO.Key() {
+		t.Error("Expected different keys for places of the same name")
+	}
+}
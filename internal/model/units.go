@@ -0,0 +1,201 @@
+package model
+
+import (
+	"math"
+	"strings"
+)
+
+type UnitSystem string
+
+const (
+	UnitsMetric     UnitSystem = "metric"
+	UnitsImperial   UnitSystem = "imperial"
+	UnitsScientific UnitSystem = "scientific"
+)
+
+// Units describes the unit of every measured quantity in a response.
+type Units struct {
+	System        UnitSystem `json:"system"`
+	Temperature   string     `json:"temperature"`
+	WindSpeed     string     `json:"wind_speed"`
+	Pressure      string     `json:"pressure"`
+	Precipitation string     `json:"precipitation"`
+	Visibility    string     `json:"visibility"`
+}
+
+// ParseUnits parses the units query param, an empty value selects metric units.
+func ParseUnits(units string) (UnitSystem, bool) {
+	switch u := UnitSystem(strings.ToLower(strings.TrimSpace(units))); u {
+	case "":
+		return UnitsMetric, true
+	case UnitsMetric, UnitsImperial, UnitsScientific:
+		return u, true
+	default:
+		return "", false
+	}
+}
+
+func (u UnitSystem) Units() Units {
+	switch u {
+	case UnitsImperial:
+		return Units{System: u, Temperature: "°F", WindSpeed: "mph", Pressure: "inHg", Precipitation: "in", Visibility: "mi"}
+	case UnitsScientific:
+		return Units{System: u, Temperature: "K", WindSpeed: "m/s", Pressure: "hPa", Precipitation: "mm", Visibility: "m"}
+	default:
+		return Units{System: UnitsMetric, Temperature: "°C", WindSpeed: "km/h", Pressure: "hPa", Precipitation: "mm", Visibility: "km"}
+	}
+}
+
+// unitConverter converts metric values, as stored and cached, to the target unit system.
+type unitConverter struct {
+	system UnitSystem
+}
+
+func (c unitConverter) temp(celsius float64) float64 {
+	switch c.system {
+	case UnitsImperial:
+		return round(celsius*9/5+32, 1)
+	case UnitsScientific:
+		return round(celsius+273.15, 2)
+	default:
+		return celsius
+	}
+}
+
+func (c unitConverter) windSpeed(kph float64) float64 {
+	switch c.system {
+	case UnitsImperial:
+		return round(kph/1.609344, 1)
+	case UnitsScientific:
+		return round(kph/3.6, 1)
+	default:
+		return kph
+	}
+}
+
+func (c unitConverter) pressure(mb float64) float64 {
+	if c.system == UnitsImperial {
+		return round(mb*0.02953, 2)
+	}
+	return mb
+}
+
+func (c unitConverter) precip(mm float64) float64 {
+	if c.system == UnitsImperial {
+		return round(mm/25.4, 2)
+	}
+	return mm
+}
+
+func (c unitConverter) visibility(km float64) float64 {
+	switch c.system {
+	case UnitsImperial:
+		return round(km/1.609344, 1)
+	case UnitsScientific:
+		return km * 1000
+	default:
+		return km
+	}
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+// ConvertUnits converts the unit-neutral weather values to the given unit system. The metric
+// fields stay metric.
+func (w *Weather) ConvertUnits(system UnitSystem) {
+	if !convertible(w.Units, system) {
+		return
+	}
+	c := unitConverter{system: system}
+	w.Current.Temp = c.temp(w.Current.Temp)
+	w.Current.FeelsLike = c.temp(w.Current.FeelsLike)
+	if w.Current.HeatIndex != nil {
+		heatIndex := c.temp(*w.Current.HeatIndex)
+		w.Current.HeatIndex = &heatIndex
+	}
+	w.Current.WindSpeed = c.windSpeed(w.Current.WindSpeed)
+	w.Current.Pressure = c.pressure(w.Current.Pressure)
+	w.Current.Precip = c.precip(w.Current.Precip)
+	w.Current.Visibility = c.visibility(w.Current.Visibility)
+	if w.Blend != nil {
+		w.Blend.convertUnits(c)
+	}
+	w.Units = system.Units()
+}
+
+func (b *Blend) convertUnits(c unitConverter) {
+	for field, spread := range b.Fields {
+		var convert func(float64) float64
+		switch field {
+		case FieldTemp, FieldFeelsLike:
+			convert = c.temp
+		case FieldWindSpeed:
+			convert = c.windSpeed
+		case FieldPressure:
+			convert = c.pressure
+		case FieldPrecip:
+			convert = c.precip
+		case FieldVisibility:
+			convert = c.visibility
+		default:
+			continue
+		}
+		spread.Median = convert(spread.Median)
+		spread.Mean = convert(spread.Mean)
+		spread.Min = convert(spread.Min)
+		spread.Max = convert(spread.Max)
+		spread.Spread = round(spread.Max-spread.Min, 2)
+		b.Fields[field] = spread
+	}
+}
+
+// ConvertUnits converts the metric forecast values to the given unit system.
+func (f *Forecast) ConvertUnits(system UnitSystem) {
+	if !convertible(f.Units, system) {
+		return
+	}
+	convertDays(f.Days, unitConverter{system: system})
+	f.Units = system.Units()
+}
+
+// ConvertUnits converts the metric history values to the given unit system.
+func (h *WeatherHistory) ConvertUnits(system UnitSystem) {
+	if !convertible(h.Units, system) {
+		return
+	}
+	convertDays(h.Days, unitConverter{system: system})
+	h.Units = system.Units()
+}
+
+// convertible reports whether values described by current are metric and need converting.
+// An empty system stands for metric values, e.g. for responses assembled from cached days.
+func convertible(current Units, target UnitSystem) bool {
+	if current.System != "" && current.System != UnitsMetric {
+		return false
+	}
+	return current.System != target
+}
+
+func convertDays(days []Day, c unitConverter) {
+	for i := range days {
+		d := &days[i]
+		d.MaxTemp = c.temp(d.MaxTemp)
+		d.MinTemp = c.temp(d.MinTemp)
+		d.AvgTemp = c.temp(d.AvgTemp)
+		d.MaxWindSpeed = c.windSpeed(d.MaxWindSpeed)
+		d.TotalPrecip = c.precip(d.TotalPrecip)
+		d.AvgVisibility = c.visibility(d.AvgVisibility)
+		for j := range d.Hours {
+			h := &d.Hours[j]
+			h.Temp = c.temp(h.Temp)
+			h.FeelsLike = c.temp(h.FeelsLike)
+			h.WindSpeed = c.windSpeed(h.WindSpeed)
+			h.Pressure = c.pressure(h.Pressure)
+			h.Precip = c.precip(h.Precip)
+			h.Visibility = c.visibility(h.Visibility)
+		}
+	}
+}
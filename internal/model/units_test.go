@@ -0,0 +1,134 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"testing"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		units    string
+		expected UnitSystem
+		valid    bool
+	}{
+		{"", UnitsMetric, true},
+		{"Imperial", UnitsImperial, true},
+		{"scientific", UnitsScientific, true},
+		{"kelvin", "", false},
+	}
+
+	for _, tt := range tests {
+		units, ok := ParseUnits(tt.units)
+		if ok != tt.valid || units != tt.expected {
+			t.Errorf("ParseUnits(%q) = %q, %v; want %q, %v", tt.units, units, ok, tt.expected, tt.valid)
+		}
+	}
+}
+
+func TestWeatherConvertUnits(t *testing.T) {
+	newWeather := func() *Weather {
+		return &Weather{
+			Units: UnitsMetric.Units(),
+			Current: Current{
+				Temp:       20,
+				WindSpeed:  36,
+				Pressure:   1013,
+				Precip:     25.4,
+				Visibility: 10,
+			},
+		}
+	}
+
+	imperial := newWeather()
+	imperial.ConvertUnits(UnitsImperial)
+	if imperial.Current.Temp != 68 || imperial.Current.WindSpeed != 22.4 || imperial.Current.Pressure != 29.91 ||
+		imperial.Current.Precip != 1 || imperial.Current.Visibility != 6.2 {
+		t.Errorf("Unexpected imperial values: %+v", imperial.Current)
+	}
+	if imperial.Units.Temperature != "°F" {
+		t.Errorf("Expected °F unit, got %q", imperial.Units.Temperature)
+	}
+
+	scientific := newWeather()
+	scientific.ConvertUnits(UnitsScientific)
+	if scientific.Current.Temp != 293.15 || scientific.Current.WindSpeed != 10 || scientific.Current.Visibility != 10000 {
+		t.Errorf("Unexpected scientific values: %+v", scientific.Current)
+	}
+
+	// Converted values must not be converted again.
+	scientific.ConvertUnits(UnitsImperial)
+	if scientific.Current.Temp != 293.15 {
+		t.Errorf("Expected already converted weather to stay unchanged, got %v", scientific.Current.Temp)
+	}
+}
+
+func TestHistoryConvertUnits_WithoutUnits(t *testing.T) {
+	history := &WeatherHistory{Days: []Day{{MaxTemp: 0, Hours: []Hour{{Temp: 100}}}}}
+
+	history.ConvertUnits(UnitsImperial)
+
+	if history.Days[0].MaxTemp != 32 || history.Days[0].Hours[0].Temp != 212 {
+		t.Errorf("Unexpected converted days: %+v", history.Days[0])
+	}
+	if history.Units.System != UnitsImperial {
+		t.Errorf("Expected imperial units, got %q", history.Units.System)
+	}
+}
+
+func TestWeatherConvertUnits_KeepsMetricFields(t *testing.T) {
+	weatherDto := &dto.WeatherByCity{}
+	weatherDto.Current.TempC = 20
+	weatherDto.Current.WindKph = 36
+	weatherDto.Current.PressureMb = 1013
+	weatherDto.Current.VisKm = 10
+	heatIndex := 30.0
+	weatherDto.Current.HeatindexC = &heatIndex
+	weather := NewWeatherFromDto(weatherDto, &dto.AstroByCity{})
+
+	weather.ConvertUnits(UnitsImperial)
+
+	data, err := json.Marshal(weather)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var body struct {
+		Current map[string]any `json:"current"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := map[string]float64{
+		"temp_c":      20,
+		"wind_kph":    36,
+		"pressure_mb": 1013,
+		"vis_km":      10,
+		"heatindex_c": 30,
+		"heatindex":   86,
+		"uv":          0,
+		"temp":        68,
+		"wind_speed":  22.4,
+		"pressure":    29.91,
+		"vis":         6.2,
+	}
+	for field, value := range expected {
+		if got := body.Current[field]; got != value {
+			t.Errorf("Expected current.%s %v, got %v", field, value, got)
+		}
+	}
+	if uvIndex, ok := body.Current["uv_index"]; !ok || uvIndex != nil {
+		t.Errorf("Expected a null UV index when it is not reported, got %v", uvIndex)
+	}
+}
+
+func TestHistoryConvertUnits_KeepsMetricFields(t *testing.T) {
+	var fd dto.ForecastDay
+	fd.Day.MaxtempC = 10
+	history := &WeatherHistory{Units: UnitsMetric.Units(), Days: []Day{newDayFromDto(fd)}}
+
+	history.ConvertUnits(UnitsImperial)
+
+	if history.Days[0].MaxTempC != 10 || history.Days[0].MaxTemp != 50 {
+		t.Errorf("Expected max_temp_c to stay metric, got %+v", history.Days[0])
+	}
+}
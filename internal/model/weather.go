@@ -3,6 +3,7 @@ package model
 import (
 	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
 	"github.com/DjordjeVuckovic/weather-radar/pkg/util"
+	"math"
 )
 
 type Location struct {
@@ -16,6 +17,10 @@ type Location struct {
 	TzOffset  int     `json:"tz_offset"`
 }
 
+// Current holds the current conditions. The metric fields keep the names of the v1 contract and
+// are always metric, the unit-neutral fields are measured in the units described by Weather.Units.
+// ConditionCode is a weatherapi condition code, other providers are mapped to it. HeatIndex and
+// UvIndex are null when the provider does not report them, the v1 fields are 0 then.
 type Current struct {
 	LastUpdated string  `json:"last_updated"`
 	TempC       float64 `json:"temp_c"`
@@ -31,58 +36,112 @@ type Current struct {
 	HeatindexC  float64 `json:"heatindex_c"`
 	VisKm       int     `json:"vis_km"`
 	Uv          int     `json:"uv"`
+
+	ConditionCode int      `json:"condition_code"`
+	Temp          float64  `json:"temp"`
+	WindSpeed     float64  `json:"wind_speed"`
+	Pressure      float64  `json:"pressure"`
+	Precip        float64  `json:"precip"`
+	FeelsLike     float64  `json:"feelslike"`
+	HeatIndex     *float64 `json:"heatindex"`
+	Visibility    float64  `json:"vis"`
+	UvIndex       *float64 `json:"uv_index"`
+}
+
+// setMetric sets the metric fields from the unit-neutral ones, which must still be metric.
+func (c *Current) setMetric() {
+	c.TempC = c.Temp
+	c.WindKph = c.WindSpeed
+	c.PressureMb = int(c.Pressure)
+	c.PrecipMm = int(c.Precip)
+	c.FeelslikeC = c.FeelsLike
+	if c.HeatIndex != nil {
+		c.HeatindexC = *c.HeatIndex
+	}
+	c.VisKm = int(c.Visibility)
+	if c.UvIndex != nil {
+		c.Uv = int(math.Round(*c.UvIndex))
+	}
 }
+
 type Astro struct {
 	Sunrise string `json:"sunrise"`
 	Sunset  string `json:"sunset"`
 }
 
+// Meta describes how a response was produced. Provider is set when a single provider answered,
+// Providers when the response blends several of them.
+type Meta struct {
+	Provider  string   `json:"provider,omitempty"`
+	Providers []string `json:"providers,omitempty"`
+}
+
 type Weather struct {
-	Location `json:"location"`
-	Current  `json:"current"`
-	Astro    `json:"astro"`
+	Meta       Meta `json:"meta"`
+	Location   `json:"location"`
+	Units      Units `json:"units"`
+	Current    `json:"current"`
+	Astro      `json:"astro"`
+	AirQuality *AirQuality `json:"air_quality,omitempty"`
+	Pollen     *Pollen     `json:"pollen,omitempty"`
+	Alerts     []Alert     `json:"alerts,omitempty"`
+	Blend      *Blend      `json:"blend,omitempty"`
 }
 
+// NewWeatherFromDto builds the weather from provider data. Without astronomy data the timezone
+// offset, sunrise and sunset are left empty.
 func NewWeatherFromDto(weatherDto *dto.WeatherByCity, astroDto *dto.AstroByCity) *Weather {
-	location := Location{
-		Name:      weatherDto.Location.Name,
-		Region:    weatherDto.Location.Region,
-		Country:   weatherDto.Location.Country,
-		Lat:       weatherDto.Location.Lat,
-		Lon:       weatherDto.Location.Lon,
-		TzId:      weatherDto.Location.TzId,
-		Localtime: weatherDto.Location.Localtime,
-		TzOffset:  astroDto.Timezone,
+	var (
+		tzOffset  int
+		astroData Astro
+	)
+	if astroDto != nil {
+		tzOffset = astroDto.Timezone
+		astroData = Astro{
+			Sunrise: util.UnixToLocal(int64(astroDto.Sys.Sunrise), astroDto.Timezone),
+			Sunset:  util.UnixToLocal(int64(astroDto.Sys.Sunset), astroDto.Timezone),
+		}
 	}
+	location := newLocationFromDto(weatherDto.Location, tzOffset)
 
 	current := Current{
-		LastUpdated: weatherDto.Current.LastUpdated,
-		TempC:       weatherDto.Current.TempC,
-		Condition:   weatherDto.Current.Condition.Text,
-		WindKph:     weatherDto.Current.WindKph,
-		WindDegree:  weatherDto.Current.WindDegree,
-		WindDir:     weatherDto.Current.WindDir,
-		PressureMb:  int(weatherDto.Current.PressureMb),
-		PrecipMm:    int(weatherDto.Current.PrecipMm),
-		Humidity:    weatherDto.Current.Humidity,
-		Cloud:       weatherDto.Current.Cloud,
-		FeelslikeC:  weatherDto.Current.FeelslikeC,
-		HeatindexC:  weatherDto.Current.HeatindexC,
-		VisKm:       int(weatherDto.Current.VisKm),
-		Uv:          int(weatherDto.Current.Uv),
+		LastUpdated:   weatherDto.Current.LastUpdated,
+		Temp:          weatherDto.Current.TempC,
+		Condition:     weatherDto.Current.Condition.Text,
+		ConditionCode: weatherDto.Current.Condition.Code,
+		WindSpeed:     weatherDto.Current.WindKph,
+		WindDegree:    weatherDto.Current.WindDegree,
+		WindDir:       weatherDto.Current.WindDir,
+		Pressure:      weatherDto.Current.PressureMb,
+		Precip:        weatherDto.Current.PrecipMm,
+		Humidity:      weatherDto.Current.Humidity,
+		Cloud:         weatherDto.Current.Cloud,
+		FeelsLike:     weatherDto.Current.FeelslikeC,
+		HeatIndex:     weatherDto.Current.HeatindexC,
+		Visibility:    weatherDto.Current.VisKm,
+		UvIndex:       weatherDto.Current.Uv,
 	}
+	current.setMetric()
 
-	sunrise := util.UnixToLocal(int64(astroDto.Sys.Sunrise), astroDto.Timezone)
-	sunset := util.UnixToLocal(int64(astroDto.Sys.Sunset), astroDto.Timezone)
-
-	astroData := Astro{
-		Sunrise: sunrise,
-		Sunset:  sunset,
+	return &Weather{
+		Location:   location,
+		Units:      UnitsMetric.Units(),
+		Current:    current,
+		Astro:      astroData,
+		AirQuality: newAirQualityFromDto(weatherDto.Current.AirQuality),
+		Pollen:     newPollenFromDto(weatherDto.Current.Pollen),
 	}
+}
 
-	return &Weather{
-		Location: location,
-		Current:  current,
-		Astro:    astroData,
+func newLocationFromDto(locationDto dto.Location, tzOffset int) Location {
+	return Location{
+		Name:      locationDto.Name,
+		Region:    locationDto.Region,
+		Country:   locationDto.Country,
+		Lat:       locationDto.Lat,
+		Lon:       locationDto.Lon,
+		TzId:      locationDto.TzId,
+		Localtime: locationDto.Localtime,
+		TzOffset:  tzOffset,
 	}
 }
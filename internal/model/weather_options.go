@@ -0,0 +1,66 @@
+package model
+
+import "strings"
+
+type Section string
+
+const (
+	SectionAirQuality Section = "aqi"
+	SectionPollen     Section = "pollen"
+	SectionAlerts     Section = "alerts"
+)
+
+// WeatherOptions selects optional sections and the language of the weather response.
+// Blend asks all providers and blends their values instead of using the first one that answers.
+type WeatherOptions struct {
+	AirQuality bool
+	Pollen     bool
+	Alerts     bool
+	Lang       Language
+	Blend      bool
+}
+
+// ParseSections parses a comma separated list of optional sections.
+func ParseSections(sections string) (WeatherOptions, bool) {
+	var opts WeatherOptions
+	for _, section := range strings.Split(sections, ",") {
+		switch Section(strings.ToLower(strings.TrimSpace(section))) {
+		case SectionAirQuality:
+			opts.AirQuality = true
+		case SectionPollen:
+			opts.Pollen = true
+		case SectionAlerts:
+			opts.Alerts = true
+		case "":
+		default:
+			return WeatherOptions{}, false
+		}
+	}
+	return opts, true
+}
+
+// Key returns a stable suffix describing the requested options, suitable for cache keys.
+func (o WeatherOptions) Key() string {
+	key := o.sectionsKey() + o.Lang.Key()
+	if o.Blend {
+		key += ":blend"
+	}
+	return key
+}
+
+func (o WeatherOptions) sectionsKey() string {
+	var sections []string
+	if o.AirQuality {
+		sections = append(sections, string(SectionAirQuality))
+	}
+	if o.Pollen {
+		sections = append(sections, string(SectionPollen))
+	}
+	if o.Alerts {
+		sections = append(sections, string(SectionAlerts))
+	}
+	if len(sections) == 0 {
+		return ""
+	}
+	return ":" + strings.Join(sections, "+")
+}
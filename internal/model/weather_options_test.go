@@ -0,0 +1,46 @@
+package model
+
+import "testing"
+
+func TestParseSections(t *testing.T) {
+	tests := []struct {
+		sections string
+		expected WeatherOptions
+		valid    bool
+	}{
+		{"", WeatherOptions{}, true},
+		{"aqi", WeatherOptions{AirQuality: true}, true},
+		{"pollen, AQI", WeatherOptions{AirQuality: true, Pollen: true}, true},
+		{"aqi,uv", WeatherOptions{}, false},
+	}
+
+	for _, tt := range tests {
+		opts, ok := ParseSections(tt.sections)
+		if ok != tt.valid {
+			t.Errorf("ParseSections(%q) valid = %v; want %v", tt.sections, ok, tt.valid)
+		}
+		if opts != tt.expected {
+			t.Errorf("ParseSections(%q) = %+v; want %+v", tt.sections, opts, tt.expected)
+		}
+	}
+}
+
+func TestWeatherOptionsKey(t *testing.T) {
+	if key := (WeatherOptions{}).Key(); key != "" {
+		t.Errorf("Expected empty key for default options, got %q", key)
+	}
+	a, _ := ParseSections("pollen,aqi")
+	b, _ := ParseSections("aqi,pollen")
+	if a.Key() != b.Key() {
+		t.Errorf("Expected equal keys regardless of order, got %q and %q", a.Key(), b.Key())
+	}
+	if a.Key() == (WeatherOptions{AirQuality: true}).Key() {
+		t.Error("Expected different keys for different sections")
+	}
+}
+
+func TestWeatherOptionsKey_Language(t *testing.T) {
+	if (WeatherOptions{Lang: "fr"}).Key() == (WeatherOptions{}).Key() {
+		t.Error("Expected different keys for different languages")
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/DjordjeVuckovic/weather-radar/internal/storage"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	apiKeyPrefix = "wr_"
+	// apiKeyBytes of randomness make keys impossible to guess, so they can be hashed with SHA-256.
+	apiKeyBytes = 24
+	// apiKeyShownPrefix is the part of a key kept in plain text, to tell keys apart in listings.
+	apiKeyShownPrefix = 8
+
+	MaxApiKeyTenantLength = 64
+	MaxApiKeyNameLength   = 100
+)
+
+// ApiKeyService issues API keys to tenants and resolves them to the plan their requests are limited by.
+type ApiKeyService struct {
+	storage storage.ApiKeyStorage
+	// plans maps a plan name to the requests per minute it allows.
+	plans map[string]int
+}
+
+func NewApiKeyService(st storage.ApiKeyStorage, plans map[string]int) *ApiKeyService {
+	return &ApiKeyService{
+		storage: st,
+		plans:   plans,
+	}
+}
+
+// Issue creates a key for the tenant. The returned response holds the only copy of the key.
+func (a *ApiKeyService) Issue(ctx context.Context, req *dto.ApiKeyReq) (*dto.ApiKeyResp, error) {
+	req.Tenant = strings.TrimSpace(req.Tenant)
+	req.Name = strings.TrimSpace(req.Name)
+	req.Plan = strings.ToLower(strings.TrimSpace(req.Plan))
+
+	if req.Tenant == "" {
+		return nil, result.ValidationErr("Tenant is required")
+	}
+	if utf8.RuneCountInString(req.Tenant) > MaxApiKeyTenantLength {
+		return nil, result.ValidationErr(fmt.Sprintf("Tenant must be at most %d characters long", MaxApiKeyTenantLength))
+	}
+	if utf8.RuneCountInString(req.Name) > MaxApiKeyNameLength {
+		return nil, result.ValidationErr(fmt.Sprintf("Name must be at most %d characters long", MaxApiKeyNameLength))
+	}
+	if _, ok := a.plans[req.Plan]; !ok {
+		return nil, result.ValidationErr(fmt.Sprintf("Plan must be one of %v", a.planNames()))
+	}
+
+	key, err := generateApiKey()
+	if err != nil {
+		return nil, err
+	}
+	apiKey := &model.ApiKey{
+		ID:        uuid.New(),
+		Prefix:    key[:apiKeyShownPrefix],
+		KeyHash:   model.HashApiKey(key),
+		Tenant:    req.Tenant,
+		Name:      req.Name,
+		Plan:      req.Plan,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := a.storage.AddApiKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &dto.ApiKeyResp{
+		ID:        apiKey.ID,
+		Key:       key,
+		Tenant:    apiKey.Tenant,
+		Name:      apiKey.Name,
+		Plan:      apiKey.Plan,
+		CreatedAt: apiKey.CreatedAt,
+	}, nil
+}
+
+// List returns the keys of a tenant, or of all tenants for an empty tenant.
+func (a *ApiKeyService) List(ctx context.Context, tenant string) ([]*model.ApiKey, error) {
+	keys, err := a.storage.ListApiKeys(ctx, strings.TrimSpace(tenant))
+	if err != nil {
+		return nil, err
+	}
+	if keys == nil {
+		keys = []*model.ApiKey{}
+	}
+	return keys, nil
+}
+
+// Revoke stops the key from being accepted. Revoking a revoked key does nothing.
+func (a *ApiKeyService) Revoke(ctx context.Context, id uuid.UUID) error {
+	err := a.storage.RevokeApiKey(ctx, id, time.Now().UTC())
+	if errors.Is(err, storage.ErrNotFound) {
+		return result.NotFoundErr("API key not found: " + id.String())
+	}
+	return err
+}
+
+// Resolve returns the active key and the requests per minute its plan allows.
+// Unknown and revoked keys are unauthorized.
+func (a *ApiKeyService) Resolve(ctx context.Context, key string) (*model.ApiKey, int, error) {
+	apiKey, err := a.storage.GetApiKeyByHash(ctx, model.HashApiKey(key))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, 0, result.UnauthorizedErr("Invalid API key")
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if apiKey.Revoked() {
+		return nil, 0, result.UnauthorizedErr("Invalid API key")
+	}
+
+	// A plan removed from the config falls back to the smallest one still offered.
+	rpm, ok := a.plans[apiKey.Plan]
+	if !ok {
+		for _, limit := range a.plans {
+			if !ok || limit < rpm {
+				rpm, ok = limit, true
+			}
+		}
+	}
+	return apiKey, rpm, nil
+}
+
+func (a *ApiKeyService) planNames() []string {
+	names := make([]string, 0, len(a.plans))
+	for name := range a.plans {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func generateApiKey() (string, error) {
+	b := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate api key: %w", err)
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
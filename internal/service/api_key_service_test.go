@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"github.com/DjordjeVuckovic/weather-radar/internal/storage"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/google/uuid"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestApiKeyService(t *testing.T) {
+	ctx := context.Background()
+	keyService := NewApiKeyService(storage.NewWeatherInMemStorage(), map[string]int{"free": 60, "partner": 6000})
+
+	issued, err := keyService.Issue(ctx, &dto.ApiKeyReq{Tenant: " acme ", Name: "web", Plan: "Partner"})
+	if err != nil {
+		t.Fatalf("Expected no error issuing key, got %v", err)
+	}
+	if !strings.HasPrefix(issued.Key, apiKeyPrefix) || issued.Tenant != "acme" || issued.Plan != "partner" {
+		t.Fatalf("Expected a partner key for acme, got %+v", issued)
+	}
+
+	key, rpm, err := keyService.Resolve(ctx, issued.Key)
+	if err != nil {
+		t.Fatalf("Expected no error resolving key, got %v", err)
+	}
+	if key.ID != issued.ID || rpm != 6000 {
+		t.Fatalf("Expected key %v with 6000 requests per minute, got %v with %d", issued.ID, key.ID, rpm)
+	}
+
+	keys, _ := keyService.List(ctx, "acme")
+	if len(keys) != 1 || keys[0].Prefix != issued.Key[:apiKeyShownPrefix] {
+		t.Fatalf("Expected the issued key listed by its prefix, got %+v", keys)
+	}
+
+	if err := keyService.Revoke(ctx, issued.ID); err != nil {
+		t.Fatalf("Expected no error revoking key, got %v", err)
+	}
+	if _, _, err := keyService.Resolve(ctx, issued.Key); !isStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("Expected revoked key to be unauthorized, got %v", err)
+	}
+	if _, _, err := keyService.Resolve(ctx, "wr_unknown"); !isStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("Expected unknown key to be unauthorized, got %v", err)
+	}
+	if err := keyService.Revoke(ctx, uuid.New()); !isStatus(err, http.StatusNotFound) {
+		t.Fatalf("Expected not found revoking an unknown key, got %v", err)
+	}
+}
+
+func TestApiKeyService_IssueValidation(t *testing.T) {
+	keyService := NewApiKeyService(storage.NewWeatherInMemStorage(), map[string]int{"free": 60})
+
+	tests := map[string]dto.ApiKeyReq{
+		"missing tenant": {Tenant: " ", Plan: "free"},
+		"long tenant":    {Tenant: strings.Repeat("a", MaxApiKeyTenantLength+1), Plan: "free"},
+		"unknown plan":   {Tenant: "acme", Plan: "gold"},
+	}
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := keyService.Issue(context.Background(), &req); !isStatus(err, http.StatusBadRequest) {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func isStatus(err error, status int) bool {
+	var rErr *result.Err
+	return errors.As(err, &rErr) && rErr.Status == status
+}
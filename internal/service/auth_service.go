@@ -1,20 +1,157 @@
 package service
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"os"
+	"slices"
+	"strings"
+)
+
+type Role string
+
+const (
+	RoleReader         Role = "reader"
+	RoleFeedbackWriter Role = "feedback-writer"
+	RoleAdmin          Role = "admin"
+)
+
+// impliedRoles lists the roles each role grants, an admin can do everything a writer can,
+// a writer everything a reader can.
+var impliedRoles = map[Role][]Role{
+	RoleReader:         {RoleReader},
+	RoleFeedbackWriter: {RoleFeedbackWriter, RoleReader},
+	RoleAdmin:          {RoleAdmin, RoleFeedbackWriter, RoleReader},
+}
+
+func ParseRole(s string) (Role, bool) {
+	r := Role(strings.ToLower(strings.TrimSpace(s)))
+	_, ok := impliedRoles[r]
+	return r, ok
+}
+
 type AuthCredentials struct {
 	Username string
 	Password string
 }
 
+// User is an account allowed to call protected endpoints. PasswordHash is a bcrypt hash.
+type User struct {
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"`
+	Roles        []Role `json:"roles"`
+}
+
+type usersFile struct {
+	Users []User `json:"users"`
+}
+
+// LoadUsersFile reads users from a JSON file of the form {"users": [{"username", "password_hash", "roles"}]}.
+func LoadUsersFile(path string) ([]User, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read users file: %w", err)
+	}
+	var file usersFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse users file: %w", err)
+	}
+	return file.Users, nil
+}
+
+// NewUser creates a user with the password hashed by bcrypt, e.g. for the admin configured by env vars.
+func NewUser(username, password string, roles ...Role) (User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return User{}, err
+	}
+	return User{Username: username, PasswordHash: string(hash), Roles: roles}, nil
+}
+
 type AuthService struct {
-	basicAuthAdminCreds AuthCredentials
+	users map[string]User
+	// dummyHash is compared against for unknown users, so a response does not take less time
+	// when the username does not exist.
+	dummyHash []byte
 }
 
-func NewAuthService(adminCreds AuthCredentials) *AuthService {
-	return &AuthService{
-		basicAuthAdminCreds: adminCreds,
+// NewAuthService validates the users, their password hashes and roles.
+func NewAuthService(users []User) (*AuthService, error) {
+	if len(users) == 0 {
+		return nil, errors.New("at least one user is required")
+	}
+
+	byName := make(map[string]User, len(users))
+	for _, u := range users {
+		if u.Username == "" {
+			return nil, errors.New("user without username")
+		}
+		if _, ok := byName[u.Username]; ok {
+			return nil, fmt.Errorf("duplicate user %q", u.Username)
+		}
+		if _, err := bcrypt.Cost([]byte(u.PasswordHash)); err != nil {
+			return nil, fmt.Errorf("user %q: password hash is not a bcrypt hash", u.Username)
+		}
+		if len(u.Roles) == 0 {
+			return nil, fmt.Errorf("user %q has no roles", u.Username)
+		}
+		for i, role := range u.Roles {
+			r, ok := ParseRole(string(role))
+			if !ok {
+				return nil, fmt.Errorf("user %q has unknown role %q", u.Username, role)
+			}
+			u.Roles[i] = r
+		}
+		byName[u.Username] = u
+	}
+
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("weather-radar"), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
 	}
+	return &AuthService{users: byName, dummyHash: dummyHash}, nil
 }
 
-func (a *AuthService) ValidateBasicAuth(creds AuthCredentials) bool {
-	return creds.Username == a.basicAuthAdminCreds.Username && creds.Password == a.basicAuthAdminCreds.Password
+// ValidateBasicAuth reports whether the credentials belong to a user. Passwords are compared by
+// bcrypt in constant time, unknown users are compared against a dummy hash.
+func (a *AuthService) ValidateBasicAuth(creds AuthCredentials) (User, bool) {
+	user, ok := a.users[creds.Username]
+	hash := a.dummyHash
+	if ok {
+		hash = []byte(user.PasswordHash)
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(creds.Password)); err != nil || !ok {
+		return User{}, false
+	}
+	return user, true
+}
+
+// RolesGranting returns the roles that grant the role, from the least to the most privileged.
+// Identities whose roles are not expanded, like the roles claim of a token, are checked against them.
+func RolesGranting(role Role) []Role {
+	var granting []Role
+	for _, r := range []Role{RoleReader, RoleFeedbackWriter, RoleAdmin} {
+		if slices.Contains(impliedRoles[r], role) {
+			granting = append(granting, r)
+		}
+	}
+	return granting
+}
+
+// GrantedRoles returns every role the user's roles imply.
+func (u User) GrantedRoles() []Role {
+	seen := make(map[Role]struct{})
+	var granted []Role
+	for _, role := range u.Roles {
+		for _, implied := range impliedRoles[role] {
+			if _, ok := seen[implied]; ok {
+				continue
+			}
+			seen[implied] = struct{}{}
+			granted = append(granted, implied)
+		}
+	}
+	return granted
 }
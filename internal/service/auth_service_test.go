@@ -0,0 +1,101 @@
+package service
+
+import (
+	"golang.org/x/crypto/bcrypt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func hashPassword(t *testing.T, password string) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Expected no error hashing password, got %v", err)
+	}
+	return string(hash)
+}
+
+func TestAuthService_ValidateBasicAuth(t *testing.T) {
+	authService, err := NewAuthService([]User{
+		{Username: "ana", PasswordHash: hashPassword(t, "secret"), Roles: []Role{RoleFeedbackWriter}},
+		{Username: "marko", PasswordHash: hashPassword(t, "admin"), Roles: []Role{"Admin"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		creds    AuthCredentials
+		wantOK   bool
+		wantUser string
+	}{
+		{"valid credentials", AuthCredentials{Username: "ana", Password: "secret"}, true, "ana"},
+		{"wrong password", AuthCredentials{Username: "ana", Password: "admin"}, false, ""},
+		{"unknown user", AuthCredentials{Username: "eve", Password: "secret"}, false, ""},
+		{"password of another user", AuthCredentials{Username: "marko", Password: "secret"}, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, ok := authService.ValidateBasicAuth(tt.creds)
+			if ok != tt.wantOK || user.Username != tt.wantUser {
+				t.Fatalf("Expected %v for %q, got %v for %q", tt.wantOK, tt.wantUser, ok, user.Username)
+			}
+		})
+	}
+
+	admin, _ := authService.ValidateBasicAuth(AuthCredentials{Username: "marko", Password: "admin"})
+	if want := []Role{RoleAdmin, RoleFeedbackWriter, RoleReader}; !reflect.DeepEqual(admin.GrantedRoles(), want) {
+		t.Errorf("Expected admin to be granted %v, got %v", want, admin.GrantedRoles())
+	}
+}
+
+func TestNewAuthService_RejectsInvalidUsers(t *testing.T) {
+	hash := hashPassword(t, "secret")
+	tests := map[string][]User{
+		"no users":        nil,
+		"plain password":  {{Username: "ana", PasswordHash: "secret", Roles: []Role{RoleReader}}},
+		"unknown role":    {{Username: "ana", PasswordHash: hash, Roles: []Role{"owner"}}},
+		"no roles":        {{Username: "ana", PasswordHash: hash}},
+		"duplicate users": {{Username: "ana", PasswordHash: hash, Roles: []Role{RoleReader}}, {Username: "ana", PasswordHash: hash, Roles: []Role{RoleAdmin}}},
+	}
+	for name, users := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewAuthService(users); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestLoadUsersFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	content := `{"users": [{"username": "ana", "password_hash": "` + hashPassword(t, "secret") + `", "roles": ["reader"]}]}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Expected no error writing users file, got %v", err)
+	}
+
+	users, err := LoadUsersFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(users) != 1 || users[0].Username != "ana" || !reflect.DeepEqual(users[0].Roles, []Role{RoleReader}) {
+		t.Fatalf("Unexpected users %+v", users)
+	}
+}
+
+func TestRolesGranting(t *testing.T) {
+	tests := map[Role][]Role{
+		RoleReader:         {RoleReader, RoleFeedbackWriter, RoleAdmin},
+		RoleFeedbackWriter: {RoleFeedbackWriter, RoleAdmin},
+		RoleAdmin:          {RoleAdmin},
+	}
+	for role, want := range tests {
+		if got := RolesGranting(role); !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %s to be granted by %v, got %v", role, want, got)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/DjordjeVuckovic/weather-radar/internal/client"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// withFallback asks the providers in priority order and moves on to the next one when a provider
+// fails with a server error, does not support the operation or does not answer within
+// attemptTimeout. Client errors, such as an unknown location, are returned right away, as
+// another provider would not do better. A zero attemptTimeout bounds attempts by ctx only.
+// It returns the name of the provider that answered.
+func withFallback[T any](
+	ctx context.Context,
+	providers *client.ProviderRegistry,
+	attemptTimeout time.Duration,
+	fetch func(ctx context.Context, cl client.WeatherClient) (T, error),
+) (T, string, error) {
+	var (
+		zero    T
+		lastErr error = result.InternalServerErr("No weather provider configured")
+	)
+
+	for _, p := range providers.Providers() {
+		attemptCtx, cancel := attemptContext(ctx, attemptTimeout)
+		v, err := fetch(attemptCtx, p.Client)
+		cancel()
+
+		if err == nil {
+			return v, p.Name, nil
+		}
+		if ctx.Err() != nil || !shouldFallback(err) {
+			return zero, p.Name, err
+		}
+
+		slog.Warn("Weather provider failed, trying next one", slog.String("provider", p.Name), slog.String("error", err.Error()))
+		lastErr = err
+	}
+
+	return zero, "", lastErr
+}
+
+func attemptContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func shouldFallback(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, client.ErrUnsupported) {
+		return true
+	}
+	var resErr *result.Err
+	if errors.As(err, &resErr) {
+		return resErr.Status >= http.StatusInternalServerError
+	}
+	// Transport errors carry no status and are treated as the provider being unavailable.
+	return true
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/internal/client"
+	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/util"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const cityLookupTimeout = 2 * time.Second
+
+// latestTimezone is the first timezone to start a new day. A feedback date is in the future
+// only once the day has not started anywhere, so users ahead of UTC are not rejected.
+var latestTimezone = time.FixedZone("UTC+14", 14*60*60)
+
+// validateFeedback checks the feedback fields that do not need a weather provider and normalizes
+// them in place. now is the time the feedback is validated at.
+func validateFeedback(req *dto.WeatherFeedbackReq, now time.Time) error {
+	req.City = strings.TrimSpace(req.City)
+	req.Message = strings.TrimSpace(req.Message)
+
+	date, err := util.ParseDate(strings.TrimSpace(req.Date))
+	if err != nil {
+		return result.ValidationErr("Date must be a date in YYYY-MM-DD format")
+	}
+	today, _ := util.ParseDate(now.In(latestTimezone).Format(time.DateOnly))
+	if date.After(today) {
+		return result.ValidationErr("Date must not be in the future")
+	}
+	req.Date = date.Format(time.DateOnly)
+
+	if req.City == "" {
+		return result.ValidationErr("City is required")
+	}
+	if utf8.RuneCountInString(req.City) > model.MaxFeedbackCityLength {
+		return result.ValidationErr(fmt.Sprintf("City must be at most %d characters long", model.MaxFeedbackCityLength))
+	}
+
+	if req.Message == "" {
+		return result.ValidationErr("Message is required")
+	}
+	if utf8.RuneCountInString(req.Message) > model.MaxFeedbackMessageLength {
+		return result.ValidationErr(fmt.Sprintf("Message must be at most %d characters long", model.MaxFeedbackMessageLength))
+	}
+
+	// A rating of 0 means the user did not rate the forecast.
+	if req.Rating != 0 && (req.Rating < model.MinFeedbackRating || req.Rating > model.MaxFeedbackRating) {
+		return result.ValidationErr(fmt.Sprintf("Rating must be between %d and %d", model.MinFeedbackRating, model.MaxFeedbackRating))
+	}
+
+	if req.ObservedCondition != "" {
+		condition, ok := model.ParseObservedCondition(req.ObservedCondition)
+		if !ok {
+			return result.ValidationErr(fmt.Sprintf("Observed condition must be one of %v", model.ObservedConditions()))
+		}
+		req.ObservedCondition = string(condition)
+	}
+
+	if t := req.ReportedTemp; t != nil && (*t < model.MinReportedTemp || *t > model.MaxReportedTemp) {
+		return result.ValidationErr(fmt.Sprintf("Reported temperature must be between %.0f and %.0f °C", model.MinReportedTemp, model.MaxReportedTemp))
+	}
+
+	return nil
+}
+
+// resolveCity looks the city up with the weather providers and returns its canonical name.
+// A city is known when the search finds it, the top result is taken as the providers rank the
+// closest match first, so "Zurich" resolves to "Zürich".
+func (w *WeatherService) resolveCity(ctx context.Context, city string) (string, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, cityLookupTimeout)
+	defer cancel()
+
+	locations, _, err := withFallback(timeoutCtx, w.providers, providerTimeout, func(ctx context.Context, cl client.WeatherClient) ([]dto.SearchLocation, error) {
+		return cl.SearchLocations(ctx, city)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(locations) == 0 {
+		return "", result.ValidationErr("Unknown city: " + city)
+	}
+	return locations[0].Name, nil
+}
+
+// validateFeedbackReq validates the feedback request and replaces its city with the canonical name.
+func (w *WeatherService) validateFeedbackReq(ctx context.Context, req *dto.WeatherFeedbackReq) error {
+	if err := validateFeedback(req, time.Now()); err != nil {
+		return err
+	}
+
+	city, err := w.resolveCity(ctx, req.City)
+	if err != nil {
+		return err
+	}
+	req.City = city
+	return nil
+}
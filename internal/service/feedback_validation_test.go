@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateFeedback(t *testing.T) {
+	now := time.Date(2024, 10, 27, 12, 0, 0, 0, time.UTC)
+	temp := func(v float64) *float64 {
+		return &v
+	}
+	valid := func() dto.WeatherFeedbackReq {
+		return dto.WeatherFeedbackReq{Date: "2024-10-27", City: "London", Message: "Rain", Rating: 3}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(req *dto.WeatherFeedbackReq)
+		wantErr string
+	}{
+		{"valid", func(req *dto.WeatherFeedbackReq) {}, ""},
+		{"tomorrow in the latest timezone", func(req *dto.WeatherFeedbackReq) { req.Date = "2024-10-28" }, ""},
+		{"future date", func(req *dto.WeatherFeedbackReq) { req.Date = "2024-10-29" }, "Date must not be in the future"},
+		{"invalid date", func(req *dto.WeatherFeedbackReq) { req.Date = "27.10.2024" }, "Date must be a date in YYYY-MM-DD format"},
+		{"missing city", func(req *dto.WeatherFeedbackReq) { req.City = "  " }, "City is required"},
+		{"missing message", func(req *dto.WeatherFeedbackReq) { req.Message = "" }, "Message is required"},
+		{"long message", func(req *dto.WeatherFeedbackReq) { req.Message = strings.Repeat("ž", 1001) }, "Message must be at most 1000 characters long"},
+		{"no rating", func(req *dto.WeatherFeedbackReq) { req.Rating = 0 }, ""},
+		{"rating too low", func(req *dto.WeatherFeedbackReq) { req.Rating = -1 }, "Rating must be between 1 and 5"},
+		{"rating too high", func(req *dto.WeatherFeedbackReq) { req.Rating = 6 }, "Rating must be between 1 and 5"},
+		{"known condition", func(req *dto.WeatherFeedbackReq) { req.ObservedCondition = "Rain" }, ""},
+		{"unknown condition", func(req *dto.WeatherFeedbackReq) { req.ObservedCondition = "meteors" }, "Observed condition must be one of"},
+		{"plausible temperature", func(req *dto.WeatherFeedbackReq) { req.ReportedTemp = temp(-12.5) }, ""},
+		{"implausible temperature", func(req *dto.WeatherFeedbackReq) { req.ReportedTemp = temp(75) }, "Reported temperature must be between -90 and 60 °C"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(&req)
+
+			err := validateFeedback(&req, now)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+			var resErr *result.Err
+			if !errors.As(err, &resErr) || resErr.Status != http.StatusBadRequest || !strings.HasPrefix(resErr.Detail, tt.wantErr) {
+				t.Fatalf("Expected validation error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateFeedback_Normalizes(t *testing.T) {
+	req := dto.WeatherFeedbackReq{Date: " 2024-10-27 ", City: " London ", Message: " Rain ", Rating: 3, ObservedCondition: " Partly_Cloudy "}
+
+	if err := validateFeedback(&req, time.Date(2024, 10, 27, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if req.Date != "2024-10-27" || req.City != "London" || req.Message != "Rain" || req.ObservedCondition != "partly_cloudy" {
+		t.Fatalf("Expected normalized request, got %+v", req)
+	}
+}
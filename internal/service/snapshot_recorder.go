@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/DjordjeVuckovic/weather-radar/internal/storage"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// snapshotQueueSize bounds the snapshots waiting to be saved, snapshots beyond it are dropped.
+	snapshotQueueSize = 256
+	snapshotTimeout   = 2 * time.Second
+)
+
+// snapshotRecorder saves weather snapshots in the background, so serving weather never waits for storage.
+type snapshotRecorder struct {
+	storage   storage.WeatherStorage
+	snapshots chan model.WeatherSnapshot
+	done      chan struct{}
+
+	mx     sync.Mutex
+	closed bool
+}
+
+func newSnapshotRecorder(st storage.WeatherStorage, size int) *snapshotRecorder {
+	r := &snapshotRecorder{
+		storage:   st,
+		snapshots: make(chan model.WeatherSnapshot, size),
+		done:      make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// record queues the snapshot. It is dropped when the queue is full or the recorder is stopped,
+// as the report is not worth slowing down a weather request for.
+func (r *snapshotRecorder) record(snapshot model.WeatherSnapshot) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.snapshots <- snapshot:
+	default:
+		slog.Warn("Snapshot queue is full, dropping weather snapshot", slog.String("city", snapshot.City))
+	}
+}
+
+func (r *snapshotRecorder) run() {
+	defer close(r.done)
+	for snapshot := range r.snapshots {
+		ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+		err := r.storage.SaveWeatherSnapshot(ctx, snapshot)
+		cancel()
+		if err != nil {
+			slog.Warn("Failed to save weather snapshot", slog.String("city", snapshot.City), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// stop saves the queued snapshots and waits for the worker to exit.
+func (r *snapshotRecorder) stop() {
+	r.mx.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.snapshots)
+	}
+	r.mx.Unlock()
+	<-r.done
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/DjordjeVuckovic/weather-radar/internal/storage"
+	"slices"
+	"testing"
+)
+
+type blockingSnapshotStorage struct {
+	storage.WeatherStorage
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSnapshotStorage) SaveWeatherSnapshot(ctx context.Context, snapshot model.WeatherSnapshot) error {
+	s.started <- struct{}{}
+	<-s.release
+	return s.WeatherStorage.SaveWeatherSnapshot(ctx, snapshot)
+}
+
+func TestSnapshotRecorder_DropsWhenQueueIsFull(t *testing.T) {
+	st := &blockingSnapshotStorage{
+		WeatherStorage: storage.NewWeatherInMemStorage(),
+		started:        make(chan struct{}, 3),
+		release:        make(chan struct{}),
+	}
+	recorder := newSnapshotRecorder(st, 1)
+
+	recorder.record(model.WeatherSnapshot{City: "London", Date: "2024-10-27", Provider: "weatherapi"})
+	<-st.started
+	recorder.record(model.WeatherSnapshot{City: "Paris", Date: "2024-10-27", Provider: "weatherapi"})
+	recorder.record(model.WeatherSnapshot{City: "Tokyo", Date: "2024-10-27", Provider: "weatherapi"})
+	close(st.release)
+	recorder.stop()
+	recorder.record(model.WeatherSnapshot{City: "Berlin", Date: "2024-10-27", Provider: "weatherapi"})
+
+	snapshots, err := st.ListWeatherSnapshots(context.Background(), model.SnapshotFilter{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("Expected the running and the queued snapshot saved, got %+v", snapshots)
+	}
+	cities := []string{snapshots[0].City, snapshots[1].City}
+	slices.Sort(cities)
+	if !slices.Equal(cities, []string{"London", "Paris"}) {
+		t.Fatalf("Expected London and Paris saved, got %v", cities)
+	}
+}
@@ -0,0 +1,438 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// pollTimeout bounds a single fetch of a poller, a slow provider delays only its next update.
+	pollTimeout = 10 * time.Second
+
+	DefaultMaxPolledCities        = 500
+	DefaultMaxClientSubscriptions = 5
+)
+
+// WeatherUpdate is the changed weather of a city. Its ID orders the updates of a hub.
+type WeatherUpdate struct {
+	ID      string         `json:"-"`
+	City    string         `json:"city"`
+	Weather *model.Weather `json:"weather"`
+	seq     uint64
+}
+
+// WeatherFetcher returns the current weather of a city.
+type WeatherFetcher func(ctx context.Context, city string) (*model.Weather, error)
+
+// WeatherHubConfig configures the polling of a WeatherHub and what it lets clients subscribe to.
+type WeatherHubConfig struct {
+	Fetch    WeatherFetcher
+	Interval time.Duration
+	// Record, if set, is called with the weather of every update, once however many subscribers
+	// receive it. It is called with the hub locked and must not block.
+	Record func(weather *model.Weather)
+	// MaxPolledCities caps the cities polled at once, subscriptions to further cities are refused.
+	// Defaults to DefaultMaxPolledCities.
+	MaxPolledCities int
+	// MaxClientSubscriptions caps the open subscriptions of a client. Defaults to
+	// DefaultMaxClientSubscriptions.
+	MaxClientSubscriptions int
+}
+
+// WeatherHub polls the weather of the cities clients subscribed to and notifies them when it
+// changes. A city is polled once per interval however many subscribers it has, and only while
+// it has any.
+type WeatherHub struct {
+	fetch                  WeatherFetcher
+	record                 func(weather *model.Weather)
+	interval               time.Duration
+	maxPolledCities        int
+	maxClientSubscriptions int
+	// epoch tells the update IDs of this hub from those of an earlier process or another replica.
+	epoch string
+	seq   atomic.Uint64
+
+	mx     sync.Mutex
+	cities map[string]*cityPoller
+	// aliases maps the cities as subscribed to the key of the location they resolved to.
+	aliases map[string]string
+	// clients counts the open subscriptions of every client.
+	clients map[string]int
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// cityPoller polls a location by the city it was first subscribed as. It is keyed by the location,
+// so the names resolving to the same location share it.
+type cityPoller struct {
+	key         string
+	query       string
+	aliases     []string
+	subscribers map[*Subscription]struct{}
+	latest      *WeatherUpdate
+	cancel      context.CancelFunc
+}
+
+// resolvedCity is a subscribed city and, unless a poller already polls it, its current weather.
+type resolvedCity struct {
+	name    string
+	key     string
+	weather *model.Weather
+}
+
+func NewWeatherHub(config WeatherHubConfig) *WeatherHub {
+	if config.MaxPolledCities <= 0 {
+		config.MaxPolledCities = DefaultMaxPolledCities
+	}
+	if config.MaxClientSubscriptions <= 0 {
+		config.MaxClientSubscriptions = DefaultMaxClientSubscriptions
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WeatherHub{
+		fetch:                  config.Fetch,
+		record:                 config.Record,
+		interval:               config.Interval,
+		maxPolledCities:        config.MaxPolledCities,
+		maxClientSubscriptions: config.MaxClientSubscriptions,
+		epoch:                  strconv.FormatInt(time.Now().UnixNano(), 36),
+		cities:                 make(map[string]*cityPoller),
+		aliases:                make(map[string]string),
+		clients:                make(map[string]int),
+		ctx:                    ctx,
+		cancel:                 cancel,
+	}
+}
+
+// Subscribe subscribes the client to the weather of the cities. The current weather of every city
+// is sent first, or with the ID of the last update received only the weather that changed since.
+// Cities nobody subscribed to yet are fetched first, so unknown cities fail the subscription
+// before a poller is started for them.
+func (h *WeatherHub) Subscribe(ctx context.Context, client string, cities []string, lastEventID string) (*Subscription, error) {
+	if err := h.addClient(client); err != nil {
+		return nil, err
+	}
+	resolved, err := h.resolve(ctx, cities)
+	if err != nil {
+		h.removeClient(client)
+		return nil, err
+	}
+
+	since, resume := h.parseEventID(lastEventID)
+	sub := &Subscription{
+		hub:     h,
+		client:  client,
+		names:   make(map[string]string),
+		pending: make(map[string]WeatherUpdate),
+		notify:  make(chan struct{}, 1),
+	}
+
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	for _, city := range resolved {
+		p, err := h.poller(city)
+		if err != nil {
+			h.removeSubscriber(sub)
+			return nil, err
+		}
+		if _, ok := sub.names[p.key]; ok {
+			continue
+		}
+		sub.names[p.key] = city.name
+		p.subscribers[sub] = struct{}{}
+		if p.latest != nil && (!resume || p.latest.seq > since) {
+			sub.push(*p.latest)
+		}
+	}
+	return sub, nil
+}
+
+// Stop stops polling. Subscriptions get no more updates.
+func (h *WeatherHub) Stop() {
+	h.cancel()
+	h.wg.Wait()
+}
+
+func (h *WeatherHub) addClient(client string) error {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	if h.clients[client] >= h.maxClientSubscriptions {
+		return result.TooManyRequestsErr(fmt.Sprintf("No more than %d weather subscriptions can be open at once", h.maxClientSubscriptions), 0)
+	}
+	h.clients[client]++
+	return nil
+}
+
+func (h *WeatherHub) removeClient(client string) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	h.releaseClient(client)
+}
+
+func (h *WeatherHub) releaseClient(client string) {
+	if h.clients[client]--; h.clients[client] <= 0 {
+		delete(h.clients, client)
+	}
+}
+
+// resolve fetches the cities without a poller concurrently. A city no provider knows is a
+// validation error.
+func (h *WeatherHub) resolve(ctx context.Context, cities []string) ([]resolvedCity, error) {
+	resolved, unresolved := h.splitResolved(cities)
+
+	errs := make([]error, len(unresolved))
+	var wg sync.WaitGroup
+	for n, i := range unresolved {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fetchCtx, cancel := context.WithTimeout(ctx, pollTimeout)
+			defer cancel()
+			resolved[i].weather, errs[n] = h.fetch(fetchCtx, resolved[i].key)
+		}()
+	}
+	wg.Wait()
+
+	for n, err := range errs {
+		var resErr *result.Err
+		if errors.As(err, &resErr) && resErr.Status == http.StatusNotFound {
+			return nil, result.ValidationErr("Unknown city: " + resolved[unresolved[n]].name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// splitResolved deduplicates the cities and returns the indexes of those without a poller.
+func (h *WeatherHub) splitResolved(cities []string) ([]resolvedCity, []int) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	var (
+		resolved   []resolvedCity
+		unresolved []int
+	)
+	for _, city := range cities {
+		key := cityKey(city)
+		if slices.ContainsFunc(resolved, func(c resolvedCity) bool { return c.key == key }) {
+			continue
+		}
+		if _, ok := h.aliases[key]; !ok {
+			unresolved = append(unresolved, len(resolved))
+		}
+		resolved = append(resolved, resolvedCity{name: strings.TrimSpace(city), key: key})
+	}
+	return resolved, unresolved
+}
+
+// poller returns the poller of the city, starting one with the weather it resolved to if needed.
+func (h *WeatherHub) poller(city resolvedCity) (*cityPoller, error) {
+	if key, ok := h.aliases[city.key]; ok {
+		return h.cities[key], nil
+	}
+	// The poller the city resolved by was stopped in the meantime, it is fetched again.
+	key := city.key
+	if city.weather != nil {
+		key = city.weather.Location.Key()
+	}
+	p, ok := h.cities[key]
+	if !ok {
+		if len(h.cities) >= h.maxPolledCities {
+			err := result.NewErr(http.StatusServiceUnavailable, "Too many cities are watched at the moment, try again later")
+			err.RetryAfter = h.interval
+			return nil, err
+		}
+		p = h.startPoller(key, city.key, city.weather)
+	}
+	h.aliases[city.key] = key
+	p.aliases = append(p.aliases, city.key)
+	return p, nil
+}
+
+func (h *WeatherHub) startPoller(key, query string, weather *model.Weather) *cityPoller {
+	ctx, cancel := context.WithCancel(h.ctx)
+	p := &cityPoller{
+		key:         key,
+		query:       query,
+		subscribers: make(map[*Subscription]struct{}),
+		cancel:      cancel,
+	}
+	if weather != nil {
+		p.latest = h.newUpdate(key, weather)
+	}
+	h.cities[key] = p
+
+	h.wg.Add(1)
+	go h.poll(ctx, p)
+	return p
+}
+
+func (h *WeatherHub) poll(ctx context.Context, p *cityPoller) {
+	defer h.wg.Done()
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	h.mx.Lock()
+	resolved := p.latest != nil
+	h.mx.Unlock()
+	if !resolved {
+		h.refresh(ctx, p)
+	}
+	for {
+		select {
+		case <-ticker.C:
+			h.refresh(ctx, p)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (h *WeatherHub) refresh(ctx context.Context, p *cityPoller) {
+	fetchCtx, cancel := context.WithTimeout(ctx, pollTimeout)
+	defer cancel()
+	weather, err := h.fetch(fetchCtx, p.query)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("Failed to poll weather", slog.String("city", p.query), slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	if p.latest != nil && !weatherChanged(p.latest.Weather, weather) {
+		return
+	}
+	p.latest = h.newUpdate(p.key, weather)
+	for sub := range p.subscribers {
+		sub.push(*p.latest)
+	}
+}
+
+func (h *WeatherHub) newUpdate(key string, weather *model.Weather) *WeatherUpdate {
+	if h.record != nil {
+		h.record(weather)
+	}
+	seq := h.seq.Add(1)
+	return &WeatherUpdate{
+		ID:      h.epoch + "-" + strconv.FormatUint(seq, 10),
+		City:    key,
+		Weather: weather,
+		seq:     seq,
+	}
+}
+
+func (h *WeatherHub) unsubscribe(sub *Subscription) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	h.removeSubscriber(sub)
+}
+
+// removeSubscriber stops the pollers left without subscribers and frees a subscription of the client.
+func (h *WeatherHub) removeSubscriber(sub *Subscription) {
+	for key := range sub.names {
+		p, ok := h.cities[key]
+		if !ok {
+			continue
+		}
+		delete(p.subscribers, sub)
+		if len(p.subscribers) == 0 {
+			p.cancel()
+			delete(h.cities, key)
+			for _, alias := range p.aliases {
+				delete(h.aliases, alias)
+			}
+		}
+	}
+	h.releaseClient(sub.client)
+}
+
+// parseEventID returns the sequence of an update ID of this hub.
+func (h *WeatherHub) parseEventID(id string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != h.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	return n, err == nil
+}
+
+// weatherChanged compares what a subscriber sees change, the current conditions and alerts.
+// The conditions are compared by value, their optional fields are pointers.
+func weatherChanged(prev, next *model.Weather) bool {
+	return !reflect.DeepEqual(prev.Current, next.Current) || !reflect.DeepEqual(prev.Alerts, next.Alerts)
+}
+
+func cityKey(city string) string {
+	return strings.ToLower(strings.TrimSpace(city))
+}
+
+// Subscription receives the updates of its cities. Updates a subscriber has not taken yet are
+// replaced by newer ones of the same city, so a slow subscriber never holds up the pollers.
+type Subscription struct {
+	hub    *WeatherHub
+	client string
+	// names maps city keys to the cities as subscribed.
+	names map[string]string
+
+	mx        sync.Mutex
+	pending   map[string]WeatherUpdate
+	notify    chan struct{}
+	closeOnce sync.Once
+}
+
+// Notify receives when there are updates to take.
+func (s *Subscription) Notify() <-chan struct{} {
+	return s.notify
+}
+
+// Updates takes the pending updates, oldest first.
+func (s *Subscription) Updates() []WeatherUpdate {
+	s.mx.Lock()
+	updates := make([]WeatherUpdate, 0, len(s.pending))
+	for key, update := range s.pending {
+		update.City = s.names[key]
+		updates = append(updates, update)
+	}
+	clear(s.pending)
+	s.mx.Unlock()
+
+	sort.Slice(updates, func(i, j int) bool {
+		return updates[i].seq < updates[j].seq
+	})
+	return updates
+}
+
+// Close unsubscribes, the pollers of cities without subscribers stop.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.hub.unsubscribe(s)
+	})
+}
+
+func (s *Subscription) push(update WeatherUpdate) {
+	s.mx.Lock()
+	s.pending[update.City] = update
+	s.mx.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
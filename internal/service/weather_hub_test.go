@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeFetcher returns the temperature set for a city and counts the fetches.
+type fakeFetcher struct {
+	mx    sync.Mutex
+	temps map[string]float64
+	calls map[string]int
+}
+
+func newFakeFetcher() *fakeFetcher {
+	return &fakeFetcher{temps: make(map[string]float64), calls: make(map[string]int)}
+}
+
+// fetch knows every city but Atlantis. Cities resolve to the location of the same name, ignoring
+// anything after a comma.
+func (f *fakeFetcher) fetch(_ context.Context, city string) (*model.Weather, error) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.calls[city]++
+	if city == "atlantis" {
+		return nil, result.NotFoundErr("No matching location found.")
+	}
+	name, _, _ := strings.Cut(city, ",")
+	// Every fetch returns a new UV pointer, changes are told by value.
+	uv := 1.0
+	return &model.Weather{Location: model.Location{Name: name}, Current: model.Current{Temp: f.temps[city], UvIndex: &uv}}, nil
+}
+
+func (f *fakeFetcher) set(city string, temp float64) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.temps[city] = temp
+}
+
+func (f *fakeFetcher) callsOf(city string) int {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	return f.calls[city]
+}
+
+func subscribe(t *testing.T, hub *WeatherHub, cities []string, lastEventID string) *Subscription {
+	t.Helper()
+	sub, err := hub.Subscribe(context.Background(), t.Name(), cities, lastEventID)
+	if err != nil {
+		t.Fatalf("Expected no error subscribing, got %v", err)
+	}
+	return sub
+}
+
+func nextUpdates(t *testing.T, sub *Subscription) []WeatherUpdate {
+	t.Helper()
+	select {
+	case <-sub.Notify():
+		return sub.Updates()
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected an update")
+		return nil
+	}
+}
+
+func expectNoUpdates(t *testing.T, sub *Subscription) {
+	t.Helper()
+	select {
+	case <-sub.Notify():
+		t.Fatalf("Expected no update, got %+v", sub.Updates())
+	default:
+	}
+}
+
+func TestWeatherHub(t *testing.T) {
+	t.Run("Share poller between subscribers", func(t *testing.T) {
+		fetcher := newFakeFetcher()
+		hub := NewWeatherHub(WeatherHubConfig{Fetch: fetcher.fetch, Interval: time.Hour})
+		defer hub.Stop()
+
+		first := subscribe(t, hub, []string{"Belgrade"}, "")
+		defer first.Close()
+		updates := nextUpdates(t, first)
+		if len(updates) != 1 || updates[0].City != "Belgrade" {
+			t.Fatalf("Expected the current weather of Belgrade, got %+v", updates)
+		}
+		latest := updates[0]
+
+		// A new subscriber gets the latest weather without another fetch.
+		second := subscribe(t, hub, []string{" belgrade ", "BELGRADE"}, "")
+		defer second.Close()
+		updates = nextUpdates(t, second)
+		if len(updates) != 1 || updates[0].City != "belgrade" || updates[0].ID != latest.ID {
+			t.Fatalf("Expected the latest update of belgrade, got %+v", updates)
+		}
+		if calls := fetcher.callsOf("belgrade"); calls != 1 {
+			t.Fatalf("Expected a single fetch, got %d", calls)
+		}
+	})
+
+	t.Run("Notify changes only", func(t *testing.T) {
+		fetcher := newFakeFetcher()
+		fetcher.set("novi sad", 10)
+		var recorded atomic.Int32
+		record := func(*model.Weather) { recorded.Add(1) }
+		hub := NewWeatherHub(WeatherHubConfig{Fetch: fetcher.fetch, Record: record, Interval: 5 * time.Millisecond})
+		defer hub.Stop()
+
+		sub := subscribe(t, hub, []string{"Novi Sad"}, "")
+		defer sub.Close()
+		first := nextUpdates(t, sub)
+
+		for fetcher.callsOf("novi sad") < 3 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		expectNoUpdates(t, sub)
+
+		fetcher.set("novi sad", 12)
+		updates := nextUpdates(t, sub)
+		if len(updates) != 1 || updates[0].Weather.Current.Temp != 12 || updates[0].ID == first[0].ID {
+			t.Fatalf("Expected a new update with 12 degrees, got %+v", updates)
+		}
+		if n := recorded.Load(); n != 2 {
+			t.Fatalf("Expected the weather recorded once per update, got %d", n)
+		}
+	})
+
+	t.Run("Resume from Last-Event-ID", func(t *testing.T) {
+		fetcher := newFakeFetcher()
+		hub := NewWeatherHub(WeatherHubConfig{Fetch: fetcher.fetch, Interval: time.Hour})
+		defer hub.Stop()
+
+		sub := subscribe(t, hub, []string{"Nis"}, "")
+		defer sub.Close()
+		last := nextUpdates(t, sub)[0]
+
+		resumed := subscribe(t, hub, []string{"Nis"}, last.ID)
+		defer resumed.Close()
+		expectNoUpdates(t, resumed)
+
+		// IDs of another process or replica do not tell what the client missed.
+		foreign := subscribe(t, hub, []string{"Nis"}, "otherepoch-"+last.ID[len(hub.epoch)+1:])
+		defer foreign.Close()
+		if updates := nextUpdates(t, foreign); len(updates) != 1 || updates[0].ID != last.ID {
+			t.Fatalf("Expected the latest update, got %+v", updates)
+		}
+	})
+
+	t.Run("Stop polling without subscribers", func(t *testing.T) {
+		fetcher := newFakeFetcher()
+		hub := NewWeatherHub(WeatherHubConfig{Fetch: fetcher.fetch, Interval: time.Hour})
+		defer hub.Stop()
+
+		sub := subscribe(t, hub, []string{"Subotica", "Kragujevac"}, "")
+		nextUpdates(t, sub)
+		sub.Close()
+		sub.Close()
+
+		hub.mx.Lock()
+		defer hub.mx.Unlock()
+		if len(hub.cities) != 0 {
+			t.Fatalf("Expected no pollers, got %d", len(hub.cities))
+		}
+	})
+
+	t.Run("Share poller between names of a location", func(t *testing.T) {
+		fetcher := newFakeFetcher()
+		hub := NewWeatherHub(WeatherHubConfig{Fetch: fetcher.fetch, Interval: time.Hour})
+		defer hub.Stop()
+
+		sub := subscribe(t, hub, []string{"Zrenjanin", "Zrenjanin,RS"}, "")
+		defer sub.Close()
+		if updates := nextUpdates(t, sub); len(updates) != 1 || updates[0].City != "Zrenjanin" {
+			t.Fatalf("Expected a single update of Zrenjanin, got %+v", updates)
+		}
+
+		hub.mx.Lock()
+		defer hub.mx.Unlock()
+		if len(hub.cities) != 1 || len(hub.aliases) != 2 {
+			t.Fatalf("Expected 1 poller for 2 names, got %d pollers and %d names", len(hub.cities), len(hub.aliases))
+		}
+	})
+
+	t.Run("Reject unknown cities", func(t *testing.T) {
+		fetcher := newFakeFetcher()
+		hub := NewWeatherHub(WeatherHubConfig{Fetch: fetcher.fetch, Interval: time.Hour, MaxClientSubscriptions: 1})
+		defer hub.Stop()
+
+		_, err := hub.Subscribe(context.Background(), "client", []string{"Sombor", "Atlantis"}, "")
+		var resErr *result.Err
+		if !errors.As(err, &resErr) || resErr.Status != http.StatusBadRequest || resErr.Detail != "Unknown city: Atlantis" {
+			t.Fatalf("Expected a validation error for Atlantis, got %v", err)
+		}
+
+		hub.mx.Lock()
+		pollers := len(hub.cities)
+		hub.mx.Unlock()
+		if pollers != 0 {
+			t.Fatalf("Expected no pollers, got %d", pollers)
+		}
+		// The failed subscription does not count towards the client's subscriptions.
+		sub, err := hub.Subscribe(context.Background(), "client", []string{"Sombor"}, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		sub.Close()
+	})
+
+	t.Run("Cap pollers", func(t *testing.T) {
+		fetcher := newFakeFetcher()
+		hub := NewWeatherHub(WeatherHubConfig{Fetch: fetcher.fetch, Interval: time.Hour, MaxPolledCities: 2})
+		defer hub.Stop()
+
+		sub := subscribe(t, hub, []string{"Pancevo", "Smederevo"}, "")
+		_, err := hub.Subscribe(context.Background(), "other", []string{"Pancevo", "Valjevo"}, "")
+		var resErr *result.Err
+		if !errors.As(err, &resErr) || resErr.Status != http.StatusServiceUnavailable || resErr.RetryAfter != time.Hour {
+			t.Fatalf("Expected a service unavailable error, got %v", err)
+		}
+
+		hub.mx.Lock()
+		subscribers := len(hub.cities[(model.Location{Name: "pancevo"}).Key()].subscribers)
+		hub.mx.Unlock()
+		if subscribers != 1 {
+			t.Fatalf("Expected the refused subscription removed from Pancevo, got %d subscribers", subscribers)
+		}
+
+		sub.Close()
+		sub = subscribe(t, hub, []string{"Valjevo"}, "")
+		sub.Close()
+	})
+
+	t.Run("Cap subscriptions of a client", func(t *testing.T) {
+		fetcher := newFakeFetcher()
+		hub := NewWeatherHub(WeatherHubConfig{Fetch: fetcher.fetch, Interval: time.Hour, MaxClientSubscriptions: 2})
+		defer hub.Stop()
+
+		first := subscribe(t, hub, []string{"Cacak"}, "")
+		second := subscribe(t, hub, []string{"Cacak"}, "")
+		defer second.Close()
+		_, err := hub.Subscribe(context.Background(), t.Name(), []string{"Cacak"}, "")
+		var resErr *result.Err
+		if !errors.As(err, &resErr) || resErr.Status != http.StatusTooManyRequests {
+			t.Fatalf("Expected too many requests, got %v", err)
+		}
+		other, err := hub.Subscribe(context.Background(), "other", []string{"Cacak"}, "")
+		if err != nil {
+			t.Fatalf("Expected other clients to subscribe, got %v", err)
+		}
+		other.Close()
+
+		first.Close()
+		third := subscribe(t, hub, []string{"Cacak"}, "")
+		third.Close()
+	})
+}
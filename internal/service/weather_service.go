@@ -2,83 +2,446 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/DjordjeVuckovic/weather-radar/internal/client"
 	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
 	"github.com/DjordjeVuckovic/weather-radar/internal/model"
 	"github.com/DjordjeVuckovic/weather-radar/internal/storage"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/google/uuid"
+	"log/slog"
+	"slices"
 	"sync"
 	"time"
 )
 
-const timeout = 1 * time.Second
+const (
+	// The timeouts of requests leave room for falling back to another provider after an attempt
+	// timed out, providerTimeout or forecastProviderTimeout for the larger forecast responses.
+	timeout                 = 3 * time.Second
+	providerTimeout         = 1 * time.Second
+	forecastTimeout         = 3 * time.Second
+	forecastProviderTimeout = 1500 * time.Millisecond
+	alertsTimeout           = 2 * time.Second
+	historyTimeout          = 3 * time.Second
+	searchTimeout           = 2 * time.Second
+
+	// maxSearchResults bounds the candidates returned, enough for an autocomplete list.
+	maxSearchResults = 5
+)
 
 type WeatherService struct {
-	weatherClient client.WeatherClient
-	astroClient   client.AstroClient
-	storage       storage.WeatherStorage
+	providers   *client.ProviderRegistry
+	astroClient client.AstroClient
+	storage     storage.WeatherStorage
+	snapshots   *snapshotRecorder
 }
 
-func NewWeatherService(wCl client.WeatherClient, aCl client.AstroClient, st storage.WeatherStorage) *WeatherService {
+// NewWeatherService starts recording served weather in the background, Stop stops it.
+func NewWeatherService(providers *client.ProviderRegistry, aCl client.AstroClient, st storage.WeatherStorage) *WeatherService {
 	return &WeatherService{
-		weatherClient: wCl,
-		astroClient:   aCl,
-		storage:       st,
+		providers:   providers,
+		astroClient: aCl,
+		storage:     st,
+		snapshots:   newSnapshotRecorder(st, snapshotQueueSize),
 	}
 }
 
-func (w *WeatherService) GetWeatherByCity(ctx context.Context, city string) (*model.Weather, error) {
+// Stop saves the served weather recorded so far. Weather served afterwards is not recorded.
+func (w *WeatherService) Stop() {
+	w.snapshots.stop()
+}
+
+func (w *WeatherService) GetWeatherByLocation(ctx context.Context, q model.LocationQuery, opts model.WeatherOptions) (*model.Weather, error) {
 	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	weatherCh := make(chan *dto.WeatherByCity, 1)
-	astroCh := make(chan *dto.AstroByCity, 1)
-	errCh := make(chan error, 1)
+	weather, alertsData, err := fetchConcurrently(
+		timeoutCtx,
+		func(ctx context.Context) (*model.Weather, error) {
+			if opts.Blend {
+				return w.fetchBlendedWeather(ctx, q, opts)
+			}
+			weatherData, astroData, provider, err := w.fetchWeatherData(ctx, q, opts)
+			if err != nil {
+				return nil, err
+			}
+			weather := model.NewWeatherFromDto(weatherData, astroData)
+			weather.Meta.Provider = provider
+			return weather, nil
+		},
+		func(ctx context.Context) (*dto.AlertsByCity, error) {
+			if !opts.Alerts {
+				return nil, nil
+			}
+			alerts, _, err := withFallback(ctx, w.providers, providerTimeout, func(ctx context.Context, cl client.WeatherClient) (*dto.AlertsByCity, error) {
+				return cl.GetAlertsByLocation(ctx, q)
+			})
+			return alerts, err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if alertsData != nil {
+		weather.Alerts = model.NewAlertsFromDto(alertsData, time.Now())
+	}
+	return weather, nil
+}
 
-	go func() {
-		wth, err := w.weatherClient.GetByCity(timeoutCtx, city)
+// RecordServed records weather served to a client for the feedback report, without waiting for
+// storage. It must be called before converting the weather to other units.
+func (w *WeatherService) RecordServed(weather *model.Weather) {
+	w.snapshots.record(model.NewWeatherSnapshot(weather, time.Now()))
+}
+
+// fetchWeatherData fetches weather and astronomy data concurrently and returns the name of the
+// weather provider that answered. OpenWeather cannot resolve IP queries, so for those the
+// astronomy data is fetched afterwards by the resolved coordinates.
+func (w *WeatherService) fetchWeatherData(ctx context.Context, q model.LocationQuery, opts model.WeatherOptions) (*dto.WeatherByCity, *dto.AstroByCity, string, error) {
+	if q.Kind == model.LocationIP {
+		weatherData, provider, err := w.getByLocation(ctx, q, opts)
 		if err != nil {
-			errCh <- err
-			return
+			return nil, nil, "", err
 		}
-		select {
-		case weatherCh <- wth:
-		case <-timeoutCtx.Done():
-			return
+		astroData := w.getAstro(ctx, coordinatesQuery(weatherData.Location))
+		return weatherData, astroData, provider, nil
+	}
+
+	// provider is only read after fetchConcurrently received the weather data, so it is set by then.
+	var provider string
+	weatherData, astroData, err := fetchConcurrently(
+		ctx,
+		func(ctx context.Context) (*dto.WeatherByCity, error) {
+			weatherData, p, err := w.getByLocation(ctx, q, opts)
+			provider = p
+			return weatherData, err
+		},
+		func(ctx context.Context) (*dto.AstroByCity, error) {
+			return w.getAstro(ctx, q), nil
+		},
+	)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return weatherData, astroData, provider, nil
+}
+
+type providerWeather struct {
+	provider string
+	data     *dto.WeatherByCity
+	err      error
+}
+
+// fetchBlendedWeather asks all providers concurrently and blends the values of those that answered.
+// Location, condition and other non numeric fields come from the highest priority provider.
+// OpenWeather astronomy data adds wind and cloud cover, unless OpenWeather is a provider itself.
+func (w *WeatherService) fetchBlendedWeather(ctx context.Context, q model.LocationQuery, opts model.WeatherOptions) (*model.Weather, error) {
+	var (
+		results   []providerWeather
+		astroData *dto.AstroByCity
+		err       error
+	)
+	if q.Kind == model.LocationIP {
+		results = w.fetchAllProviders(ctx, q, opts)
+		if data := firstAnswered(results); data != nil {
+			astroData = w.getAstro(ctx, coordinatesQuery(data.Location))
+		}
+	} else {
+		results, astroData, err = fetchConcurrently(
+			ctx,
+			func(ctx context.Context) ([]providerWeather, error) {
+				return w.fetchAllProviders(ctx, q, opts), nil
+			},
+			func(ctx context.Context) (*dto.AstroByCity, error) {
+				return w.getAstro(ctx, q), nil
+			},
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		sources   []model.BlendSource
+		providers []string
+		firstErr  error
+		base      *dto.WeatherByCity
+	)
+	for _, r := range results {
+		if r.err != nil {
+			slog.Warn("Weather provider left out of blend", slog.String("provider", r.provider), slog.String("error", r.err.Error()))
+			if firstErr == nil && !errors.Is(r.err, client.ErrUnsupported) {
+				firstErr = r.err
+			}
+			continue
+		}
+		if base == nil {
+			base = r.data
+		}
+		sources = append(sources, model.NewBlendSourceFromDto(r.provider, r.data))
+		providers = append(providers, r.provider)
+	}
+	if base == nil {
+		if firstErr == nil {
+			firstErr = result.InternalServerErr("No weather provider can serve " + q.String())
+		}
+		return nil, firstErr
+	}
+	if astroData != nil && !slices.Contains(providers, client.ProviderOpenWeather) {
+		sources = append(sources, model.NewBlendSourceFromAstro(client.ProviderOpenWeather, astroData))
+	}
+
+	weather := model.NewWeatherFromDto(base, astroData)
+	weather.ApplyBlend(sources)
+	weather.Meta.Providers = providers
+	return weather, nil
+}
+
+// fetchAllProviders asks every provider concurrently and returns the results in priority order.
+func (w *WeatherService) fetchAllProviders(ctx context.Context, q model.LocationQuery, opts model.WeatherOptions) []providerWeather {
+	providers := w.providers.Providers()
+	results := make([]providerWeather, len(providers))
+
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func(i int, p client.Provider) {
+			defer wg.Done()
+			attemptCtx, cancel := context.WithTimeout(ctx, providerTimeout)
+			defer cancel()
+			data, err := p.Client.GetByLocation(attemptCtx, q, opts)
+			results[i] = providerWeather{provider: p.Name, data: data, err: err}
+		}(i, p)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func firstAnswered(results []providerWeather) *dto.WeatherByCity {
+	for _, r := range results {
+		if r.err == nil {
+			return r.data
+		}
+	}
+	return nil
+}
+
+func (w *WeatherService) getByLocation(ctx context.Context, q model.LocationQuery, opts model.WeatherOptions) (*dto.WeatherByCity, string, error) {
+	return withFallback(ctx, w.providers, providerTimeout, func(ctx context.Context, cl client.WeatherClient) (*dto.WeatherByCity, error) {
+		return cl.GetByLocation(ctx, q, opts)
+	})
+}
+
+func (w *WeatherService) GetForecastByLocation(ctx context.Context, q model.LocationQuery, days int, lang model.Language) (*model.Forecast, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, forecastTimeout)
+	defer cancel()
+
+	forecastData, astroData, err := w.fetchForecastData(timeoutCtx, q, days, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := model.NewForecastFromDto(forecastData, astroData)
+	return forecast, nil
+}
 
+func (w *WeatherService) fetchForecastData(ctx context.Context, q model.LocationQuery, days int, lang model.Language) (*dto.ForecastByCity, *dto.AstroForecastByCity, error) {
+	if q.Kind == model.LocationIP {
+		forecastData, err := w.getForecastByLocation(ctx, q, days, lang)
+		if err != nil {
+			return nil, nil, err
 		}
+		astroData := w.getAstroForecast(ctx, coordinatesQuery(forecastData.Location), days)
+		return forecastData, astroData, nil
+	}
+
+	return fetchConcurrently(
+		ctx,
+		func(ctx context.Context) (*dto.ForecastByCity, error) {
+			return w.getForecastByLocation(ctx, q, days, lang)
+		},
+		func(ctx context.Context) (*dto.AstroForecastByCity, error) {
+			return w.getAstroForecast(ctx, q, days), nil
+		},
+	)
+}
+
+// getAstro fetches astronomy data, which only adds to the weather. A failure or a fetch slower than
+// providerTimeout is logged and the weather served without it, so an OpenWeather outage does not
+// defeat the fallback between weather providers.
+func (w *WeatherService) getAstro(ctx context.Context, q model.LocationQuery) *dto.AstroByCity {
+	return bestEffort(ctx, "astronomy data", q, func(ctx context.Context) (*dto.AstroByCity, error) {
+		return w.astroClient.GetByLocation(ctx, q)
+	})
+}
+
+// getAstroForecast fetches the astronomy data of a forecast like getAstro.
+func (w *WeatherService) getAstroForecast(ctx context.Context, q model.LocationQuery, days int) *dto.AstroForecastByCity {
+	return bestEffort(ctx, "astronomy forecast", q, func(ctx context.Context) (*dto.AstroForecastByCity, error) {
+		return w.astroClient.GetForecastByLocation(ctx, q, days)
+	})
+}
+
+func bestEffort[T any](ctx context.Context, what string, q model.LocationQuery, fetch func(ctx context.Context) (*T, error)) *T {
+	attemptCtx, cancel := context.WithTimeout(ctx, providerTimeout)
+	defer cancel()
+	v, err := fetch(attemptCtx)
+	if err != nil {
+		slog.Warn("Serving weather without "+what, slog.String("location", q.String()), slog.String("error", err.Error()))
+		return nil
+	}
+	return v
+}
+
+func (w *WeatherService) getForecastByLocation(ctx context.Context, q model.LocationQuery, days int, lang model.Language) (*dto.ForecastByCity, error) {
+	forecast, _, err := withFallback(ctx, w.providers, forecastProviderTimeout, func(ctx context.Context, cl client.WeatherClient) (*dto.ForecastByCity, error) {
+		return cl.GetForecastByLocation(ctx, q, days, lang)
+	})
+	return forecast, err
+}
+
+// GetHistoryByLocation fetches the recorded weather for each of the given dates concurrently.
+func (w *WeatherService) GetHistoryByLocation(ctx context.Context, q model.LocationQuery, dates []time.Time, lang model.Language) (*model.WeatherHistory, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, historyTimeout)
+	defer cancel()
+
+	var (
+		resultCh = make(chan *dto.HistoryByCity, len(dates))
+		errCh    = make(chan error, len(dates))
+		wg       sync.WaitGroup
+	)
+
+	for _, date := range dates {
+		wg.Add(1)
+		go func(date time.Time) {
+			defer wg.Done()
+			history, _, err := withFallback(timeoutCtx, w.providers, forecastProviderTimeout, func(ctx context.Context, cl client.WeatherClient) (*dto.HistoryByCity, error) {
+				return cl.GetHistoryByLocation(ctx, q, date, lang)
+			})
+			if err != nil {
+				errCh <- err
+				return
+			}
+			resultCh <- history
+		}(date)
+	}
+
+	go func() {
+		wg.Wait()
+		close(resultCh)
 	}()
 
+	histories := make([]*dto.HistoryByCity, 0, len(dates))
+	for {
+		select {
+		case history, ok := <-resultCh:
+			if !ok {
+				select {
+				case err := <-errCh:
+					return nil, err
+				default:
+				}
+				return model.NewWeatherHistoryFromDto(histories), nil
+			}
+			histories = append(histories, history)
+		case err := <-errCh:
+			return nil, err
+		case <-timeoutCtx.Done():
+			return nil, context.DeadlineExceeded
+		}
+	}
+}
+
+func (w *WeatherService) GetAlertsByLocation(ctx context.Context, q model.LocationQuery) (*model.WeatherAlerts, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, alertsTimeout)
+	defer cancel()
+
+	alertsData, _, err := withFallback(timeoutCtx, w.providers, providerTimeout, func(ctx context.Context, cl client.WeatherClient) (*dto.AlertsByCity, error) {
+		return cl.GetAlertsByLocation(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewWeatherAlertsFromDto(alertsData, time.Now()), nil
+}
+
+// SearchLocations returns candidate locations matching the query with a single provider call,
+// so searching on every keystroke stays cheap.
+func (w *WeatherService) SearchLocations(ctx context.Context, query string) ([]model.LocationCandidate, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, searchTimeout)
+	defer cancel()
+
+	locations, _, err := withFallback(timeoutCtx, w.providers, providerTimeout, func(ctx context.Context, cl client.WeatherClient) ([]dto.SearchLocation, error) {
+		return cl.SearchLocations(ctx, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(locations) > maxSearchResults {
+		locations = locations[:maxSearchResults]
+	}
+
+	candidates := make([]model.LocationCandidate, len(locations))
+	for i, location := range locations {
+		candidates[i] = model.NewLocationCandidateFromDto(location)
+	}
+	return candidates, nil
+}
+
+func coordinatesQuery(location dto.Location) model.LocationQuery {
+	return model.NewCoordinatesQuery(location.Lat, location.Lon)
+}
+
+// fetchConcurrently runs both fetches in parallel and returns on the first error or timeout.
+func fetchConcurrently[A, B any](
+	ctx context.Context,
+	fetchA func(ctx context.Context) (A, error),
+	fetchB func(ctx context.Context) (B, error),
+) (A, B, error) {
+	var (
+		a     A
+		b     B
+		zeroA A
+		zeroB B
+	)
+	aCh := make(chan A, 1)
+	bCh := make(chan B, 1)
+	errCh := make(chan error, 2)
+
 	go func() {
-		a, err := w.astroClient.GetByCity(timeoutCtx, city)
+		v, err := fetchA(ctx)
 		if err != nil {
 			errCh <- err
 			return
 		}
-		select {
-		case astroCh <- a:
-		case <-timeoutCtx.Done():
-			return
+		aCh <- v
+	}()
 
+	go func() {
+		v, err := fetchB(ctx)
+		if err != nil {
+			errCh <- err
+			return
 		}
+		bCh <- v
 	}()
 
-	var weatherData *dto.WeatherByCity
-	var astroData *dto.AstroByCity
 	for i := 0; i < 2; i++ {
 		select {
-		case w := <-weatherCh:
-			weatherData = w
-		case a := <-astroCh:
-			astroData = a
+		case a = <-aCh:
+		case b = <-bCh:
 		case err := <-errCh:
-			return nil, err
-		case <-timeoutCtx.Done():
-			return nil, context.DeadlineExceeded
+			return zeroA, zeroB, err
+		case <-ctx.Done():
+			return zeroA, zeroB, context.DeadlineExceeded
 		}
 	}
 
-	weather := model.NewWeatherFromDto(weatherData, astroData)
-	return weather, nil
+	return a, b, nil
 }
 
 type AggregatedWeather struct {
@@ -86,6 +449,11 @@ type AggregatedWeather struct {
 	City    string
 }
 
+// GetWeatherByCity returns the current weather of a city, see WeatherFetcher.
+func (w *WeatherService) GetWeatherByCity(ctx context.Context, city string) (*model.Weather, error) {
+	return w.GetWeatherByLocation(ctx, model.NewCityQuery(city), model.WeatherOptions{})
+}
+
 func (w *WeatherService) GetWeatherByCites(ctx context.Context, cities []string) ([]AggregatedWeather, error) {
 	var (
 		resultCh = make(chan AggregatedWeather, len(cities))
@@ -97,7 +465,7 @@ func (w *WeatherService) GetWeatherByCites(ctx context.Context, cities []string)
 		wg.Add(1)
 		go func(city string) {
 			defer wg.Done()
-			weather, err := w.GetWeatherByCity(ctx, city)
+			weather, err := w.GetWeatherByLocation(ctx, model.NewCityQuery(city), model.WeatherOptions{})
 			if err != nil {
 				errCh <- err
 				return
@@ -144,7 +512,7 @@ func (w *WeatherService) GetWeatherStreamByCities(ctx context.Context, cities []
 			wg.Add(1)
 			go func(city string) {
 				defer wg.Done()
-				weather, err := w.GetWeatherByCity(ctx, city)
+				weather, err := w.GetWeatherByLocation(ctx, model.NewCityQuery(city), model.WeatherOptions{})
 				if err != nil {
 					select {
 					case errCh <- err:
@@ -166,10 +534,88 @@ func (w *WeatherService) GetWeatherStreamByCities(ctx context.Context, cities []
 	return resultCh, errCh
 }
 
-func (w *WeatherService) SubmitFeedback(ctx context.Context, feedback *dto.WeatherFeedbackReq) error {
-	fb := model.NewFeedbackFromDto(feedback)
+// SubmitFeedback validates and stores the feedback, recording the authenticated subject that submitted it.
+func (w *WeatherService) SubmitFeedback(ctx context.Context, subject string, feedback *dto.WeatherFeedbackReq) (*model.Feedback, error) {
+	if err := w.validateFeedbackReq(ctx, feedback); err != nil {
+		return nil, err
+	}
+	fb := model.NewFeedbackFromDto(feedback, subject)
 	if err := w.storage.AddFeedback(ctx, fb); err != nil {
-		return err
+		return nil, err
+	}
+	return fb, nil
+}
+
+func (w *WeatherService) GetFeedback(ctx context.Context, id uuid.UUID) (*model.Feedback, error) {
+	fb, err := w.storage.GetFeedback(ctx, id)
+	if err != nil {
+		return nil, feedbackErr(err, id)
+	}
+	return fb, nil
+}
+
+func (w *WeatherService) ListFeedback(ctx context.Context, filter model.FeedbackFilter) (*model.FeedbackPage, error) {
+	feedbacks, total, err := w.storage.ListFeedback(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if feedbacks == nil {
+		feedbacks = []*model.Feedback{}
+	}
+	return &model.FeedbackPage{
+		Items:  feedbacks,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+func (w *WeatherService) UpdateFeedback(ctx context.Context, id uuid.UUID, feedback *dto.WeatherFeedbackReq) (*model.Feedback, error) {
+	if err := w.validateFeedbackReq(ctx, feedback); err != nil {
+		return nil, err
+	}
+	fb, err := w.storage.GetFeedback(ctx, id)
+	if err != nil {
+		return nil, feedbackErr(err, id)
+	}
+	fb.Update(feedback)
+	if err := w.storage.UpdateFeedback(ctx, fb); err != nil {
+		return nil, feedbackErr(err, id)
+	}
+	return fb, nil
+}
+
+func (w *WeatherService) DeleteFeedback(ctx context.Context, id uuid.UUID) error {
+	if err := w.storage.DeleteFeedback(ctx, id); err != nil {
+		return feedbackErr(err, id)
 	}
 	return nil
 }
+
+// ExportFeedback calls fn for all feedback matching the filter, oldest first, without loading it
+// all at once. Pagination of the filter is ignored.
+func (w *WeatherService) ExportFeedback(ctx context.Context, filter model.FeedbackFilter, fn func(fb *model.Feedback) error) error {
+	return w.storage.ForEachFeedback(ctx, filter, fn)
+}
+
+// GetFeedbackReport compares feedback matching the filter with the weather served on the same dates.
+// Pagination of the filter is ignored.
+func (w *WeatherService) GetFeedbackReport(ctx context.Context, filter model.FeedbackFilter) (*model.FeedbackReport, error) {
+	filter.Limit, filter.Offset = 0, 0
+	feedbacks, _, err := w.storage.ListFeedback(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := w.storage.ListWeatherSnapshots(ctx, model.SnapshotFilter{City: filter.City, From: filter.From, To: filter.To})
+	if err != nil {
+		return nil, err
+	}
+	return model.NewFeedbackReport(filter, feedbacks, snapshots), nil
+}
+
+func feedbackErr(err error, id uuid.UUID) error {
+	if errors.Is(err, storage.ErrNotFound) {
+		return result.NotFoundErr("Feedback not found: " + id.String())
+	}
+	return err
+}
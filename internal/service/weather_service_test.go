@@ -5,7 +5,12 @@ import (
 	"errors"
 	"github.com/DjordjeVuckovic/weather-radar/internal/client"
 	"github.com/DjordjeVuckovic/weather-radar/internal/dto"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
 	"github.com/DjordjeVuckovic/weather-radar/internal/storage"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/google/uuid"
+	"net/http"
+	"slices"
 	"testing"
 	"time"
 )
@@ -14,7 +19,7 @@ func TestGetWeatherByCity_Success(t *testing.T) {
 	weatherMock := client.NewMockWeatherClient(nil, 0)
 	astroMock := client.NewMockAstroClient(nil)
 
-	service := NewWeatherService(weatherMock, astroMock, storage.NewWeatherInMemStorage())
+	service := NewWeatherService(newProviders(weatherMock), astroMock, storage.NewWeatherInMemStorage())
 	ctx := context.Background()
 
 	city := "London"
@@ -31,12 +36,88 @@ func TestGetWeatherByCity_Success(t *testing.T) {
 	}
 }
 
+func TestGetFeedbackReport_ComparesWithServedWeather(t *testing.T) {
+	weatherMock := newCityMock()
+	weatherMock.Response.Location.Localtime = "2024-10-27 12:00"
+	weatherMock.Response.Current.TempC = 10
+	weatherMock.Response.Current.Condition.Code = 1183
+	service := NewWeatherService(newProviders(weatherMock), client.NewMockAstroClient(nil), storage.NewWeatherInMemStorage())
+	ctx := context.Background()
+
+	weather, err := service.GetWeatherByLocation(ctx, model.NewCityQuery("London"), model.WeatherOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	service.RecordServed(weather)
+	service.Stop()
+
+	reportedTemp := 7.0
+	_, err = service.SubmitFeedback(ctx, "ana", &dto.WeatherFeedbackReq{
+		City: "London", Date: "2024-10-27", Message: "Colder", Rating: 3, ObservedCondition: "rain", ReportedTemp: &reportedTemp,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	report, err := service.GetFeedbackReport(ctx, model.FeedbackFilter{City: "London", Limit: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Cities) != 1 || len(report.Cities[0].Providers) != 1 {
+		t.Fatalf("Expected a London report with one provider, got %+v", report)
+	}
+	accuracy := report.Cities[0].Providers[0]
+	if accuracy.Provider != "mock" || accuracy.Temp.Mean != -3 || accuracy.Condition.Mismatches != 0 {
+		t.Fatalf("Unexpected provider accuracy %+v", accuracy)
+	}
+}
+
+func TestGetWeatherByLocation_IPResolvesAstroByCoordinates(t *testing.T) {
+	weatherMock := client.NewMockWeatherClient(nil, 0)
+	astroMock := client.NewMockAstroClient(nil)
+
+	service := NewWeatherService(newProviders(weatherMock), astroMock, storage.NewWeatherInMemStorage())
+
+	weather, err := service.GetWeatherByLocation(context.Background(), model.NewIPQuery("81.2.69.142"), model.WeatherOptions{})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if weather.Location.Name != "London" {
+		t.Fatalf("Expected weather data with City: London got %+v", weather)
+	}
+	if astroMock.LastQuery.Kind != model.LocationCoordinates {
+		t.Fatalf("Expected astro client to be queried by coordinates, got %s", astroMock.LastQuery.Kind)
+	}
+}
+
+func TestGetWeatherByLocation_WithAlerts(t *testing.T) {
+	weatherMock := client.NewMockWeatherClient(nil, 0)
+	expires := time.Now().Add(time.Hour).Format(time.RFC3339)
+	weatherMock.AlertsResponse.Alerts.Alert = []dto.Alert{
+		{Event: "Wind warning", Areas: "London", Expires: expires},
+		{Event: "Wind warning", Areas: "London", Expires: expires},
+	}
+	astroMock := client.NewMockAstroClient(nil)
+
+	service := NewWeatherService(newProviders(weatherMock), astroMock, storage.NewWeatherInMemStorage())
+
+	weather, err := service.GetWeatherByLocation(context.Background(), model.NewCityQuery("London"), model.WeatherOptions{Alerts: true})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(weather.Alerts) != 1 {
+		t.Fatalf("Expected 1 deduplicated alert, got %d", len(weather.Alerts))
+	}
+}
+
 func TestGetWeatherByCity_WeatherClientError(t *testing.T) {
 	errMsg := "no matching location found"
 	weatherMock := client.NewMockWeatherClient(errors.New(errMsg), 0)
 	astroMock := client.NewMockAstroClient(errors.New(errMsg))
 
-	service := NewWeatherService(weatherMock, astroMock, storage.NewWeatherInMemStorage())
+	service := NewWeatherService(newProviders(weatherMock), astroMock, storage.NewWeatherInMemStorage())
 	ctx := context.Background()
 
 	weather, err := service.GetWeatherByCity(ctx, "London")
@@ -53,7 +134,7 @@ func TestGetWeatherByCity_Timeout(t *testing.T) {
 	weatherMock := client.NewMockWeatherClient(nil, 10*time.Millisecond)
 	astroMock := client.NewMockAstroClient(nil)
 
-	service := NewWeatherService(weatherMock, astroMock, storage.NewWeatherInMemStorage())
+	service := NewWeatherService(newProviders(weatherMock), astroMock, storage.NewWeatherInMemStorage())
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
 	defer cancel()
 
@@ -67,17 +148,360 @@ func TestGetWeatherByCity_Timeout(t *testing.T) {
 	}
 }
 
+func TestGetWeatherByLocation_FallsBackOnServerError(t *testing.T) {
+	failing := client.NewMockWeatherClient(result.InternalServerErr("Failed to fetch weather data"), 0)
+	backup := client.NewMockWeatherClient(nil, 0)
+	astroMock := client.NewMockAstroClient(nil)
+
+	providers := client.NewProviderRegistry()
+	providers.Register("backup", 2, backup)
+	providers.Register("primary", 1, failing)
+	service := NewWeatherService(providers, astroMock, storage.NewWeatherInMemStorage())
+
+	weather, err := service.GetWeatherByLocation(context.Background(), model.NewCityQuery("London"), model.WeatherOptions{})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if weather.Meta.Provider != "backup" {
+		t.Fatalf("Expected backup provider to answer, got %q", weather.Meta.Provider)
+	}
+}
+
+func TestGetWeatherByLocation_FallsBackOnProviderTimeout(t *testing.T) {
+	slow := client.NewMockWeatherClient(nil, 2*providerTimeout)
+	backup := client.NewMockWeatherClient(nil, 0)
+	astroMock := client.NewMockAstroClient(nil)
+
+	providers := client.NewProviderRegistry()
+	providers.Register("slow", 1, slow)
+	providers.Register("backup", 2, backup)
+	service := NewWeatherService(providers, astroMock, storage.NewWeatherInMemStorage())
+
+	weather, err := service.GetWeatherByLocation(context.Background(), model.NewCityQuery("London"), model.WeatherOptions{})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if weather.Meta.Provider != "backup" {
+		t.Fatalf("Expected backup provider to answer, got %q", weather.Meta.Provider)
+	}
+}
+
+func TestGetForecastByLocation_FallsBackOnProviderTimeout(t *testing.T) {
+	slow := client.NewMockWeatherClient(nil, forecastTimeout)
+	backup := client.NewMockWeatherClient(nil, 0)
+	backup.ForecastResponse.Location.Name = "Backup London"
+
+	providers := client.NewProviderRegistry()
+	providers.Register("slow", 1, slow)
+	providers.Register("backup", 2, backup)
+	service := NewWeatherService(providers, client.NewMockAstroClient(nil), storage.NewWeatherInMemStorage())
+
+	forecast, err := service.GetForecastByLocation(context.Background(), model.NewCityQuery("London"), 2, "")
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if forecast.Location.Name != "Backup London" {
+		t.Fatalf("Expected backup provider to answer, got %q", forecast.Location.Name)
+	}
+}
+
+func TestGetWeatherByLocation_NoFallbackOnNotFound(t *testing.T) {
+	notFound := client.NewMockWeatherClient(result.NotFoundErr("No matching location found."), 0)
+	backup := client.NewMockWeatherClient(nil, 0)
+	astroMock := client.NewMockAstroClient(nil)
+
+	providers := client.NewProviderRegistry()
+	providers.Register("primary", 1, notFound)
+	providers.Register("backup", 2, backup)
+	service := NewWeatherService(providers, astroMock, storage.NewWeatherInMemStorage())
+
+	_, err := service.GetWeatherByLocation(context.Background(), model.NewCityQuery("Atlantis"), model.WeatherOptions{})
+
+	var resErr *result.Err
+	if !errors.As(err, &resErr) || resErr.Status != http.StatusNotFound {
+		t.Fatalf("Expected not found error, got %v", err)
+	}
+}
+
+func TestGetWeatherByLocation_Blend(t *testing.T) {
+	primary := client.NewMockWeatherClient(nil, 0)
+	primary.Response.Current.TempC = 10
+	secondary := client.NewMockWeatherClient(nil, 0)
+	secondary.Response.Current.TempC = 12
+	unsupported := client.NewMockWeatherClient(client.ErrUnsupported, 0)
+	astroMock := client.NewMockAstroClient(nil)
+	astroMock.Response.Wind.Speed = 5
+
+	providers := client.NewProviderRegistry()
+	providers.Register("primary", 1, primary)
+	providers.Register("secondary", 2, secondary)
+	providers.Register("unsupported", 3, unsupported)
+	service := NewWeatherService(providers, astroMock, storage.NewWeatherInMemStorage())
+
+	weather, err := service.GetWeatherByLocation(context.Background(), model.NewCityQuery("London"), model.WeatherOptions{Blend: true})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if weather.Current.Temp != 11 {
+		t.Errorf("Expected blended temperature 11, got %v", weather.Current.Temp)
+	}
+	if len(weather.Meta.Providers) != 2 || weather.Meta.Providers[0] != "primary" {
+		t.Errorf("Expected primary and secondary providers, got %v", weather.Meta.Providers)
+	}
+	if wind := weather.Blend.Fields[model.FieldWindSpeed]; wind.Samples != 3 {
+		t.Errorf("Expected astro wind to be blended as third sample, got %+v", wind)
+	}
+}
+
+func TestGetWeatherByLocation_BlendDefaultProvidersDisagree(t *testing.T) {
+	weatherApi := client.NewMockWeatherClient(nil, 0)
+	weatherApi.Response.Current.TempC = 10
+	openWeather := client.NewMockWeatherClient(nil, 0)
+	openWeather.Response.Current.TempC = 15
+
+	providers := client.NewProviderRegistry()
+	providers.Register(client.ProviderWeatherAPI, 0, weatherApi)
+	providers.Register(client.ProviderOpenWeather, 1, openWeather)
+	service := NewWeatherService(providers, client.NewMockAstroClient(nil), storage.NewWeatherInMemStorage())
+
+	weather, err := service.GetWeatherByLocation(context.Background(), model.NewCityQuery("London"), model.WeatherOptions{Blend: true})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	temp := weather.Blend.Fields[model.FieldTemp]
+	if temp.Samples != 2 || !temp.Disagree {
+		t.Errorf("Expected the two default providers to disagree on temperature, got %+v", temp)
+	}
+	if !slices.Contains(weather.Blend.Disagreements, model.FieldTemp) {
+		t.Errorf("Expected a temperature disagreement, got %v", weather.Blend.Disagreements)
+	}
+}
+
+func TestGetWeatherByLocation_BlendAllFail(t *testing.T) {
+	notFound := client.NewMockWeatherClient(result.NotFoundErr("No matching location found."), 0)
+	astroMock := client.NewMockAstroClient(nil)
+
+	service := NewWeatherService(newProviders(notFound), astroMock, storage.NewWeatherInMemStorage())
+
+	_, err := service.GetWeatherByLocation(context.Background(), model.NewCityQuery("Atlantis"), model.WeatherOptions{Blend: true})
+
+	var resErr *result.Err
+	if !errors.As(err, &resErr) || resErr.Status != http.StatusNotFound {
+		t.Fatalf("Expected not found error, got %v", err)
+	}
+}
+
 func TestWeatherService_SubmitFeedback(t *testing.T) {
 	st := storage.NewWeatherInMemStorage()
-	service := NewWeatherService(nil, nil, st)
+	service := NewWeatherService(newProviders(newCityMock()), nil, st)
 
 	feedback := &dto.WeatherFeedbackReq{
-		City:    "London",
+		City:    "london",
 		Date:    "2024-10-27",
 		Message: "It was raining",
 	}
-	err := service.SubmitFeedback(context.Background(), feedback)
+	fb, err := service.SubmitFeedback(context.Background(), "ana", feedback)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if fb.ID == uuid.Nil {
+		t.Fatal("Expected submitted feedback to get an id")
+	}
+	if fb.City != "London" {
+		t.Fatalf("Expected canonical city London, got %s", fb.City)
+	}
+	if fb.Subject != "ana" {
+		t.Fatalf("Expected feedback submitted by ana, got %q", fb.Subject)
+	}
+}
+
+func TestWeatherService_SubmitFeedbackResolvesCity(t *testing.T) {
+	weatherMock := client.NewMockWeatherClient(nil, 0)
+	weatherMock.SearchResponse = []dto.SearchLocation{{Name: "Zürich", Country: "Switzerland"}, {Name: "Zurich", Country: "Netherlands"}}
+	service := NewWeatherService(newProviders(weatherMock), nil, storage.NewWeatherInMemStorage())
+
+	fb, err := service.SubmitFeedback(context.Background(), "ana", &dto.WeatherFeedbackReq{City: "Zurich", Date: "2024-10-27", Message: "Rain"})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
+	if fb.City != "Zürich" {
+		t.Errorf("Expected the top search result Zürich, got %q", fb.City)
+	}
+}
+
+func TestWeatherService_SubmitFeedbackUnknownCity(t *testing.T) {
+	// The search finds nothing for Atlantis.
+	service := NewWeatherService(newProviders(client.NewMockWeatherClient(nil, 0)), nil, storage.NewWeatherInMemStorage())
+
+	_, err := service.SubmitFeedback(context.Background(), "ana", &dto.WeatherFeedbackReq{City: "Atlantis", Date: "2024-10-27", Message: "Rain", Rating: 3})
+
+	var resErr *result.Err
+	if !errors.As(err, &resErr) || resErr.Status != http.StatusBadRequest {
+		t.Fatalf("Expected validation error, got %v", err)
+	}
+}
+
+func TestWeatherService_UpdateAndDeleteFeedback(t *testing.T) {
+	service := NewWeatherService(newProviders(newCityMock()), nil, storage.NewWeatherInMemStorage())
+	ctx := context.Background()
+
+	fb, _ := service.SubmitFeedback(ctx, "ana", &dto.WeatherFeedbackReq{City: "London", Date: "2024-10-27", Message: "Rain", Rating: 3})
+
+	updated, err := service.UpdateFeedback(ctx, fb.ID, &dto.WeatherFeedbackReq{City: "London", Date: "2024-10-27", Message: "Heavy rain", Rating: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Message != "Heavy rain" || updated.UpdatedAt == nil || !updated.CreatedAt.Equal(fb.CreatedAt) {
+		t.Fatalf("Unexpected updated feedback %+v", updated)
+	}
+
+	if err := service.DeleteFeedback(ctx, fb.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, err = service.GetFeedback(ctx, fb.ID)
+	var resErr *result.Err
+	if !errors.As(err, &resErr) || resErr.Status != http.StatusNotFound {
+		t.Fatalf("Expected not found error, got %v", err)
+	}
+}
+
+func TestGetForecastByLocation_Success(t *testing.T) {
+	weatherMock := client.NewMockWeatherClient(nil, 0)
+	weatherMock.ForecastResponse.Forecast.ForecastDay = []dto.ForecastDay{
+		{Date: "2024-11-03"},
+		{Date: "2024-11-04"},
+	}
+	astroMock := client.NewMockAstroClient(nil)
+
+	service := NewWeatherService(newProviders(weatherMock), astroMock, storage.NewWeatherInMemStorage())
+
+	forecast, err := service.GetForecastByLocation(context.Background(), model.NewCityQuery("London"), 2, "")
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if forecast.Location.Name != "London" {
+		t.Fatalf("Expected forecast for London, got %+v", forecast.Location)
+	}
+	if len(forecast.Days) != 2 {
+		t.Fatalf("Expected 2 forecast days, got %d", len(forecast.Days))
+	}
+}
+
+func TestGetForecastByLocation_AstroClientError(t *testing.T) {
+	weatherMock := client.NewMockWeatherClient(nil, 0)
+	astroMock := client.NewMockAstroClient(errors.New("city not found"))
+
+	service := NewWeatherService(newProviders(weatherMock), astroMock, storage.NewWeatherInMemStorage())
+
+	forecast, err := service.GetForecastByLocation(context.Background(), model.NewCityQuery("London"), 2, "")
+
+	if err != nil {
+		t.Fatalf("Expected forecast served without astronomy data, got %v", err)
+	}
+	if forecast.Location.Name != "London" || forecast.Astro.Sunrise != "" {
+		t.Fatalf("Expected forecast for London without astronomy data, got %+v", forecast)
+	}
+}
+
+func TestGetWeatherByLocation_AstroClientError(t *testing.T) {
+	weatherMock := client.NewMockWeatherClient(nil, 0)
+	astroMock := client.NewMockAstroClient(errors.New("service unavailable"))
+
+	service := NewWeatherService(newProviders(weatherMock), astroMock, storage.NewWeatherInMemStorage())
+
+	for _, opts := range []model.WeatherOptions{{}, {Blend: true}} {
+		weather, err := service.GetWeatherByLocation(context.Background(), model.NewCityQuery("London"), opts)
+		if err != nil {
+			t.Fatalf("Expected weather served without astronomy data, got %v", err)
+		}
+		if weather.Location.Name != "London" || weather.Astro.Sunrise != "" {
+			t.Fatalf("Expected weather for London without astronomy data, got %+v", weather)
+		}
+	}
+}
+
+func TestGetHistoryByLocation_Success(t *testing.T) {
+	weatherMock := client.NewMockWeatherClient(nil, 0)
+	service := NewWeatherService(newProviders(weatherMock), nil, storage.NewWeatherInMemStorage())
+
+	from := time.Date(2024, 10, 25, 0, 0, 0, 0, time.UTC)
+	dates := []time.Time{from, from.AddDate(0, 0, 1), from.AddDate(0, 0, 2)}
+
+	history, err := service.GetHistoryByLocation(context.Background(), model.NewCityQuery("London"), dates, "")
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if history.Location.Name != "London" {
+		t.Fatalf("Expected history for London, got %+v", history.Location)
+	}
+	expected := []string{"2024-10-25", "2024-10-26", "2024-10-27"}
+	if len(history.Days) != len(expected) {
+		t.Fatalf("Expected %d history days, got %d", len(expected), len(history.Days))
+	}
+	for i, day := range history.Days {
+		if day.Date != expected[i] {
+			t.Errorf("Expected day %d to be %s, got %s", i, expected[i], day.Date)
+		}
+	}
+}
+
+func TestGetHistoryByLocation_WeatherClientError(t *testing.T) {
+	errMsg := "no matching location found"
+	weatherMock := client.NewMockWeatherClient(errors.New(errMsg), 0)
+	service := NewWeatherService(newProviders(weatherMock), nil, storage.NewWeatherInMemStorage())
+
+	dates := []time.Time{time.Date(2024, 10, 25, 0, 0, 0, 0, time.UTC)}
+	history, err := service.GetHistoryByLocation(context.Background(), model.NewCityQuery("London"), dates, "")
+
+	if err == nil || err.Error() != errMsg {
+		t.Fatalf("Expected weather error, got %v", err)
+	}
+	if history != nil {
+		t.Fatal("Expected nil history response due to error")
+	}
+}
+
+func TestSearchLocations_Success(t *testing.T) {
+	weatherMock := client.NewMockWeatherClient(nil, 0)
+	weatherMock.SearchResponse = make([]dto.SearchLocation, 7)
+	for i := range weatherMock.SearchResponse {
+		weatherMock.SearchResponse[i] = dto.SearchLocation{Name: "London", Country: "United Kingdom", Lat: 51.52, Lon: -0.11}
+	}
+
+	service := NewWeatherService(newProviders(weatherMock), nil, storage.NewWeatherInMemStorage())
+
+	candidates, err := service.SearchLocations(context.Background(), "Lond")
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(candidates) != maxSearchResults {
+		t.Fatalf("Expected %d candidates, got %d", maxSearchResults, len(candidates))
+	}
+	for _, candidate := range candidates {
+		if candidate.Name != "London" || candidate.Lat != 51.52 || candidate.Lon != -0.11 {
+			t.Errorf("Unexpected candidate %+v", candidate)
+		}
+	}
+}
+
+// newCityMock returns a weather client whose location search knows London only.
+func newCityMock() *client.MockWeatherClient {
+	weatherMock := client.NewMockWeatherClient(nil, 0)
+	weatherMock.SearchResponse = []dto.SearchLocation{{Name: "London", Country: "United Kingdom"}}
+	return weatherMock
+}
+
+func newProviders(cl client.WeatherClient) *client.ProviderRegistry {
+	providers := client.NewProviderRegistry()
+	providers.Register("mock", 0, cl)
+	return providers
 }
@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"context"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ApiKeyStorage interface {
+	AddApiKey(ctx context.Context, key *model.ApiKey) error
+	// GetApiKeyByHash returns ErrNotFound when no key has the given hash, revoked keys are returned.
+	GetApiKeyByHash(ctx context.Context, keyHash string) (*model.ApiKey, error)
+	// ListApiKeys returns the keys of a tenant, or of all tenants for an empty tenant, oldest first.
+	ListApiKeys(ctx context.Context, tenant string) ([]*model.ApiKey, error)
+	// RevokeApiKey returns ErrNotFound when no key has the given id. Revoking twice keeps the first time.
+	RevokeApiKey(ctx context.Context, id uuid.UUID, at time.Time) error
+}
@@ -0,0 +1,9 @@
+CREATE TABLE feedback (
+    id         TEXT PRIMARY KEY,
+    date       TEXT NOT NULL,
+    city       TEXT NOT NULL COLLATE NOCASE,
+    message    TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_feedback_city_date ON feedback (city, date);
@@ -0,0 +1,3 @@
+ALTER TABLE feedback ADD COLUMN updated_at TIMESTAMP;
+
+CREATE INDEX idx_feedback_created_at ON feedback (created_at);
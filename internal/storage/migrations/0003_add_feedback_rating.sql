@@ -0,0 +1,3 @@
+ALTER TABLE feedback ADD COLUMN rating INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE feedback ADD COLUMN observed_condition TEXT NOT NULL DEFAULT '';
+ALTER TABLE feedback ADD COLUMN reported_temp REAL;
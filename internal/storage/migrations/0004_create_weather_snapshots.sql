@@ -0,0 +1,12 @@
+CREATE TABLE weather_snapshots (
+    city           TEXT NOT NULL COLLATE NOCASE,
+    date           TEXT NOT NULL,
+    provider       TEXT NOT NULL,
+    temp           REAL NOT NULL,
+    condition      TEXT NOT NULL,
+    condition_text TEXT NOT NULL,
+    served_at      TIMESTAMP NOT NULL,
+    PRIMARY KEY (city, date, provider)
+);
+
+CREATE INDEX idx_weather_snapshots_date ON weather_snapshots (date);
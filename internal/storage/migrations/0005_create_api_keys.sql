@@ -0,0 +1,12 @@
+CREATE TABLE api_keys (
+    id         TEXT PRIMARY KEY,
+    key_hash   TEXT NOT NULL UNIQUE,
+    prefix     TEXT NOT NULL,
+    tenant     TEXT NOT NULL,
+    name       TEXT NOT NULL,
+    plan       TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_tenant ON api_keys (tenant);
@@ -0,0 +1 @@
+ALTER TABLE feedback ADD COLUMN subject TEXT NOT NULL DEFAULT '';
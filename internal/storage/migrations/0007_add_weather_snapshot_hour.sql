@@ -0,0 +1,21 @@
+-- Snapshots are kept per local hour, so feedback is compared with the weather served closest to it.
+-- Existing snapshots were the last of their day and are kept as hour 23.
+CREATE TABLE weather_snapshots_by_hour (
+    city           TEXT NOT NULL COLLATE NOCASE,
+    date           TEXT NOT NULL,
+    hour           INTEGER NOT NULL,
+    provider       TEXT NOT NULL,
+    temp           REAL NOT NULL,
+    condition      TEXT NOT NULL,
+    condition_text TEXT NOT NULL,
+    served_at      TIMESTAMP NOT NULL,
+    PRIMARY KEY (city, date, hour, provider)
+);
+
+INSERT INTO weather_snapshots_by_hour (city, date, hour, provider, temp, condition, condition_text, served_at)
+SELECT city, date, 23, provider, temp, condition, condition_text, served_at FROM weather_snapshots;
+
+DROP TABLE weather_snapshots;
+ALTER TABLE weather_snapshots_by_hour RENAME TO weather_snapshots;
+
+CREATE INDEX idx_weather_snapshots_date ON weather_snapshots (date);
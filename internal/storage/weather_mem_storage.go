@@ -4,17 +4,25 @@ import (
 	"context"
 	"github.com/DjordjeVuckovic/weather-radar/internal/model"
 	"github.com/google/uuid"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 type WeatherMemStorage struct {
 	feedbacks map[uuid.UUID]*model.Feedback
+	snapshots map[string]model.WeatherSnapshot
+	apiKeys   map[uuid.UUID]*model.ApiKey
 	mx        sync.RWMutex
 }
 
-func NewWeatherInMemStorage() WeatherStorage {
+func NewWeatherInMemStorage() Storage {
 	return &WeatherMemStorage{
 		feedbacks: make(map[uuid.UUID]*model.Feedback),
+		snapshots: make(map[string]model.WeatherSnapshot),
+		apiKeys:   make(map[uuid.UUID]*model.ApiKey),
 	}
 }
 
@@ -24,3 +32,164 @@ func (wms *WeatherMemStorage) AddFeedback(_ context.Context, fb *model.Feedback)
 	wms.feedbacks[fb.ID] = fb
 	return nil
 }
+
+func (wms *WeatherMemStorage) GetFeedback(_ context.Context, id uuid.UUID) (*model.Feedback, error) {
+	wms.mx.RLock()
+	defer wms.mx.RUnlock()
+	fb, ok := wms.feedbacks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	// A copy keeps callers from modifying stored feedback without UpdateFeedback.
+	fbCopy := *fb
+	return &fbCopy, nil
+}
+
+func (wms *WeatherMemStorage) ListFeedback(_ context.Context, filter model.FeedbackFilter) ([]*model.Feedback, int, error) {
+	wms.mx.RLock()
+	defer wms.mx.RUnlock()
+
+	var matches []*model.Feedback
+	for _, fb := range wms.feedbacks {
+		if filter.Matches(fb) {
+			fbCopy := *fb
+			matches = append(matches, &fbCopy)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].ID.String() < matches[j].ID.String()
+		}
+		return matches[i].CreatedAt.After(matches[j].CreatedAt)
+	})
+
+	total := len(matches)
+	start := min(filter.Offset, total)
+	end := total
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, total)
+	}
+	return matches[start:end], total, nil
+}
+
+func (wms *WeatherMemStorage) ForEachFeedback(ctx context.Context, filter model.FeedbackFilter, fn func(fb *model.Feedback) error) error {
+	filter.Limit, filter.Offset = 0, 0
+	feedbacks, _, err := wms.ListFeedback(ctx, filter)
+	if err != nil {
+		return err
+	}
+	// fn may be slow, e.g. write to a client, so it is called without holding the lock.
+	for i := len(feedbacks) - 1; i >= 0; i-- {
+		if err := fn(feedbacks[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wms *WeatherMemStorage) UpdateFeedback(_ context.Context, fb *model.Feedback) error {
+	wms.mx.Lock()
+	defer wms.mx.Unlock()
+	if _, ok := wms.feedbacks[fb.ID]; !ok {
+		return ErrNotFound
+	}
+	wms.feedbacks[fb.ID] = fb
+	return nil
+}
+
+func (wms *WeatherMemStorage) DeleteFeedback(_ context.Context, id uuid.UUID) error {
+	wms.mx.Lock()
+	defer wms.mx.Unlock()
+	if _, ok := wms.feedbacks[id]; !ok {
+		return ErrNotFound
+	}
+	delete(wms.feedbacks, id)
+	return nil
+}
+
+func (wms *WeatherMemStorage) SaveWeatherSnapshot(_ context.Context, snapshot model.WeatherSnapshot) error {
+	wms.mx.Lock()
+	defer wms.mx.Unlock()
+	key := strings.ToLower(snapshot.City) + "|" + snapshot.Date + "|" + strconv.Itoa(snapshot.Hour) + "|" + snapshot.Provider
+	wms.snapshots[key] = snapshot
+	return nil
+}
+
+func (wms *WeatherMemStorage) ListWeatherSnapshots(_ context.Context, filter model.SnapshotFilter) ([]model.WeatherSnapshot, error) {
+	wms.mx.RLock()
+	defer wms.mx.RUnlock()
+
+	var matches []model.WeatherSnapshot
+	for _, snapshot := range wms.snapshots {
+		if filter.Matches(snapshot) {
+			matches = append(matches, snapshot)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Date != matches[j].Date {
+			return matches[i].Date < matches[j].Date
+		}
+		if matches[i].Hour != matches[j].Hour {
+			return matches[i].Hour < matches[j].Hour
+		}
+		return matches[i].Provider < matches[j].Provider
+	})
+	return matches, nil
+}
+
+func (wms *WeatherMemStorage) AddApiKey(_ context.Context, key *model.ApiKey) error {
+	wms.mx.Lock()
+	defer wms.mx.Unlock()
+	keyCopy := *key
+	wms.apiKeys[key.ID] = &keyCopy
+	return nil
+}
+
+func (wms *WeatherMemStorage) GetApiKeyByHash(_ context.Context, keyHash string) (*model.ApiKey, error) {
+	wms.mx.RLock()
+	defer wms.mx.RUnlock()
+	for _, key := range wms.apiKeys {
+		if key.KeyHash == keyHash {
+			keyCopy := *key
+			return &keyCopy, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (wms *WeatherMemStorage) ListApiKeys(_ context.Context, tenant string) ([]*model.ApiKey, error) {
+	wms.mx.RLock()
+	defer wms.mx.RUnlock()
+
+	var keys []*model.ApiKey
+	for _, key := range wms.apiKeys {
+		if tenant == "" || key.Tenant == tenant {
+			keyCopy := *key
+			keys = append(keys, &keyCopy)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID.String() < keys[j].ID.String()
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+func (wms *WeatherMemStorage) RevokeApiKey(_ context.Context, id uuid.UUID, at time.Time) error {
+	wms.mx.Lock()
+	defer wms.mx.Unlock()
+	key, ok := wms.apiKeys[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+	}
+	return nil
+}
+
+func (wms *WeatherMemStorage) Close() error {
+	return nil
+}
@@ -0,0 +1,400 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+	"strings"
+	"time"
+)
+
+const (
+	feedbackColumns = "id, date, city, message, rating, observed_condition, reported_temp, subject, created_at, updated_at"
+	snapshotColumns = "city, date, hour, provider, temp, condition, condition_text, served_at"
+	apiKeyColumns   = "id, key_hash, prefix, tenant, name, plan, created_at, revoked_at"
+
+	exportBatchSize = 500
+)
+
+type WeatherSQLiteStorage struct {
+	db *sql.DB
+}
+
+// NewWeatherSQLiteStorage opens the SQLite database at path, creating it when missing,
+// and migrates it to the latest schema.
+func NewWeatherSQLiteStorage(ctx context.Context, path string) (Storage, error) {
+	dsn := fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on", path)
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	// SQLite allows a single writer, a single connection avoids busy errors between our own writes.
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &WeatherSQLiteStorage{db: db}, nil
+}
+
+func (s *WeatherSQLiteStorage) AddFeedback(ctx context.Context, fb *model.Feedback) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		"INSERT INTO feedback ("+feedbackColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		fb.ID.String(), fb.Date, fb.City, fb.Message, fb.Rating, fb.ObservedCondition, fb.ReportedTemp, fb.Subject, fb.CreatedAt, fb.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert feedback: %w", err)
+	}
+	return nil
+}
+
+func (s *WeatherSQLiteStorage) GetFeedback(ctx context.Context, id uuid.UUID) (*model.Feedback, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+feedbackColumns+" FROM feedback WHERE id = ?", id.String())
+	fb, err := scanFeedback(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get feedback: %w", err)
+	}
+	return fb, nil
+}
+
+// feedbackConditions returns the SQL conditions selecting feedback by the city and dates of filter.
+func feedbackConditions(filter model.FeedbackFilter) ([]string, []any) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if filter.City != "" {
+		conditions = append(conditions, "city = ?")
+		args = append(args, filter.City)
+	}
+	if filter.From != "" {
+		conditions = append(conditions, "date >= ?")
+		args = append(args, filter.From)
+	}
+	if filter.To != "" {
+		conditions = append(conditions, "date <= ?")
+		args = append(args, filter.To)
+	}
+	return conditions, args
+}
+
+func (s *WeatherSQLiteStorage) ListFeedback(ctx context.Context, filter model.FeedbackFilter) ([]*model.Feedback, int, error) {
+	conditions, args := feedbackConditions(filter)
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM feedback"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count feedback: %w", err)
+	}
+
+	// SQLite treats a negative limit as no limit.
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	query := "SELECT " + feedbackColumns + " FROM feedback" + where + " ORDER BY created_at DESC, id LIMIT ? OFFSET ?"
+	rows, err := s.db.QueryContext(ctx, query, append(args, limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list feedback: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var feedbacks []*model.Feedback
+	for rows.Next() {
+		fb, err := scanFeedback(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan feedback: %w", err)
+		}
+		feedbacks = append(feedbacks, fb)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("list feedback: %w", err)
+	}
+	return feedbacks, total, nil
+}
+
+// ForEachFeedback reads feedback in batches of exportBatchSize, in insertion order. Between batches
+// the connection is released, so a slow fn does not block other queries on the single connection.
+func (s *WeatherSQLiteStorage) ForEachFeedback(ctx context.Context, filter model.FeedbackFilter, fn func(fb *model.Feedback) error) error {
+	var lastRowID int64
+	for {
+		batch, err := s.feedbackBatch(ctx, filter, lastRowID)
+		if err != nil {
+			return err
+		}
+		for _, row := range batch {
+			if err := fn(row.feedback); err != nil {
+				return err
+			}
+			lastRowID = row.rowID
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+type feedbackRow struct {
+	rowID    int64
+	feedback *model.Feedback
+}
+
+func (s *WeatherSQLiteStorage) feedbackBatch(ctx context.Context, filter model.FeedbackFilter, afterRowID int64) ([]feedbackRow, error) {
+	conditions, args := feedbackConditions(filter)
+	conditions = append(conditions, "rowid > ?")
+	args = append(args, afterRowID, exportBatchSize)
+
+	query := "SELECT rowid, " + feedbackColumns + " FROM feedback WHERE " + strings.Join(conditions, " AND ") + " ORDER BY rowid LIMIT ?"
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("export feedback: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	batch := make([]feedbackRow, 0, exportBatchSize)
+	for rows.Next() {
+		var row feedbackRow
+		fb, err := scanFeedback(rowIDScanner{row: rows, rowID: &row.rowID})
+		if err != nil {
+			return nil, fmt.Errorf("scan feedback: %w", err)
+		}
+		row.feedback = fb
+		batch = append(batch, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("export feedback: %w", err)
+	}
+	return batch, nil
+}
+
+// rowIDScanner scans a leading rowid column before the feedback columns.
+type rowIDScanner struct {
+	row   rowScanner
+	rowID *int64
+}
+
+func (s rowIDScanner) Scan(dest ...any) error {
+	return s.row.Scan(append([]any{s.rowID}, dest...)...)
+}
+
+func (s *WeatherSQLiteStorage) UpdateFeedback(ctx context.Context, fb *model.Feedback) error {
+	res, err := s.db.ExecContext(
+		ctx,
+		"UPDATE feedback SET date = ?, city = ?, message = ?, rating = ?, observed_condition = ?, reported_temp = ?, updated_at = ? WHERE id = ?",
+		fb.Date, fb.City, fb.Message, fb.Rating, fb.ObservedCondition, fb.ReportedTemp, fb.UpdatedAt, fb.ID.String(),
+	)
+	if err != nil {
+		return fmt.Errorf("update feedback: %w", err)
+	}
+	return requireAffected(res)
+}
+
+func (s *WeatherSQLiteStorage) DeleteFeedback(ctx context.Context, id uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM feedback WHERE id = ?", id.String())
+	if err != nil {
+		return fmt.Errorf("delete feedback: %w", err)
+	}
+	return requireAffected(res)
+}
+
+func (s *WeatherSQLiteStorage) SaveWeatherSnapshot(ctx context.Context, snapshot model.WeatherSnapshot) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO weather_snapshots (`+snapshotColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (city, date, hour, provider) DO UPDATE SET
+			temp = excluded.temp,
+			condition = excluded.condition,
+			condition_text = excluded.condition_text,
+			served_at = excluded.served_at`,
+		snapshot.City, snapshot.Date, snapshot.Hour, snapshot.Provider, snapshot.Temp, snapshot.Condition, snapshot.ConditionText, snapshot.ServedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("save weather snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *WeatherSQLiteStorage) ListWeatherSnapshots(ctx context.Context, filter model.SnapshotFilter) ([]model.WeatherSnapshot, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if filter.City != "" {
+		conditions = append(conditions, "city = ?")
+		args = append(args, filter.City)
+	}
+	if filter.From != "" {
+		conditions = append(conditions, "date >= ?")
+		args = append(args, filter.From)
+	}
+	if filter.To != "" {
+		conditions = append(conditions, "date <= ?")
+		args = append(args, filter.To)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+snapshotColumns+" FROM weather_snapshots"+where+" ORDER BY date, hour, provider", args...)
+	if err != nil {
+		return nil, fmt.Errorf("list weather snapshots: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var snapshots []model.WeatherSnapshot
+	for rows.Next() {
+		var snapshot model.WeatherSnapshot
+		if err := rows.Scan(&snapshot.City, &snapshot.Date, &snapshot.Hour, &snapshot.Provider, &snapshot.Temp, &snapshot.Condition, &snapshot.ConditionText, &snapshot.ServedAt); err != nil {
+			return nil, fmt.Errorf("scan weather snapshot: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list weather snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+func (s *WeatherSQLiteStorage) AddApiKey(ctx context.Context, key *model.ApiKey) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		"INSERT INTO api_keys ("+apiKeyColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		key.ID.String(), key.KeyHash, key.Prefix, key.Tenant, key.Name, key.Plan, key.CreatedAt, key.RevokedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert api key: %w", err)
+	}
+	return nil
+}
+
+func (s *WeatherSQLiteStorage) GetApiKeyByHash(ctx context.Context, keyHash string) (*model.ApiKey, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = ?", keyHash)
+	key, err := scanApiKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+	return key, nil
+}
+
+func (s *WeatherSQLiteStorage) ListApiKeys(ctx context.Context, tenant string) ([]*model.ApiKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys"
+	var args []any
+	if tenant != "" {
+		query += " WHERE tenant = ?"
+		args = append(args, tenant)
+	}
+	rows, err := s.db.QueryContext(ctx, query+" ORDER BY created_at, id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var keys []*model.ApiKey
+	for rows.Next() {
+		key, err := scanApiKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *WeatherSQLiteStorage) RevokeApiKey(ctx context.Context, id uuid.UUID, at time.Time) error {
+	res, err := s.db.ExecContext(ctx, "UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ?", at, id.String())
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+	return requireAffected(res)
+}
+
+func scanApiKey(row rowScanner) (*model.ApiKey, error) {
+	var (
+		key       model.ApiKey
+		id        string
+		revokedAt sql.NullTime
+	)
+	if err := row.Scan(&id, &key.KeyHash, &key.Prefix, &key.Tenant, &key.Name, &key.Plan, &key.CreatedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	key.ID = parsed
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
+
+func requireAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanFeedback(row rowScanner) (*model.Feedback, error) {
+	var (
+		fb           model.Feedback
+		id           string
+		reportedTemp sql.NullFloat64
+		updatedAt    sql.NullTime
+	)
+	if err := row.Scan(&id, &fb.Date, &fb.City, &fb.Message, &fb.Rating, &fb.ObservedCondition, &reportedTemp, &fb.Subject, &fb.CreatedAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	fb.ID = parsed
+	if reportedTemp.Valid {
+		fb.ReportedTemp = &reportedTemp.Float64
+	}
+	if updatedAt.Valid {
+		fb.UpdatedAt = &updatedAt.Time
+	}
+	return &fb, nil
+}
+
+func (s *WeatherSQLiteStorage) Close() error {
+	return s.db.Close()
+}
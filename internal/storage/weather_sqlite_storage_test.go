@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/google/uuid"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWeatherSQLiteStorage_AddFeedback(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "weather.db")
+
+	st, err := NewWeatherSQLiteStorage(ctx, path)
+	if err != nil {
+		t.Fatalf("Expected no error opening storage, got %v", err)
+	}
+
+	fb := &model.Feedback{
+		ID:        uuid.New(),
+		Date:      "2024-10-27",
+		City:      "London",
+		Message:   "It was raining",
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := st.AddFeedback(ctx, fb); err != nil {
+		t.Fatalf("Expected no error adding feedback, got %v", err)
+	}
+	if err := st.Close(); err != nil {
+		t.Fatalf("Expected no error closing storage, got %v", err)
+	}
+
+	// Reopening must keep the data and not apply migrations twice.
+	reopened, err := NewWeatherSQLiteStorage(ctx, path)
+	if err != nil {
+		t.Fatalf("Expected no error reopening storage, got %v", err)
+	}
+	defer func() {
+		_ = reopened.Close()
+	}()
+
+	db := reopened.(*WeatherSQLiteStorage).db
+	var city string
+	if err := db.QueryRowContext(ctx, "SELECT city FROM feedback WHERE id = ?", fb.ID.String()).Scan(&city); err != nil {
+		t.Fatalf("Expected stored feedback, got %v", err)
+	}
+	if city != fb.City {
+		t.Errorf("Expected city %s, got %s", fb.City, city)
+	}
+
+	var applied int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&applied); err != nil {
+		t.Fatalf("Expected schema_migrations table, got %v", err)
+	}
+	available, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("Expected embedded migrations, got %v", err)
+	}
+	if applied != len(available) {
+		t.Errorf("Expected %d applied migrations, got %d", len(available), applied)
+	}
+}
+
+func TestWeatherSQLiteStorage_DuplicateID(t *testing.T) {
+	ctx := context.Background()
+	st, err := NewWeatherSQLiteStorage(ctx, filepath.Join(t.TempDir(), "weather.db"))
+	if err != nil {
+		t.Fatalf("Expected no error opening storage, got %v", err)
+	}
+	defer func() {
+		_ = st.Close()
+	}()
+
+	fb := &model.Feedback{ID: uuid.New(), Date: "2024-10-27", City: "London", Message: "Sunny", CreatedAt: time.Now()}
+	if err := st.AddFeedback(ctx, fb); err != nil {
+		t.Fatalf("Expected no error adding feedback, got %v", err)
+	}
+	if err := st.AddFeedback(ctx, fb); err == nil {
+		t.Fatal("Expected error adding feedback with duplicate id")
+	}
+}
+
+func TestWeatherSQLiteStorage_CityFilterUsesIndex(t *testing.T) {
+	ctx := context.Background()
+	st, err := NewWeatherSQLiteStorage(ctx, filepath.Join(t.TempDir(), "weather.db"))
+	if err != nil {
+		t.Fatalf("Expected no error opening storage, got %v", err)
+	}
+	defer func() {
+		_ = st.Close()
+	}()
+
+	conditions, args := feedbackConditions(model.FeedbackFilter{City: "london", From: "2024-10-01"})
+	query := "EXPLAIN QUERY PLAN SELECT id FROM feedback WHERE " + strings.Join(conditions, " AND ")
+	rows, err := st.(*WeatherSQLiteStorage).db.QueryContext(ctx, query, args...)
+	if err != nil {
+		t.Fatalf("Expected no error explaining the query, got %v", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var plan []string
+	for rows.Next() {
+		var (
+			id, parent, notUsed int
+			detail              string
+		)
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			t.Fatalf("Expected no error reading the plan, got %v", err)
+		}
+		plan = append(plan, detail)
+	}
+	if !strings.Contains(strings.Join(plan, "\n"), "idx_feedback_city_date") {
+		t.Errorf("Expected the city filter to use idx_feedback_city_date, got plan %v", plan)
+	}
+}
@@ -2,9 +2,34 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/google/uuid"
 )
 
+var ErrNotFound = errors.New("not found")
+
 type WeatherStorage interface {
 	AddFeedback(ctx context.Context, fb *model.Feedback) error
+	// GetFeedback returns ErrNotFound when no feedback has the given id.
+	GetFeedback(ctx context.Context, id uuid.UUID) (*model.Feedback, error)
+	// ListFeedback returns a page of matching feedback, newest first, and the total number of matches.
+	ListFeedback(ctx context.Context, filter model.FeedbackFilter) ([]*model.Feedback, int, error)
+	// ForEachFeedback calls fn for every matching feedback, oldest first, ignoring pagination.
+	// It stops at the first error fn returns and returns that error.
+	ForEachFeedback(ctx context.Context, filter model.FeedbackFilter, fn func(fb *model.Feedback) error) error
+	// UpdateFeedback returns ErrNotFound when no feedback has the id of fb.
+	UpdateFeedback(ctx context.Context, fb *model.Feedback) error
+	// DeleteFeedback returns ErrNotFound when no feedback has the given id.
+	DeleteFeedback(ctx context.Context, id uuid.UUID) error
+	// SaveWeatherSnapshot replaces the snapshot of the same city, date, hour and provider, if any.
+	SaveWeatherSnapshot(ctx context.Context, snapshot model.WeatherSnapshot) error
+	ListWeatherSnapshots(ctx context.Context, filter model.SnapshotFilter) ([]model.WeatherSnapshot, error)
+}
+
+// Storage is a storage backend, the weather and API key storages share its database.
+type Storage interface {
+	WeatherStorage
+	ApiKeyStorage
+	Close() error
 }
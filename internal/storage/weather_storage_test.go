@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/DjordjeVuckovic/weather-radar/internal/model"
+	"github.com/google/uuid"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// storages lets every storage implementation run the same scenarios.
+var storages = map[string]func(t *testing.T) Storage{
+	"memory": func(t *testing.T) Storage {
+		return NewWeatherInMemStorage()
+	},
+	"sqlite": func(t *testing.T) Storage {
+		st, err := NewWeatherSQLiteStorage(context.Background(), filepath.Join(t.TempDir(), "weather.db"))
+		if err != nil {
+			t.Fatalf("Expected no error opening storage, got %v", err)
+		}
+		return st
+	},
+}
+
+func TestWeatherStorage_Feedback(t *testing.T) {
+	for name, newStorage := range storages {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			st := newStorage(t)
+			defer func() {
+				_ = st.Close()
+			}()
+
+			created := time.Date(2024, 10, 27, 12, 0, 0, 0, time.UTC)
+			reportedTemp := 11.5
+			feedbacks := []*model.Feedback{
+				{ID: uuid.New(), Date: "2024-10-25", City: "London", Message: "Rain", Rating: 2, ObservedCondition: model.ObservedRain, ReportedTemp: &reportedTemp, Subject: "ana", CreatedAt: created},
+				{ID: uuid.New(), Date: "2024-10-26", City: "london", Message: "Fog", CreatedAt: created.Add(time.Minute)},
+				{ID: uuid.New(), Date: "2024-10-27", City: "Paris", Message: "Sun", CreatedAt: created.Add(2 * time.Minute)},
+			}
+			for _, fb := range feedbacks {
+				if err := st.AddFeedback(ctx, fb); err != nil {
+					t.Fatalf("Expected no error adding feedback, got %v", err)
+				}
+			}
+
+			got, err := st.GetFeedback(ctx, feedbacks[0].ID)
+			if err != nil || got.Message != "Rain" {
+				t.Fatalf("Expected stored feedback, got %+v, %v", got, err)
+			}
+			if got.Rating != 2 || got.ObservedCondition != model.ObservedRain || got.ReportedTemp == nil || *got.ReportedTemp != reportedTemp || got.Subject != "ana" {
+				t.Fatalf("Expected structured feedback fields, got %+v", got)
+			}
+
+			page, total, err := st.ListFeedback(ctx, model.FeedbackFilter{City: "LONDON", Limit: 1})
+			if err != nil {
+				t.Fatalf("Expected no error listing feedback, got %v", err)
+			}
+			if total != 2 || len(page) != 1 || page[0].ID != feedbacks[1].ID {
+				t.Fatalf("Expected newest London feedback of 2, got %d items of %d", len(page), total)
+			}
+
+			page, total, _ = st.ListFeedback(ctx, model.FeedbackFilter{From: "2024-10-26", To: "2024-10-26"})
+			if total != 1 || page[0].ID != feedbacks[1].ID {
+				t.Fatalf("Expected feedback of 2024-10-26 only, got %d", total)
+			}
+
+			page, total, _ = st.ListFeedback(ctx, model.FeedbackFilter{Offset: 2, Limit: 10})
+			if total != 3 || len(page) != 1 || page[0].ID != feedbacks[0].ID {
+				t.Fatalf("Expected oldest feedback on the last page, got %d items of %d", len(page), total)
+			}
+
+			var exported []uuid.UUID
+			err = st.ForEachFeedback(ctx, model.FeedbackFilter{City: "london", Limit: 1}, func(fb *model.Feedback) error {
+				exported = append(exported, fb.ID)
+				return nil
+			})
+			if err != nil || len(exported) != 2 || exported[0] != feedbacks[0].ID || exported[1] != feedbacks[1].ID {
+				t.Fatalf("Expected all London feedback oldest first, got %v, %v", exported, err)
+			}
+
+			errStop := errors.New("stop")
+			calls := 0
+			err = st.ForEachFeedback(ctx, model.FeedbackFilter{}, func(fb *model.Feedback) error {
+				calls++
+				return errStop
+			})
+			if !errors.Is(err, errStop) || calls != 1 {
+				t.Fatalf("Expected export to stop at the first error, got %v after %d calls", err, calls)
+			}
+
+			updatedAt := time.Now().UTC()
+			update := *got
+			update.Message = "Heavy rain"
+			update.UpdatedAt = &updatedAt
+			if err := st.UpdateFeedback(ctx, &update); err != nil {
+				t.Fatalf("Expected no error updating feedback, got %v", err)
+			}
+			got, _ = st.GetFeedback(ctx, feedbacks[0].ID)
+			if got.Message != "Heavy rain" || got.UpdatedAt == nil {
+				t.Fatalf("Expected updated feedback, got %+v", got)
+			}
+
+			if err := st.DeleteFeedback(ctx, feedbacks[0].ID); err != nil {
+				t.Fatalf("Expected no error deleting feedback, got %v", err)
+			}
+			if _, err := st.GetFeedback(ctx, feedbacks[0].ID); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound after delete, got %v", err)
+			}
+			if err := st.DeleteFeedback(ctx, feedbacks[0].ID); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound deleting twice, got %v", err)
+			}
+			if err := st.UpdateFeedback(ctx, &update); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound updating deleted feedback, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWeatherStorage_Snapshots(t *testing.T) {
+	for name, newStorage := range storages {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			st := newStorage(t)
+			defer func() {
+				_ = st.Close()
+			}()
+
+			served := time.Date(2024, 10, 27, 12, 0, 0, 0, time.UTC)
+			snapshots := []model.WeatherSnapshot{
+				{City: "London", Date: "2024-10-26", Provider: "weatherapi", Temp: 9, Condition: model.ObservedRain, ConditionText: "Light rain", ServedAt: served},
+				{City: "London", Date: "2024-10-27", Provider: "weatherapi", Temp: 10, Condition: model.ObservedRain, ConditionText: "Light rain", ServedAt: served},
+				{City: "london", Date: "2024-10-27", Provider: "weatherapi", Temp: 12, Condition: model.ObservedCloudy, ConditionText: "Overcast", ServedAt: served.Add(time.Hour)},
+				{City: "London", Date: "2024-10-27", Hour: 18, Provider: "weatherapi", Temp: 8, Condition: model.ObservedClear, ConditionText: "Clear", ServedAt: served.Add(6 * time.Hour)},
+				{City: "Paris", Date: "2024-10-27", Provider: "openweather", Temp: 14, Condition: model.ObservedClear, ConditionText: "Clear sky", ServedAt: served},
+			}
+			for _, snapshot := range snapshots {
+				if err := st.SaveWeatherSnapshot(ctx, snapshot); err != nil {
+					t.Fatalf("Expected no error saving snapshot, got %v", err)
+				}
+			}
+
+			got, err := st.ListWeatherSnapshots(ctx, model.SnapshotFilter{City: "LONDON", From: "2024-10-27"})
+			if err != nil {
+				t.Fatalf("Expected no error listing snapshots, got %v", err)
+			}
+			if len(got) != 2 || got[0].Temp != 12 || got[0].Condition != model.ObservedCloudy {
+				t.Fatalf("Expected the latest London snapshot of each hour of 2024-10-27, got %+v", got)
+			}
+			if got[1].Hour != 18 || got[1].Temp != 8 {
+				t.Fatalf("Expected the hour 18 snapshot last, got %+v", got[1])
+			}
+
+			got, _ = st.ListWeatherSnapshots(ctx, model.SnapshotFilter{})
+			if len(got) != 4 {
+				t.Fatalf("Expected 4 snapshots, got %d", len(got))
+			}
+		})
+	}
+}
+
+func TestWeatherStorage_ApiKeys(t *testing.T) {
+	for name, newStorage := range storages {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			st := newStorage(t)
+			defer func() {
+				_ = st.Close()
+			}()
+
+			created := time.Date(2024, 10, 27, 12, 0, 0, 0, time.UTC)
+			keys := []*model.ApiKey{
+				{ID: uuid.New(), Prefix: "wr_aaaaa", KeyHash: model.HashApiKey("key-a"), Tenant: "acme", Name: "web", Plan: "free", CreatedAt: created},
+				{ID: uuid.New(), Prefix: "wr_bbbbb", KeyHash: model.HashApiKey("key-b"), Tenant: "acme", Name: "mobile", Plan: "standard", CreatedAt: created.Add(time.Minute)},
+				{ID: uuid.New(), Prefix: "wr_ccccc", KeyHash: model.HashApiKey("key-c"), Tenant: "globex", Name: "backend", Plan: "partner", CreatedAt: created},
+			}
+			for _, key := range keys {
+				if err := st.AddApiKey(ctx, key); err != nil {
+					t.Fatalf("Expected no error adding api key, got %v", err)
+				}
+			}
+
+			got, err := st.GetApiKeyByHash(ctx, model.HashApiKey("key-b"))
+			if err != nil {
+				t.Fatalf("Expected no error getting api key, got %v", err)
+			}
+			if got.ID != keys[1].ID || got.Tenant != "acme" || got.Plan != "standard" || got.Revoked() {
+				t.Fatalf("Expected the mobile key of acme, got %+v", got)
+			}
+			if _, err := st.GetApiKeyByHash(ctx, model.HashApiKey("unknown")); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound for an unknown key, got %v", err)
+			}
+
+			acme, err := st.ListApiKeys(ctx, "acme")
+			if err != nil {
+				t.Fatalf("Expected no error listing api keys, got %v", err)
+			}
+			if len(acme) != 2 || acme[0].Name != "web" || acme[1].Name != "mobile" {
+				t.Fatalf("Expected the acme keys oldest first, got %+v", acme)
+			}
+			all, _ := st.ListApiKeys(ctx, "")
+			if len(all) != 3 {
+				t.Fatalf("Expected 3 api keys, got %d", len(all))
+			}
+
+			revokedAt := created.Add(time.Hour)
+			if err := st.RevokeApiKey(ctx, keys[0].ID, revokedAt); err != nil {
+				t.Fatalf("Expected no error revoking api key, got %v", err)
+			}
+			if err := st.RevokeApiKey(ctx, keys[0].ID, revokedAt.Add(time.Hour)); err != nil {
+				t.Fatalf("Expected no error revoking api key twice, got %v", err)
+			}
+			got, _ = st.GetApiKeyByHash(ctx, model.HashApiKey("key-a"))
+			if !got.Revoked() || !got.RevokedAt.Equal(revokedAt) {
+				t.Fatalf("Expected key to stay revoked at %v, got %v", revokedAt, got.RevokedAt)
+			}
+			if err := st.RevokeApiKey(ctx, uuid.New(), revokedAt); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound revoking an unknown key, got %v", err)
+			}
+		})
+	}
+}
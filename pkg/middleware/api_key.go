@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const ApiKeyHeader = "X-Api-Key"
+
+// apiKeyCacheSweepSize is the number of cached keys above which expired ones are removed.
+const apiKeyCacheSweepSize = 1024
+
+// Tenant is the owner of the API key a request was made with, and the rate its plan allows.
+type Tenant struct {
+	ID                string
+	Plan              string
+	RequestsPerMinute int
+}
+
+type tenantKey struct{}
+
+func WithTenant(ctx context.Context, tenant Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant ApiKeyAuth stored in ctx.
+func TenantFromContext(ctx context.Context) (Tenant, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(Tenant)
+	return tenant, ok
+}
+
+// TenantResolver returns the tenant an API key belongs to, or an error for unknown and revoked keys.
+type TenantResolver func(ctx context.Context, key string) (Tenant, error)
+
+type ApiKeyAuthConfig struct {
+	Resolve TenantResolver
+	// Lookups limits the keys resolved for a client, keyed by its address. Resolved keys are cached,
+	// so it limits unknown keys, which would otherwise let a client guess keys and load the storage
+	// unthrottled. Lookups are not limited without it.
+	Lookups Limiter
+	// CacheTTL is how long a resolved key is accepted without resolving it again. A revoked key
+	// is accepted for up to CacheTTL. Keys are resolved on every request when zero.
+	CacheTTL time.Duration
+}
+
+// ApiKeyAuth resolves the X-Api-Key header to a tenant and stores it in the request context.
+// Requests without the header stay anonymous, requests with an invalid key are rejected
+// with the error of the resolver.
+func ApiKeyAuth(config ApiKeyAuthConfig) server.MiddlewareFunc {
+	auth := &apiKeyAuth{
+		config: config,
+		cache:  make(map[[sha256.Size]byte]cachedTenant),
+	}
+	return func(next server.HandlerFunc) server.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			key := r.Header.Get(ApiKeyHeader)
+			if key == "" || r.Method == http.MethodOptions {
+				return next(w, r)
+			}
+			tenant, ok, err := auth.tenant(w, r, key)
+			if !ok {
+				return err
+			}
+			return next(w, r.WithContext(WithTenant(r.Context(), tenant)))
+		}
+	}
+}
+
+type apiKeyAuth struct {
+	config ApiKeyAuthConfig
+
+	mx sync.Mutex
+	// cache maps the hashes of resolved keys to their tenant, so no key is kept in plain text.
+	cache map[[sha256.Size]byte]cachedTenant
+}
+
+type cachedTenant struct {
+	tenant  Tenant
+	expires time.Time
+}
+
+// tenant resolves the key, or writes the response when the client made too many lookups.
+func (a *apiKeyAuth) tenant(w http.ResponseWriter, r *http.Request, key string) (Tenant, bool, error) {
+	hash := sha256.Sum256([]byte(key))
+	if tenant, ok := a.cached(hash); ok {
+		return tenant, true, nil
+	}
+
+	if a.config.Lookups != nil {
+		limit, err := a.config.Lookups.AddAndCheckLimit(r)
+		if err != nil {
+			return Tenant{}, false, result.InternalServerErr(err.Error())
+		}
+		if limit.Exceeded {
+			return Tenant{}, false, writeRateLimited(w, limit, "Too many API key lookups")
+		}
+	}
+
+	tenant, err := a.config.Resolve(r.Context(), key)
+	if err != nil {
+		return Tenant{}, false, err
+	}
+	a.store(hash, tenant)
+	return tenant, true, nil
+}
+
+func (a *apiKeyAuth) cached(hash [sha256.Size]byte) (Tenant, bool) {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	entry, ok := a.cache[hash]
+	if !ok || time.Now().After(entry.expires) {
+		return Tenant{}, false
+	}
+	return entry.tenant, true
+}
+
+func (a *apiKeyAuth) store(hash [sha256.Size]byte, tenant Tenant) {
+	if a.config.CacheTTL <= 0 {
+		return
+	}
+	now := time.Now()
+
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	if len(a.cache) >= apiKeyCacheSweepSize {
+		for h, entry := range a.cache {
+			if now.After(entry.expires) {
+				delete(a.cache, h)
+			}
+		}
+	}
+	a.cache[hash] = cachedTenant{tenant: tenant, expires: now.Add(a.config.CacheTTL)}
+}
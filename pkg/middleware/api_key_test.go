@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestApiKeyAuth(t *testing.T) {
+	resolve := func(_ context.Context, key string) (Tenant, error) {
+		if key == "wr_valid" {
+			return Tenant{ID: "acme", Plan: "free", RequestsPerMinute: 60}, nil
+		}
+		return Tenant{}, result.UnauthorizedErr("Invalid API key")
+	}
+
+	var got Tenant
+	var found bool
+	handler := ApiKeyAuth(ApiKeyAuthConfig{Resolve: resolve})(func(w http.ResponseWriter, r *http.Request) error {
+		got, found = TenantFromContext(r.Context())
+		return nil
+	})
+
+	tests := []struct {
+		name       string
+		key        string
+		wantTenant string
+		wantStatus int
+	}{
+		{"anonymous", "", "", 0},
+		{"valid key", "wr_valid", "acme", 0},
+		{"invalid key", "wr_guess", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found = Tenant{}, false
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.key != "" {
+				req.Header.Set(ApiKeyHeader, tt.key)
+			}
+
+			err := handler(httptest.NewRecorder(), req)
+
+			var rErr *result.Err
+			if tt.wantStatus != 0 {
+				if !errors.As(err, &rErr) || rErr.Status != tt.wantStatus {
+					t.Fatalf("Expected status %d, got %v", tt.wantStatus, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if found != (tt.wantTenant != "") || got.ID != tt.wantTenant {
+				t.Errorf("Expected tenant %q, got %q", tt.wantTenant, got.ID)
+			}
+		})
+	}
+}
+
+func TestApiKeyAuth_CacheAndLookupLimit(t *testing.T) {
+	lookups := 0
+	resolve := func(_ context.Context, key string) (Tenant, error) {
+		lookups++
+		if key == "wr_valid" {
+			return Tenant{ID: "acme"}, nil
+		}
+		return Tenant{}, result.UnauthorizedErr("Invalid API key")
+	}
+	limiter := NewFixedWindowLimiter(FixedWindowLimiterConfig{Window: time.Minute, MaxRequests: 2})
+	defer limiter.Stop()
+	handler := ApiKeyAuth(ApiKeyAuthConfig{Resolve: resolve, Lookups: limiter, CacheTTL: time.Minute})(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+	send := func(key string) (int, error) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(ApiKeyHeader, key)
+		rec := httptest.NewRecorder()
+		err := handler(rec, req)
+		return rec.Code, err
+	}
+
+	// A resolved key is cached and does not count as a lookup again.
+	for range 3 {
+		if code, err := send("wr_valid"); err != nil || code != http.StatusOK {
+			t.Fatalf("Expected valid key accepted, got %d, %v", code, err)
+		}
+	}
+	if lookups != 1 {
+		t.Fatalf("Expected a single lookup of the valid key, got %d", lookups)
+	}
+
+	// Unknown keys use up the lookups of the client, then are not resolved at all.
+	if _, err := send("wr_guess1"); err == nil {
+		t.Fatal("Expected unknown key rejected")
+	}
+	code, err := send("wr_guess2")
+	if err != nil || code != http.StatusTooManyRequests {
+		t.Fatalf("Expected lookups limited, got %d, %v", code, err)
+	}
+	if lookups != 2 {
+		t.Fatalf("Expected no lookup over the limit, got %d lookups", lookups)
+	}
+	if code, err := send("wr_valid"); err != nil || code != http.StatusOK {
+		t.Fatalf("Expected cached key accepted over the lookup limit, got %d, %v", code, err)
+	}
+}
+
+func TestFixedWindowLimiter_Tenant(t *testing.T) {
+	handler := newTestFwLimiterHandler(time.Minute, 1)
+	tenantReq := func(addr string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = addr
+		return req.WithContext(WithTenant(req.Context(), Tenant{ID: "acme", RequestsPerMinute: 3}))
+	}
+
+	// The plan allows 3 requests a minute, shared by every address the tenant calls from.
+	for i, addr := range []string{"10.0.0.1:1000", "10.0.0.2:1000", "10.0.0.3:1000", "10.0.0.4:1000"} {
+		rec := httptest.NewRecorder()
+		_ = handler(rec, tenantReq(addr))
+
+		want := http.StatusOK
+		if i == 3 {
+			want = http.StatusTooManyRequests
+		}
+		if rec.Code != want {
+			t.Fatalf("Expected status %d for request %d, got %d", want, i+1, rec.Code)
+		}
+		if limit := rec.Header().Get("X-RateLimit-Limit"); limit != "3" {
+			t.Fatalf("Expected the plan limit of 3, got %s", limit)
+		}
+	}
+
+	// Anonymous requests from the same address are limited separately by the route limit.
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1000"
+	rec := httptest.NewRecorder()
+	_ = handler(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Limit") != "1" {
+		t.Fatalf("Expected anonymous request allowed with limit 1, got %d with limit %s", rec.Code, rec.Header().Get("X-RateLimit-Limit"))
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"net/http"
+	"slices"
+)
+
+// Identity is the authenticated caller of a request.
+type Identity struct {
+	Subject string
+	Roles   []string
+}
+
+func (i Identity) HasRole(role string) bool {
+	return slices.Contains(i.Roles, role)
+}
+
+type identityKey struct{}
+
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity an authentication middleware stored in ctx.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// BasicAuthFunc validates Basic Auth credentials and returns the identity they belong to.
+type BasicAuthFunc func(username, password string) (Identity, bool)
+
+// BasicAuth rejects requests without valid Basic Auth credentials and stores the caller's
+// identity in the request context.
+func BasicAuth(realm string, authenticate BasicAuthFunc) server.MiddlewareFunc {
+	return func(next server.HandlerFunc) server.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			username, password, ok := r.BasicAuth()
+			if !ok {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", realm))
+				return result.UnauthorizedErr("Missing credentials")
+			}
+			identity, ok := authenticate(username, password)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", realm))
+				return result.UnauthorizedErr("Invalid credentials")
+			}
+			return next(w, r.WithContext(WithIdentity(r.Context(), identity)))
+		}
+	}
+}
+
+// RequireRole lets a request through when the authenticated identity has any of the roles.
+// It must be used after an authentication middleware.
+func RequireRole(roles ...string) server.MiddlewareFunc {
+	return func(next server.HandlerFunc) server.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			identity, ok := IdentityFromContext(r.Context())
+			if !ok {
+				return result.UnauthorizedErr("Authentication required")
+			}
+			for _, role := range roles {
+				if identity.HasRole(role) {
+					return next(w, r)
+				}
+			}
+			return result.ForbiddenErr(fmt.Sprintf("Requires one of the roles: %v", roles))
+		}
+	}
+}
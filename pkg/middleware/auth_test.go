@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasicAuth(t *testing.T) {
+	authenticate := func(username, password string) (Identity, bool) {
+		if username == "ana" && password == "secret" {
+			return Identity{Subject: "ana", Roles: []string{"reader"}}, true
+		}
+		return Identity{}, false
+	}
+
+	var got Identity
+	handler := BasicAuth("test", authenticate)(func(w http.ResponseWriter, r *http.Request) error {
+		got, _ = IdentityFromContext(r.Context())
+		return nil
+	})
+
+	tests := []struct {
+		name       string
+		username   string
+		password   string
+		withAuth   bool
+		wantStatus int
+	}{
+		{"missing credentials", "", "", false, http.StatusUnauthorized},
+		{"wrong password", "ana", "guess", true, http.StatusUnauthorized},
+		{"valid credentials", "ana", "secret", true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.withAuth {
+				req.SetBasicAuth(tt.username, tt.password)
+			}
+			rec := httptest.NewRecorder()
+
+			err := handler(rec, req)
+
+			if tt.wantStatus == 0 {
+				if err != nil || got.Subject != "ana" {
+					t.Fatalf("Expected identity of ana, got %+v, %v", got, err)
+				}
+				return
+			}
+			var resErr *result.Err
+			if !errors.As(err, &resErr) || resErr.Status != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %v", tt.wantStatus, err)
+			}
+			if rec.Header().Get("WWW-Authenticate") != `Basic realm="test"` {
+				t.Errorf("Expected WWW-Authenticate challenge, got %q", rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	handler := RequireRole("writer", "admin")(server.TestOKHandler())
+
+	tests := []struct {
+		name       string
+		identity   *Identity
+		wantStatus int
+	}{
+		{"no identity", nil, http.StatusUnauthorized},
+		{"missing role", &Identity{Subject: "ana", Roles: []string{"reader"}}, http.StatusForbidden},
+		{"any of the roles", &Identity{Subject: "ana", Roles: []string{"reader", "admin"}}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.identity != nil {
+				req = req.WithContext(WithIdentity(req.Context(), *tt.identity))
+			}
+			rec := httptest.NewRecorder()
+
+			err := handler(rec, req)
+
+			status := rec.Code
+			var resErr *result.Err
+			if errors.As(err, &resErr) {
+				status = resErr.Status
+			}
+			if status != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, status)
+			}
+		})
+	}
+}
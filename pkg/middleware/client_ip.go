@@ -0,0 +1,262 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Forwarding headers trusted proxies can identify clients by.
+const (
+	HeaderForwarded     = "Forwarded"
+	HeaderXForwardedFor = "X-Forwarded-For"
+	HeaderXRealIP       = "X-Real-IP"
+)
+
+// DefaultIPv6PrefixLen groups IPv6 clients by /64, the smallest network usually assigned to a
+// single subscriber, who can otherwise rotate through its addresses.
+const DefaultIPv6PrefixLen = 64
+
+// Client identifies the client of a request. IP is the address it connected from, or was
+// forwarded for by a trusted proxy. Key groups the addresses of a client, it is the IP for
+// IPv4 and the network of the IPv6 prefix for IPv6.
+type Client struct {
+	IP  netip.Addr
+	Key string
+}
+
+// Addr returns the IP, or the remote address when it is not an IP.
+func (c Client) Addr() string {
+	if c.IP.IsValid() {
+		return c.IP.String()
+	}
+	return c.Key
+}
+
+type ClientResolverConfig struct {
+	// TrustedProxies are the networks whose forwarding headers are honored. The headers of other
+	// clients are ignored, since anyone can send them.
+	TrustedProxies []netip.Prefix
+	// TrustedHeader is the forwarding header the trusted proxies set, see ParseTrustedHeader. No
+	// other header is read, proxies pass those a client sent through and the client could pick its
+	// address. Defaults to X-Forwarded-For.
+	TrustedHeader string
+	// IPv6PrefixLen groups IPv6 clients by network, 128 keys every address on its own.
+	// Defaults to DefaultIPv6PrefixLen.
+	IPv6PrefixLen int
+}
+
+// ClientResolver identifies clients by the remote address, or behind trusted proxies by the
+// forwarding header they set.
+type ClientResolver struct {
+	trustedProxies []netip.Prefix
+	trustedHeader  string
+	ipv6PrefixLen  int
+}
+
+var defaultClientResolver = &ClientResolver{ipv6PrefixLen: DefaultIPv6PrefixLen}
+
+func NewClientResolver(config ClientResolverConfig) (*ClientResolver, error) {
+	prefixLen := config.IPv6PrefixLen
+	if prefixLen == 0 {
+		prefixLen = DefaultIPv6PrefixLen
+	}
+	if prefixLen < 1 || prefixLen > 128 {
+		return nil, fmt.Errorf("IPv6 prefix length must be between 1 and 128, got %d", prefixLen)
+	}
+	header := HeaderXForwardedFor
+	if config.TrustedHeader != "" {
+		var ok bool
+		if header, ok = ParseTrustedHeader(config.TrustedHeader); !ok {
+			return nil, fmt.Errorf("trusted header must be one of %s, %s or %s, got %q", HeaderForwarded, HeaderXForwardedFor, HeaderXRealIP, config.TrustedHeader)
+		}
+	}
+	trusted := make([]netip.Prefix, 0, len(config.TrustedProxies))
+	for _, p := range config.TrustedProxies {
+		trusted = append(trusted, p.Masked())
+	}
+	return &ClientResolver{trustedProxies: trusted, trustedHeader: header, ipv6PrefixLen: prefixLen}, nil
+}
+
+// ParseTrustedHeader returns the forwarding header of the given name, in any case.
+func ParseTrustedHeader(name string) (string, bool) {
+	for _, header := range []string{HeaderForwarded, HeaderXForwardedFor, HeaderXRealIP} {
+		if strings.EqualFold(strings.TrimSpace(name), header) {
+			return header, true
+		}
+	}
+	return "", false
+}
+
+// ParseTrustedProxies parses a comma separated list of CIDRs and IP addresses.
+func ParseTrustedProxies(s string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if strings.Contains(field, "/") {
+			p, err := netip.ParsePrefix(field)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, p)
+			continue
+		}
+		addr, err := netip.ParseAddr(field)
+		if err != nil {
+			return nil, err
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// Resolve identifies the client of the request. Forwarded addresses are read right to left, the
+// client is the first one not of a trusted proxy. An invalid address ends the chain at the last
+// trusted proxy, so a client cannot make up the hops before it.
+func (cr *ClientResolver) Resolve(r *http.Request) Client {
+	ip, ok := parseRemoteAddr(r.RemoteAddr)
+	if !ok {
+		return Client{Key: r.RemoteAddr}
+	}
+	if cr.trusted(ip) {
+		ip = cr.forwardedFor(r, ip)
+	}
+	return Client{IP: ip, Key: cr.key(ip)}
+}
+
+func (cr *ClientResolver) forwardedFor(r *http.Request, proxy netip.Addr) netip.Addr {
+	var chain []string
+	switch cr.trustedHeader {
+	case HeaderForwarded:
+		chain = forwardedChain(r.Header)
+	case HeaderXRealIP:
+		if ip, ok := parseIP(r.Header.Get(HeaderXRealIP)); ok {
+			return ip
+		}
+		return proxy
+	default:
+		chain = xForwardedForChain(r.Header)
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip, ok := parseIP(chain[i])
+		if !ok {
+			return proxy
+		}
+		if !cr.trusted(ip) {
+			return ip
+		}
+		proxy = ip
+	}
+	return proxy
+}
+
+func (cr *ClientResolver) trusted(ip netip.Addr) bool {
+	for _, p := range cr.trustedProxies {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cr *ClientResolver) key(ip netip.Addr) string {
+	if !ip.Is6() || cr.ipv6PrefixLen == 128 {
+		return ip.String()
+	}
+	prefix, err := ip.Prefix(cr.ipv6PrefixLen)
+	if err != nil {
+		return ip.String()
+	}
+	return prefix.String()
+}
+
+// forwardedChain returns the for parameters of the Forwarded headers (RFC 7239), oldest hop first.
+func forwardedChain(h http.Header) []string {
+	var chain []string
+	for _, header := range h.Values(HeaderForwarded) {
+		for _, element := range strings.Split(header, ",") {
+			// Every hop is part of the chain, an element without for breaks it like an invalid one.
+			var forIP string
+			for _, pair := range strings.Split(element, ";") {
+				name, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+				if strings.EqualFold(name, "for") {
+					forIP = strings.Trim(value, `"`)
+				}
+			}
+			chain = append(chain, forIP)
+		}
+	}
+	return chain
+}
+
+// xForwardedForChain returns the addresses of the X-Forwarded-For headers, oldest hop first.
+func xForwardedForChain(h http.Header) []string {
+	var chain []string
+	for _, header := range h.Values(HeaderXForwardedFor) {
+		for _, ip := range strings.Split(header, ",") {
+			chain = append(chain, strings.TrimSpace(ip))
+		}
+	}
+	return chain
+}
+
+// parseIP parses an address, optionally with a port and IPv6 in brackets as in Forwarded.
+func parseIP(s string) (netip.Addr, bool) {
+	if ip, err := netip.ParseAddr(strings.Trim(s, "[]")); err == nil {
+		return ip.Unmap().WithZone(""), true
+	}
+	return parseRemoteAddr(s)
+}
+
+func parseRemoteAddr(addr string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	// IPv4 clients of a dual stack listener connect as ::ffff:a.b.c.d.
+	return ip.Unmap().WithZone(""), true
+}
+
+type clientKey struct{}
+
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFromContext returns the client ClientIdentification stored in ctx.
+func ClientFromContext(ctx context.Context) (Client, bool) {
+	client, ok := ctx.Value(clientKey{}).(Client)
+	return client, ok
+}
+
+// ClientFromRequest returns the client ClientIdentification resolved, or without it the client
+// by the remote address.
+func ClientFromRequest(r *http.Request) Client {
+	if client, ok := ClientFromContext(r.Context()); ok {
+		return client
+	}
+	return defaultClientResolver.Resolve(r)
+}
+
+// ClientIdentification resolves the client of every request and stores it in the request context
+// for the rate limiters, the logger and handlers. Use it before the middleware relying on it.
+func ClientIdentification(resolver *ClientResolver) server.MiddlewareFunc {
+	return func(next server.HandlerFunc) server.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			client := resolver.Resolve(r)
+			return next(w, r.WithContext(WithClient(r.Context(), client)))
+		}
+	}
+}
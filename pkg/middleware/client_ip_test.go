@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientResolver_Resolve(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.1, fd00::/8")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resolvers := make(map[string]*ClientResolver)
+	for _, header := range []string{HeaderForwarded, HeaderXForwardedFor, HeaderXRealIP} {
+		resolver, err := NewClientResolver(ClientResolverConfig{TrustedProxies: trusted, TrustedHeader: header})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		resolvers[header] = resolver
+	}
+
+	tests := []struct {
+		name       string
+		trusted    string
+		remoteAddr string
+		headers    map[string]string
+		wantIP     string
+		wantKey    string
+	}{
+		{
+			name:       "Strip port",
+			remoteAddr: "203.0.113.7:51234",
+			wantIP:     "203.0.113.7",
+			wantKey:    "203.0.113.7",
+		},
+		{
+			name:       "Unmap IPv4 of dual stack listener",
+			remoteAddr: "[::ffff:203.0.113.7]:51234",
+			wantIP:     "203.0.113.7",
+			wantKey:    "203.0.113.7",
+		},
+		{
+			name:       "Group IPv6 by prefix",
+			remoteAddr: "[2001:db8:1:2:aaaa::1]:443",
+			wantIP:     "2001:db8:1:2:aaaa::1",
+			wantKey:    "2001:db8:1:2::/64",
+		},
+		{
+			name:       "Ignore headers of untrusted client",
+			remoteAddr: "203.0.113.7:51234",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "198.51.100.2"},
+			wantIP:     "203.0.113.7",
+			wantKey:    "203.0.113.7",
+		},
+		{
+			name:       "X-Forwarded-For from trusted proxy",
+			remoteAddr: "10.1.2.3:8080",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			wantIP:     "198.51.100.1",
+			wantKey:    "198.51.100.1",
+		},
+		{
+			name:       "Skip trusted hops and ignore spoofed ones before them",
+			remoteAddr: "10.1.2.3:8080",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.1, 192.168.1.1"},
+			wantIP:     "198.51.100.1",
+			wantKey:    "198.51.100.1",
+		},
+		{
+			name:       "Stop at invalid hop",
+			remoteAddr: "10.1.2.3:8080",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1, garbage, 10.0.0.9"},
+			wantIP:     "10.0.0.9",
+			wantKey:    "10.0.0.9",
+		},
+		{
+			name:       "Forwarded from trusted proxy",
+			trusted:    HeaderForwarded,
+			remoteAddr: "10.1.2.3:8080",
+			headers: map[string]string{
+				"Forwarded":       `for="[2001:db8::1]:4711";proto=https, for=10.0.0.9`,
+				"X-Forwarded-For": "198.51.100.1",
+			},
+			wantIP:  "2001:db8::1",
+			wantKey: "2001:db8::/64",
+		},
+		{
+			name:       "Obfuscated Forwarded hop",
+			trusted:    HeaderForwarded,
+			remoteAddr: "10.1.2.3:8080",
+			headers:    map[string]string{"Forwarded": "for=_hidden, for=10.0.0.9"},
+			wantIP:     "10.0.0.9",
+			wantKey:    "10.0.0.9",
+		},
+		{
+			name:       "X-Real-IP from trusted proxy",
+			trusted:    HeaderXRealIP,
+			remoteAddr: "[fd00::1]:8080",
+			headers:    map[string]string{"X-Real-IP": "198.51.100.1"},
+			wantIP:     "198.51.100.1",
+			wantKey:    "198.51.100.1",
+		},
+		{
+			name:       "Ignore Forwarded passed through by X-Forwarded-For proxy",
+			remoteAddr: "10.1.2.3:8080",
+			headers: map[string]string{
+				"Forwarded":       "for=198.51.100.9",
+				"X-Forwarded-For": "198.51.100.1",
+			},
+			wantIP:  "198.51.100.1",
+			wantKey: "198.51.100.1",
+		},
+		{
+			name:       "Ignore X-Real-IP when not trusted",
+			remoteAddr: "10.1.2.3:8080",
+			headers:    map[string]string{"X-Real-IP": "198.51.100.2"},
+			wantIP:     "10.1.2.3",
+			wantKey:    "10.1.2.3",
+		},
+		{
+			name:       "Keep unparsable remote address",
+			remoteAddr: "pipe",
+			wantKey:    "pipe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			header := tt.trusted
+			if header == "" {
+				header = HeaderXForwardedFor
+			}
+			client := resolvers[header].Resolve(req)
+			if tt.wantIP != "" && client.IP.String() != tt.wantIP {
+				t.Errorf("Expected IP %s, got %s", tt.wantIP, client.IP)
+			}
+			if client.Key != tt.wantKey {
+				t.Errorf("Expected key %s, got %s", tt.wantKey, client.Key)
+			}
+		})
+	}
+}
+
+func TestNewClientResolver(t *testing.T) {
+	resolver, err := NewClientResolver(ClientResolverConfig{IPv6PrefixLen: 128})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "[2001:db8::1]:443"
+	if key := resolver.Resolve(req).Key; key != "2001:db8::1" {
+		t.Errorf("Expected every IPv6 address keyed on its own, got %s", key)
+	}
+
+	if _, err := NewClientResolver(ClientResolverConfig{IPv6PrefixLen: 129}); err == nil {
+		t.Error("Expected an error for a prefix longer than 128 bits")
+	}
+	if _, err := NewClientResolver(ClientResolverConfig{TrustedHeader: "X-Client-IP"}); err == nil {
+		t.Error("Expected an error for an unknown trusted header")
+	}
+	if header, ok := ParseTrustedHeader("x-real-ip"); !ok || header != HeaderXRealIP {
+		t.Errorf("Expected X-Real-IP, got %q", header)
+	}
+	if _, err := ParseTrustedProxies("10.0.0.0/33"); err == nil {
+		t.Error("Expected an error for an invalid CIDR")
+	}
+}
+
+func TestClientIdentification(t *testing.T) {
+	trusted, _ := ParseTrustedProxies("10.0.0.0/8")
+	resolver, _ := NewClientResolver(ClientResolverConfig{TrustedProxies: trusted})
+	limiter := NewFixedWindowLimiter(FixedWindowLimiterConfig{Window: time.Minute, MaxRequests: 1})
+	defer limiter.Stop()
+	handler := ClientIdentification(resolver)(RateLimit(limiter)(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}))
+
+	send := func(remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		_ = handler(rec, req)
+		return rec.Code
+	}
+
+	// Behind the ingress, clients are limited on their own and new connections share a budget.
+	if code := send("10.0.0.2:40000", "198.51.100.1"); code != http.StatusOK {
+		t.Fatalf("Expected first client allowed, got %d", code)
+	}
+	if code := send("10.0.0.2:40001", "198.51.100.2"); code != http.StatusOK {
+		t.Fatalf("Expected second client allowed, got %d", code)
+	}
+	if code := send("10.0.0.3:40002", "198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("Expected first client limited on a new connection, got %d", code)
+	}
+}
@@ -19,6 +19,11 @@ func CORS(c CORSConfig) server.MiddlewareFunc {
 			w.Header().Set(
 				"Access-Control-Allow-Headers",
 				"Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-Api-Key")
+			// Lets browser clients read when to retry, these are not safelisted response headers.
+			w.Header().Set(
+				"Access-Control-Expose-Headers",
+				"Retry-After, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, "+
+					"X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset")
 
 			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusNoContent)
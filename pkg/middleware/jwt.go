@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"github.com/golang-jwt/jwt/v5"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type JWTConfig struct {
+	// Secret verifies HS256 tokens, HS256 is rejected without it.
+	Secret []byte
+	// Keys verify RS256 and ES256 tokens by the kid of their header, both are rejected without keys.
+	Keys JWKS
+	// Audience and Issuer the tokens must have, empty values are not checked.
+	Audience string
+	Issuer   string
+	// Leeway tolerates clock skew between the issuer and us when checking exp and nbf.
+	Leeway time.Duration
+}
+
+// Claims are the claims of a validated JWT. Roles is the custom claim the roles are read from.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
+type claimsKey struct{}
+
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims the JWT middleware stored in ctx.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
+
+// BearerToken returns the token of an Authorization: Bearer header.
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// JWT rejects requests without a valid bearer token. Tokens must be signed with an algorithm
+// the config has a key for and have an expiry, the claims and the identity of the subject are
+// stored in the request context.
+func JWT(cfg JWTConfig) server.MiddlewareFunc {
+	var methods []string
+	if len(cfg.Secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(cfg.Keys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	parser := jwt.NewParser(opts...)
+	keyFunc := jwtKeyFunc(cfg)
+
+	return func(next server.HandlerFunc) server.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			token, ok := BearerToken(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				return result.UnauthorizedErr("Missing bearer token")
+			}
+
+			claims := &Claims{}
+			if _, err := parser.ParseWithClaims(token, claims, keyFunc); err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				return result.UnauthorizedErr(jwtErrDetail(err))
+			}
+			if claims.Subject == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				return result.UnauthorizedErr("Token has no subject")
+			}
+
+			ctx := WithClaims(r.Context(), claims)
+			ctx = WithIdentity(ctx, Identity{Subject: claims.Subject, Roles: claims.Roles})
+			return next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+func jwtKeyFunc(cfg JWTConfig) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		if token.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+			return cfg.Secret, nil
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, ok := cfg.Keys.Key(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key, nil
+	}
+}
+
+// jwtErrDetail tells clients why a token was rejected without echoing parser internals.
+func jwtErrDetail(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "Token is expired"
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return "Token is not valid yet"
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return "Token is missing a required claim"
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return "Token has an invalid audience"
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return "Token has an invalid issuer"
+	default:
+		return "Invalid token"
+	}
+}
@@ -0,0 +1,228 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	testJWTAudience = "weather-radar"
+	testJWTIssuer   = "https://sso.example.com"
+)
+
+func TestJWT(t *testing.T) {
+	secret := []byte("test-secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Expected no error generating rsa key, got %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error generating ec key, got %v", err)
+	}
+	keys, err := ParseJWKS(testJWKS(t, &rsaKey.PublicKey, &ecKey.PublicKey))
+	if err != nil {
+		t.Fatalf("Expected no error parsing jwks, got %v", err)
+	}
+
+	var got *Claims
+	handler := JWT(JWTConfig{
+		Secret:   secret,
+		Keys:     keys,
+		Audience: testJWTAudience,
+		Issuer:   testJWTIssuer,
+	})(func(w http.ResponseWriter, r *http.Request) error {
+		got, _ = ClaimsFromContext(r.Context())
+		identity, _ := IdentityFromContext(r.Context())
+		if identity.Subject != got.Subject {
+			t.Errorf("Expected identity of subject %q, got %q", got.Subject, identity.Subject)
+		}
+		return nil
+	})
+
+	now := time.Now()
+	valid := func() *Claims {
+		return &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "ana",
+				Audience:  jwt.ClaimStrings{testJWTAudience},
+				Issuer:    testJWTIssuer,
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+				NotBefore: jwt.NewNumericDate(now.Add(-time.Minute)),
+			},
+			Roles: []string{"reader"},
+		}
+	}
+	sign := func(method jwt.SigningMethod, kid string, key any, claims *Claims) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("Expected no error signing token, got %v", err)
+		}
+		return signed
+	}
+	with := func(modify func(c *Claims)) *Claims {
+		c := valid()
+		modify(c)
+		return c
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantDetail string
+	}{
+		{"HS256", sign(jwt.SigningMethodHS256, "", secret, valid()), ""},
+		{"RS256", sign(jwt.SigningMethodRS256, "rsa", rsaKey, valid()), ""},
+		{"ES256", sign(jwt.SigningMethodES256, "ec", ecKey, valid()), ""},
+		{"missing token", "", "Missing bearer token"},
+		{"wrong secret", sign(jwt.SigningMethodHS256, "", []byte("guess"), valid()), "Invalid token"},
+		{"unknown kid", sign(jwt.SigningMethodRS256, "other", rsaKey, valid()), "Invalid token"},
+		{"key of another type", sign(jwt.SigningMethodRS256, "ec", rsaKey, valid()), "Invalid token"},
+		{"unsupported algorithm", sign(jwt.SigningMethodHS512, "", secret, valid()), "Invalid token"},
+		{"unsigned", sign(jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, valid()), "Invalid token"},
+		{"expired", sign(jwt.SigningMethodHS256, "", secret, with(func(c *Claims) {
+			c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute))
+		})), "Token is expired"},
+		{"without expiry", sign(jwt.SigningMethodHS256, "", secret, with(func(c *Claims) {
+			c.ExpiresAt = nil
+		})), "Token is missing a required claim"},
+		{"not valid yet", sign(jwt.SigningMethodHS256, "", secret, with(func(c *Claims) {
+			c.NotBefore = jwt.NewNumericDate(now.Add(time.Minute))
+		})), "Token is not valid yet"},
+		{"other audience", sign(jwt.SigningMethodHS256, "", secret, with(func(c *Claims) {
+			c.Audience = jwt.ClaimStrings{"other"}
+		})), "Token has an invalid audience"},
+		{"other issuer", sign(jwt.SigningMethodHS256, "", secret, with(func(c *Claims) {
+			c.Issuer = "https://evil.example.com"
+		})), "Token has an invalid issuer"},
+		{"without subject", sign(jwt.SigningMethodHS256, "", secret, with(func(c *Claims) {
+			c.Subject = ""
+		})), "Token has no subject"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+
+			err := handler(rec, req)
+
+			if tt.wantDetail == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if got == nil || got.Subject != "ana" || len(got.Roles) != 1 {
+					t.Fatalf("Expected claims of ana stored in the context, got %+v", got)
+				}
+				return
+			}
+			var rErr *result.Err
+			if !errors.As(err, &rErr) || rErr.Status != http.StatusUnauthorized || rErr.Detail != tt.wantDetail {
+				t.Fatalf("Expected unauthorized error %q, got %v", tt.wantDetail, err)
+			}
+			if rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("Expected WWW-Authenticate header to be set")
+			}
+		})
+	}
+}
+
+func TestJWT_HS256RequiresSecret(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Expected no error generating rsa key, got %v", err)
+	}
+	keys, _ := ParseJWKS(testJWKS(t, &rsaKey.PublicKey, nil))
+	handler := JWT(JWTConfig{Keys: keys})(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	// Without a secret, an HS256 token must not be verified with the empty key or a public key.
+	claims := jwt.RegisteredClaims{Subject: "ana", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte{})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	if err := handler(httptest.NewRecorder(), req); err == nil {
+		t.Fatalf("Expected HS256 token to be rejected without a secret")
+	}
+}
+
+func TestParseJWKS_RejectsInvalidKeys(t *testing.T) {
+	tests := map[string]string{
+		"not json":         `keys`,
+		"no keys":          `{"keys": []}`,
+		"only encryption":  `{"keys": [{"kty": "RSA", "use": "enc", "n": "AQAB", "e": "AQAB"}]}`,
+		"unsupported type": `{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`,
+		"short rsa key":    `{"keys": [{"kty": "RSA", "n": "AQAB", "e": "AQAB"}]}`,
+		"other curve":      `{"keys": [{"kty": "EC", "crv": "P-384", "x": "AQAB", "y": "AQAB"}]}`,
+		"point off curve":  `{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQAB", "y": "AQAB"}]}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseJWKS([]byte(data)); err == nil {
+				t.Fatalf("Expected an error parsing %s", data)
+			}
+		})
+	}
+}
+
+// testJWKS builds a key set with the RSA key as kid "rsa" and the EC key as kid "ec".
+func testJWKS(t *testing.T, rsaKey *rsa.PublicKey, ecKey *ecdsa.PublicKey) []byte {
+	t.Helper()
+	encode := func(b []byte) string {
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	var keys []map[string]string
+	if rsaKey != nil {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"kid": "rsa",
+			"use": "sig",
+			"n":   encode(rsaKey.N.Bytes()),
+			"e":   encode(big.NewInt(int64(rsaKey.E)).Bytes()),
+		})
+	}
+	if ecKey != nil {
+		ecdhKey, err := ecKey.ECDH()
+		if err != nil {
+			t.Fatalf("Expected no error converting ec key, got %v", err)
+		}
+		// The uncompressed point is 0x04 followed by the 32 byte X and Y coordinates.
+		point := ecdhKey.Bytes()
+		keys = append(keys, map[string]string{
+			"kty": "EC",
+			"kid": "ec",
+			"crv": "P-256",
+			"x":   encode(point[1:33]),
+			"y":   encode(point[33:]),
+		})
+	}
+
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatalf("Expected no error encoding jwks, got %v", err)
+	}
+	return data
+}
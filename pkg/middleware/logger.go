@@ -12,15 +12,18 @@ func Logger() server.MiddlewareFunc {
 		return func(w http.ResponseWriter, r *http.Request) error {
 			ctx := r.Context()
 			start := time.Now()
+			clientIP := ClientFromRequest(r).Addr()
 			slog.LogAttrs(ctx, slog.LevelInfo, "REQUEST",
 				slog.String("uri", r.URL.Path),
 				slog.String("method", r.Method),
+				slog.String("client_ip", clientIP),
 			)
 			err := next(w, r)
 			duration := time.Since(start)
 			slog.LogAttrs(ctx, slog.LevelInfo, "REQUEST",
 				slog.String("uri", r.URL.Path),
 				slog.String("method", r.Method),
+				slog.String("client_ip", clientIP),
 				slog.Duration("duration", duration),
 			)
 
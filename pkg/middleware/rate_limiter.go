@@ -1,8 +1,11 @@
 package middleware
 
 import (
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/resp"
 	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
 	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"math"
 	"net/http"
 	"strconv"
 	"sync/atomic"
@@ -13,36 +16,66 @@ type Limit struct {
 	Exceeded  bool
 	Limit     int
 	Remaining int
-	Reset     time.Time
+	// Reset is when the request would be allowed, if exceeded, or when the full limit is available.
+	Reset time.Time
+	// Window is the period Limit requests are allowed in.
+	Window time.Duration
 }
 type Limiter interface {
 	AddAndCheckLimit(r *http.Request) (Limit, error)
+	// Stop releases the resources of the limiter, it must not be used afterward.
+	Stop()
 }
 
 func RateLimit(limiter Limiter) server.MiddlewareFunc {
 	return func(next server.HandlerFunc) server.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) error {
-			if r.Method == http.MethodOptions {
-				return next(w, r)
-			}
+			return limitRequest(limiter, next, w, r)
+		}
+	}
+}
 
-			limit, err := limiter.AddAndCheckLimit(r)
-			if err != nil {
-				return result.InternalServerErr(err.Error())
-			}
+func limitRequest(limiter Limiter, next server.HandlerFunc, w http.ResponseWriter, r *http.Request) error {
+	if r.Method == http.MethodOptions {
+		return next(w, r)
+	}
 
-			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit.Limit))
-			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(limit.Remaining))
-			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(limit.Reset.Unix(), 10))
+	limit, err := limiter.AddAndCheckLimit(r)
+	if err != nil {
+		return result.InternalServerErr(err.Error())
+	}
 
-			if limit.Exceeded {
-				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
-				return nil
-			}
+	if limit.Exceeded {
+		return writeRateLimited(w, limit, "Rate limit exceeded")
+	}
+	setRateLimitHeaders(w.Header(), limit, time.Now())
 
-			return next(w, r)
-		}
+	return next(w, r)
+}
+
+// writeRateLimited answers a request over the limit. The response is written rather than returned,
+// so it does not depend on the error handling of the server the middleware runs in.
+func writeRateLimited(w http.ResponseWriter, limit Limit, detail string) error {
+	now := time.Now()
+	setRateLimitHeaders(w.Header(), limit, now)
+	retryAfter := max(limit.Reset.Sub(now), time.Second)
+	return resp.WriteProblemJSON(w, result.TooManyRequestsErr(detail, retryAfter))
+}
+
+// setRateLimitHeaders sets the RateLimit headers of the IETF draft, with Reset in seconds from now,
+// and the legacy X-RateLimit headers, with Reset as a Unix time.
+func setRateLimitHeaders(h http.Header, limit Limit, now time.Time) {
+	resetIn := strconv.FormatInt(int64(math.Ceil(max(0, limit.Reset.Sub(now).Seconds()))), 10)
+	h.Set("RateLimit-Limit", strconv.Itoa(limit.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(limit.Remaining))
+	h.Set("RateLimit-Reset", resetIn)
+	if limit.Window > 0 {
+		h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Limit, int64(math.Ceil(limit.Window.Seconds()))))
 	}
+
+	h.Set("X-RateLimit-Limit", strconv.Itoa(limit.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(limit.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(limit.Reset.Unix(), 10))
 }
 
 type clientLimit struct {
@@ -50,6 +83,22 @@ type clientLimit struct {
 	windowStart  atomic.Int64
 }
 
-func getClientID(r *http.Request) string {
-	return r.RemoteAddr
+// ClientID keys requests made with an API key on the tenant, so all keys and addresses
+// of a tenant share its budget. Anonymous requests are keyed on the client, see ClientResolver.
+func ClientID(r *http.Request) string {
+	if tenant, ok := TenantFromContext(r.Context()); ok {
+		return "tenant:" + tenant.ID
+	}
+	return ClientFromRequest(r).Key
+}
+
+// maxRequestsFor returns the requests allowed per window for the request. The requests per minute
+// of a tenant's plan are scaled to the window, anonymous requests get the limiter's own maximum.
+func maxRequestsFor(r *http.Request, window time.Duration, limiterMax int32) int32 {
+	tenant, ok := TenantFromContext(r.Context())
+	if !ok || tenant.RequestsPerMinute <= 0 {
+		return limiterMax
+	}
+	maxRequests := int64(tenant.RequestsPerMinute) * int64(window) / int64(time.Minute)
+	return int32(max(1, min(maxRequests, math.MaxInt32)))
 }
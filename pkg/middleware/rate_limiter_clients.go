@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultMaxClients = 100_000
+
+	limiterCleanupInterval = 1 * time.Minute
+	// evictFraction of the clients is evicted at once when the cap is reached, so a flood of new
+	// clients does not scan all clients on every request.
+	evictFraction = 10
+)
+
+// clientStore holds the limiter state of every client. Clients idle for longer than the idle
+// timeout are removed in the background, and the least recently seen clients are evicted when
+// more than maxClients are tracked.
+type clientStore[T any] struct {
+	clients     sync.Map
+	size        atomic.Int64
+	idleTimeout time.Duration
+	maxClients  int64
+
+	evictMx  sync.Mutex
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+type trackedClient[T any] struct {
+	state    *T
+	lastSeen atomic.Int64
+}
+
+// newClientStore starts the cleanup of idle clients. The idle timeout is at least two windows,
+// the longest any algorithm looks back, so removing an idle client never resets its limit early.
+func newClientStore[T any](window, idleTimeout time.Duration, maxClients int) *clientStore[T] {
+	if maxClients <= 0 {
+		maxClients = DefaultMaxClients
+	}
+	cs := &clientStore[T]{
+		idleTimeout: max(idleTimeout, 2*window),
+		maxClients:  int64(maxClients),
+		stopCh:      make(chan struct{}),
+	}
+	go cs.startCleanupLoop(limiterCleanupInterval)
+	return cs
+}
+
+// get returns the state of the client, creating it with newState for new clients.
+func (cs *clientStore[T]) get(clientID string, now time.Time, newState func() *T) *T {
+	if c, ok := cs.clients.Load(clientID); ok {
+		client := c.(*trackedClient[T])
+		client.lastSeen.Store(now.UnixNano())
+		return client.state
+	}
+
+	client := &trackedClient[T]{state: newState()}
+	client.lastSeen.Store(now.UnixNano())
+	c, loaded := cs.clients.LoadOrStore(clientID, client)
+	if loaded {
+		client = c.(*trackedClient[T])
+		client.lastSeen.Store(now.UnixNano())
+		return client.state
+	}
+
+	if cs.size.Add(1) > cs.maxClients {
+		cs.evict()
+	}
+	return client.state
+}
+
+func (cs *clientStore[T]) len() int {
+	return int(cs.size.Load())
+}
+
+// Stop stops the cleanup of idle clients.
+func (cs *clientStore[T]) Stop() {
+	cs.stopOnce.Do(func() {
+		close(cs.stopCh)
+	})
+}
+
+func (cs *clientStore[T]) startCleanupLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			cs.cleanup(time.Now())
+		case <-cs.stopCh:
+			return
+		}
+	}
+}
+
+// cleanup removes the clients idle for longer than the idle timeout.
+func (cs *clientStore[T]) cleanup(now time.Time) {
+	idleSince := now.Add(-cs.idleTimeout).UnixNano()
+	removed := 0
+	cs.clients.Range(func(key, value any) bool {
+		if value.(*trackedClient[T]).lastSeen.Load() < idleSince && cs.clients.CompareAndDelete(key, value) {
+			cs.size.Add(-1)
+			removed++
+		}
+		return true
+	})
+	if removed > 0 {
+		slog.Debug("Removed idle rate limit clients", slog.Int("removed", removed), slog.Int("clients", cs.len()))
+	}
+}
+
+// evict removes the least recently seen clients until a tenth of the cap is free again.
+func (cs *clientStore[T]) evict() {
+	if !cs.evictMx.TryLock() {
+		// Another request is already evicting.
+		return
+	}
+	defer cs.evictMx.Unlock()
+
+	type seen struct {
+		key      any
+		client   any
+		lastSeen int64
+	}
+	var clients []seen
+	cs.clients.Range(func(key, value any) bool {
+		clients = append(clients, seen{key: key, client: value, lastSeen: value.(*trackedClient[T]).lastSeen.Load()})
+		return true
+	})
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].lastSeen < clients[j].lastSeen
+	})
+
+	target := cs.maxClients - max(1, cs.maxClients/evictFraction)
+	evicted := 0
+	for _, c := range clients {
+		if cs.size.Load() <= target {
+			break
+		}
+		if cs.clients.CompareAndDelete(c.key, c.client) {
+			cs.size.Add(-1)
+			evicted++
+		}
+	}
+	slog.Warn("Rate limit clients cap reached, evicted least recently seen clients",
+		slog.Int("evicted", evicted), slog.Int64("max_clients", cs.maxClients))
+}
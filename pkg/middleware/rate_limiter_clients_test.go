@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientStore_Cleanup(t *testing.T) {
+	store := newClientStore[clientLimit](time.Second, time.Minute, 0)
+	defer store.Stop()
+
+	start := time.Unix(1_000_000, 0)
+	store.get("idle", start, func() *clientLimit { return &clientLimit{} })
+	store.get("active", start, func() *clientLimit { return &clientLimit{} })
+	store.get("active", start.Add(50*time.Second), func() *clientLimit { return &clientLimit{} })
+
+	store.cleanup(start.Add(time.Minute + time.Second))
+
+	if store.len() != 1 {
+		t.Fatalf("Expected 1 client left, got %d", store.len())
+	}
+	if _, ok := store.clients.Load("idle"); ok {
+		t.Errorf("Expected the idle client to be removed")
+	}
+	if _, ok := store.clients.Load("active"); !ok {
+		t.Errorf("Expected the active client to be kept")
+	}
+}
+
+func TestClientStore_IdleTimeoutCoversTwoWindows(t *testing.T) {
+	store := newClientStore[clientLimit](time.Minute, time.Second, 0)
+	defer store.Stop()
+
+	start := time.Unix(1_000_000, 0)
+	store.get("client", start, func() *clientLimit { return &clientLimit{} })
+
+	store.cleanup(start.Add(90 * time.Second))
+	if store.len() != 1 {
+		t.Fatalf("Expected client to be kept within two windows, got %d clients", store.len())
+	}
+}
+
+func TestClientStore_EvictsLeastRecentlySeen(t *testing.T) {
+	store := newClientStore[clientLimit](time.Second, time.Minute, 10)
+	defer store.Stop()
+
+	start := time.Unix(1_000_000, 0)
+	for i := 0; i < 10; i++ {
+		store.get(fmt.Sprintf("client-%d", i), start.Add(time.Duration(i)*time.Second), func() *clientLimit { return &clientLimit{} })
+	}
+	// Seen again, client-0 is now the most recent one.
+	store.get("client-0", start.Add(time.Minute), func() *clientLimit { return &clientLimit{} })
+
+	store.get("client-new", start.Add(time.Minute), func() *clientLimit { return &clientLimit{} })
+
+	if store.len() != 9 {
+		t.Fatalf("Expected a tenth of the cap to be freed, got %d clients", store.len())
+	}
+	for _, id := range []string{"client-1", "client-2"} {
+		if _, ok := store.clients.Load(id); ok {
+			t.Errorf("Expected least recently seen %s to be evicted", id)
+		}
+	}
+	for _, id := range []string{"client-0", "client-3", "client-new"} {
+		if _, ok := store.clients.Load(id); !ok {
+			t.Errorf("Expected %s to be kept", id)
+		}
+	}
+}
+
+func TestFixedWindowLimiter_MaxClients(t *testing.T) {
+	limiter := NewFixedWindowLimiter(FixedWindowLimiterConfig{Window: time.Minute, MaxRequests: 1, MaxClients: 20})
+	defer limiter.Stop()
+
+	for i := 0; i < 100; i++ {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = fmt.Sprintf("10.0.0.%d:1312", i)
+		if _, err := limiter.AddAndCheckLimit(req); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if n := limiter.clients.len(); n > 20 {
+		t.Fatalf("Expected at most 20 tracked clients, got %d", n)
+	}
+
+	limiter.Stop()
+	limiter.Stop()
+}
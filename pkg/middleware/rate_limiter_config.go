@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LimiterAlgorithm selects how a limiter counts the requests of a window.
+type LimiterAlgorithm string
+
+const (
+	// AlgorithmFixedWindow counts requests per window. Clients can send twice the limit around
+	// the end of a window.
+	AlgorithmFixedWindow LimiterAlgorithm = "fixed-window"
+	// AlgorithmSlidingLog remembers the time of every allowed request and counts those of the
+	// last window. It is exact, but keeps up to the limit of timestamps per client.
+	AlgorithmSlidingLog LimiterAlgorithm = "sliding-log"
+	// AlgorithmSlidingWindow weights the count of the previous window by how much of it overlaps
+	// the last window. It approximates the sliding log with two counters per client.
+	AlgorithmSlidingWindow LimiterAlgorithm = "sliding-window"
+	// AlgorithmTokenBucket refills the limit evenly over the window and allows bursts of up to
+	// the limit.
+	AlgorithmTokenBucket LimiterAlgorithm = "token-bucket"
+)
+
+var limiterAlgorithms = []LimiterAlgorithm{
+	AlgorithmFixedWindow,
+	AlgorithmSlidingLog,
+	AlgorithmSlidingWindow,
+	AlgorithmTokenBucket,
+}
+
+func LimiterAlgorithms() []LimiterAlgorithm {
+	return limiterAlgorithms
+}
+
+func ParseLimiterAlgorithm(s string) (LimiterAlgorithm, bool) {
+	for _, a := range limiterAlgorithms {
+		if strings.EqualFold(strings.TrimSpace(s), string(a)) {
+			return a, true
+		}
+	}
+	return "", false
+}
+
+// LimiterConfig allows MaxRequests per Window with the algorithm. An empty algorithm is a fixed window.
+type LimiterConfig struct {
+	Algorithm   LimiterAlgorithm
+	Window      time.Duration
+	MaxRequests int32
+	// IdleTimeout is how long a client is tracked after its last request, at least two windows.
+	IdleTimeout time.Duration
+	// MaxClients caps the clients tracked, the least recently seen are evicted above it.
+	// Defaults to DefaultMaxClients.
+	MaxClients int
+	// Store shares the counts between replicas, only the fixed and sliding window algorithms
+	// support it. Without a store every replica limits on its own.
+	Store CounterStore
+	// Name distinguishes the counts of limiters sharing a store, it is required with a store.
+	Name string
+}
+
+// NewLimiter creates the limiter of the configured algorithm. With a store, the limiter falls
+// back to a local limiter of the same algorithm while the store is unreachable.
+func NewLimiter(config LimiterConfig) (Limiter, error) {
+	if config.Window <= 0 || config.MaxRequests <= 0 {
+		return nil, fmt.Errorf("rate limit window and max requests must be positive, got %v and %d", config.Window, config.MaxRequests)
+	}
+	if config.Store == nil {
+		return newLocalLimiter(config)
+	}
+
+	if config.Name == "" {
+		return nil, errors.New("rate limit name is required with a store")
+	}
+	switch config.Algorithm {
+	case "", AlgorithmFixedWindow, AlgorithmSlidingWindow:
+	default:
+		return nil, fmt.Errorf("rate limit algorithm %q does not support a store", config.Algorithm)
+	}
+	local, err := newLocalLimiter(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreLimiter(config, local), nil
+}
+
+func newLocalLimiter(config LimiterConfig) (Limiter, error) {
+	switch config.Algorithm {
+	case "", AlgorithmFixedWindow:
+		return NewFixedWindowLimiter(FixedWindowLimiterConfig{
+			Window:      config.Window,
+			MaxRequests: config.MaxRequests,
+			IdleTimeout: config.IdleTimeout,
+			MaxClients:  config.MaxClients,
+		}), nil
+	case AlgorithmSlidingLog:
+		return NewSlidingWindowLogLimiter(config), nil
+	case AlgorithmSlidingWindow:
+		return NewSlidingWindowCounterLimiter(config), nil
+	case AlgorithmTokenBucket:
+		return NewTokenBucketLimiter(config), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit algorithm %q", config.Algorithm)
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testClock is a manually advanced clock for limiters, starting at the beginning of a 10s window.
+type testClock struct {
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Unix(1_000_000, 0)}
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Set(sinceStart time.Duration) {
+	c.now = time.Unix(1_000_000, 0).Add(sinceStart)
+}
+
+// checkLimit sends a request from the test address and returns the limit the limiter reports.
+func checkLimit(t *testing.T, limiter Limiter) Limit {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "127.0.0.1:1312"
+	limit, err := limiter.AddAndCheckLimit(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return limit
+}
+
+func TestNewLimiter(t *testing.T) {
+	tests := []struct {
+		algorithm LimiterAlgorithm
+		want      Limiter
+	}{
+		{"", &FixedWindowLimiter{}},
+		{AlgorithmFixedWindow, &FixedWindowLimiter{}},
+		{AlgorithmSlidingLog, &SlidingWindowLogLimiter{}},
+		{AlgorithmSlidingWindow, &SlidingWindowCounterLimiter{}},
+		{AlgorithmTokenBucket, &TokenBucketLimiter{}},
+	}
+	for _, tt := range tests {
+		limiter, err := NewLimiter(LimiterConfig{Algorithm: tt.algorithm, Window: time.Minute, MaxRequests: 10})
+		if err != nil {
+			t.Fatalf("Expected no error for %q, got %v", tt.algorithm, err)
+		}
+		if got, want := typeName(limiter), typeName(tt.want); got != want {
+			t.Errorf("Expected %s for %q, got %s", want, tt.algorithm, got)
+		}
+	}
+
+	if _, err := NewLimiter(LimiterConfig{Algorithm: "leaky-bucket", Window: time.Minute, MaxRequests: 10}); err == nil {
+		t.Errorf("Expected an error for an unknown algorithm")
+	}
+	if _, err := NewLimiter(LimiterConfig{Window: time.Minute}); err == nil {
+		t.Errorf("Expected an error without max requests")
+	}
+}
+
+func TestParseLimiterAlgorithm(t *testing.T) {
+	if a, ok := ParseLimiterAlgorithm(" Token-Bucket "); !ok || a != AlgorithmTokenBucket {
+		t.Errorf("Expected token-bucket, got %q, %v", a, ok)
+	}
+	if _, ok := ParseLimiterAlgorithm("leaky-bucket"); ok {
+		t.Errorf("Expected leaky-bucket to be unknown")
+	}
+}
+
+func typeName(v any) string {
+	return fmt.Sprintf("%T", v)
+}
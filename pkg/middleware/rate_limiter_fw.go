@@ -2,7 +2,6 @@ package middleware
 
 import (
 	"net/http"
-	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -10,31 +9,40 @@ import (
 type FixedWindowLimiterConfig struct {
 	Window      time.Duration
 	MaxRequests int32
+	// IdleTimeout and MaxClients bound the clients tracked, see LimiterConfig.
+	IdleTimeout time.Duration
+	MaxClients  int
 }
 
 type FixedWindowLimiter struct {
 	window      time.Duration
 	maxRequests int32
-	clients     sync.Map
+	clients     *clientStore[clientLimit]
 }
 
 func NewFixedWindowLimiter(config FixedWindowLimiterConfig) *FixedWindowLimiter {
 	limiter := &FixedWindowLimiter{
 		window:      config.Window,
 		maxRequests: config.MaxRequests,
+		clients:     newClientStore[clientLimit](config.Window, config.IdleTimeout, config.MaxClients),
 	}
 	return limiter
 }
 
+// Stop stops the cleanup of idle clients.
+func (fw *FixedWindowLimiter) Stop() {
+	fw.clients.Stop()
+}
+
 func (fw *FixedWindowLimiter) AddAndCheckLimit(r *http.Request) (Limit, error) {
-	clientID := getClientID(r)
+	clientID := ClientID(r)
+	maxRequests := maxRequestsFor(r, fw.window, fw.maxRequests)
 
-	cl, _ := fw.clients.LoadOrStore(clientID, &clientLimit{
-		requestCount: 0,
+	now := time.Now()
+	client := fw.clients.get(clientID, now, func() *clientLimit {
+		return &clientLimit{requestCount: 0}
 	})
-	client := cl.(*clientLimit)
 
-	now := time.Now()
 	currentWindowStart := client.windowStart.Load()
 
 	if now.UnixNano()-currentWindowStart >= fw.window.Nanoseconds() {
@@ -45,8 +53,8 @@ func (fw *FixedWindowLimiter) AddAndCheckLimit(r *http.Request) (Limit, error) {
 	}
 
 	requestCount := atomic.LoadInt32(&client.requestCount)
-	limitExceeded := requestCount > fw.maxRequests
-	remaining := fw.maxRequests - requestCount
+	limitExceeded := requestCount > maxRequests
+	remaining := maxRequests - requestCount
 
 	if remaining < 0 {
 		remaining = 0
@@ -54,8 +62,9 @@ func (fw *FixedWindowLimiter) AddAndCheckLimit(r *http.Request) (Limit, error) {
 	resetTime := time.Unix(0, client.windowStart.Load()).Add(fw.window)
 	return Limit{
 		Exceeded:  limitExceeded,
-		Limit:     int(fw.maxRequests),
+		Limit:     int(maxRequests),
 		Remaining: int(remaining),
 		Reset:     resetTime,
+		Window:    fw.window,
 	}, nil
 }
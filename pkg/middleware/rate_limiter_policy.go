@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Client classes a rate limit rule can apply to.
+const (
+	// ClientAnonymous are requests without an API key.
+	ClientAnonymous = "anonymous"
+	// ClientTenant are requests with an API key of any plan.
+	ClientTenant = "tenant"
+	// ClientPlanPrefix followed by a plan name, e.g. plan:free, are requests with a key of the plan.
+	ClientPlanPrefix = "plan:"
+)
+
+// RateLimitPolicy maps routes, methods and client classes to rate limits. Rules are matched in
+// order and the first matching one applies, requests no rule matches are not limited.
+type RateLimitPolicy struct {
+	Rules []RateLimitRule `yaml:"rules"`
+}
+
+// RateLimitRule limits the requests to its routes. All routes of a rule share its limit.
+type RateLimitRule struct {
+	// Name identifies the counts of the rule in a shared store.
+	Name string `yaml:"name"`
+	// Routes are route patterns as registered, e.g. /api/v1/weather/feedback/{id}. A pattern
+	// ending in /* also matches the routes below it, * matches all routes.
+	Routes []string `yaml:"routes"`
+	// Methods the rule applies to, all methods when empty.
+	Methods []string `yaml:"methods"`
+	// Clients are the client classes the rule applies to, all clients when empty.
+	Clients []string `yaml:"clients"`
+	// Algorithm defaults to the configured one.
+	Algorithm LimiterAlgorithm `yaml:"algorithm"`
+	Window    time.Duration    `yaml:"window"`
+	// MaxRequests per window of anonymous clients. Tenants are allowed the requests per minute of
+	// their plan, scaled to the window.
+	MaxRequests int32 `yaml:"max_requests"`
+	// Unlimited exempts the matching requests from later rules.
+	Unlimited bool `yaml:"unlimited"`
+}
+
+// LoadRateLimitPolicy reads a policy file in YAML or JSON.
+func LoadRateLimitPolicy(path string) (*RateLimitPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate limit policy: %w", err)
+	}
+	return ParseRateLimitPolicy(data)
+}
+
+// ParseRateLimitPolicy parses and validates a policy in YAML, or JSON which is valid YAML.
+func ParseRateLimitPolicy(data []byte) (*RateLimitPolicy, error) {
+	var policy RateLimitPolicy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse rate limit policy: %w", err)
+	}
+
+	names := make(map[string]bool)
+	for i := range policy.Rules {
+		rule := &policy.Rules[i]
+		if err := rule.normalize(); err != nil {
+			return nil, fmt.Errorf("rate limit rule %d: %w", i+1, err)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("rate limit rule %d: duplicate name %q", i+1, rule.Name)
+		}
+		names[rule.Name] = true
+	}
+	return &policy, nil
+}
+
+func (rule *RateLimitRule) normalize() error {
+	if rule.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(rule.Routes) == 0 {
+		return errors.New("at least one route is required")
+	}
+	for _, route := range rule.Routes {
+		if route != "*" && !strings.HasPrefix(route, "/") {
+			return fmt.Errorf("route %q must start with /", route)
+		}
+	}
+	for i, method := range rule.Methods {
+		rule.Methods[i] = strings.ToUpper(method)
+	}
+	for _, class := range rule.Clients {
+		if class != ClientAnonymous && class != ClientTenant && !strings.HasPrefix(class, ClientPlanPrefix) {
+			return fmt.Errorf("unknown client class %q, expected %s, %s or %s<plan>", class, ClientAnonymous, ClientTenant, ClientPlanPrefix)
+		}
+	}
+	if rule.Algorithm != "" {
+		algorithm, ok := ParseLimiterAlgorithm(string(rule.Algorithm))
+		if !ok {
+			return fmt.Errorf("algorithm must be one of: %v", LimiterAlgorithms())
+		}
+		rule.Algorithm = algorithm
+	}
+	if !rule.Unlimited && (rule.Window <= 0 || rule.MaxRequests <= 0) {
+		return errors.New("window and max_requests must be positive, or the rule unlimited")
+	}
+	return nil
+}
+
+func (rule *RateLimitRule) matchesRoute(method, route string) bool {
+	if len(rule.Methods) > 0 && !slices.Contains(rule.Methods, method) {
+		return false
+	}
+	for _, pattern := range rule.Routes {
+		if pattern == "*" || pattern == route {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(route, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule *RateLimitRule) matchesClient(r *http.Request) bool {
+	if len(rule.Clients) == 0 {
+		return true
+	}
+	tenant, ok := TenantFromContext(r.Context())
+	for _, class := range rule.Clients {
+		switch {
+		case class == ClientAnonymous && !ok,
+			class == ClientTenant && ok,
+			ok && strings.EqualFold(class, ClientPlanPrefix+tenant.Plan):
+			return true
+		}
+	}
+	return false
+}
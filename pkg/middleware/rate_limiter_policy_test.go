@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testPolicyYAML = `
+rules:
+  - name: partners
+    routes: ["*"]
+    clients: [plan:partner]
+    unlimited: true
+  - name: weather
+    routes: [/api/v1/weather, /api/v1/weather/forecast]
+    methods: [get]
+    algorithm: Token-Bucket
+    window: 1m
+    max_requests: 10
+  - name: feedback
+    routes: [/api/v1/weather/feedback/*]
+    clients: [anonymous, tenant]
+    window: 30s
+    max_requests: 5
+`
+
+func TestParseRateLimitPolicy(t *testing.T) {
+	policy, err := ParseRateLimitPolicy([]byte(testPolicyYAML))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(policy.Rules) != 3 {
+		t.Fatalf("Expected 3 rules, got %d", len(policy.Rules))
+	}
+	weather := policy.Rules[1]
+	if weather.Algorithm != AlgorithmTokenBucket || weather.Window != time.Minute || weather.MaxRequests != 10 {
+		t.Errorf("Expected token bucket of 10 per minute, got %+v", weather)
+	}
+	if weather.Methods[0] != http.MethodGet {
+		t.Errorf("Expected methods upper cased, got %v", weather.Methods)
+	}
+
+	json := `{"rules": [{"name": "search", "routes": ["/api/v1/locations/search"], "window": "10s", "max_requests": 3}]}`
+	policy, err = ParseRateLimitPolicy([]byte(json))
+	if err != nil {
+		t.Fatalf("Expected JSON policy to parse, got %v", err)
+	}
+	if rule := policy.Rules[0]; rule.Window != 10*time.Second || rule.MaxRequests != 3 {
+		t.Errorf("Expected 3 per 10s, got %+v", rule)
+	}
+}
+
+func TestParseRateLimitPolicy_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"Missing name":     `rules: [{routes: [/a], window: 1m, max_requests: 1}]`,
+		"Missing routes":   `rules: [{name: a, window: 1m, max_requests: 1}]`,
+		"Relative route":   `rules: [{name: a, routes: [a], window: 1m, max_requests: 1}]`,
+		"Duplicate name":   `rules: [{name: a, routes: [/a], unlimited: true}, {name: a, routes: [/b], unlimited: true}]`,
+		"Unknown client":   `rules: [{name: a, routes: [/a], clients: [bots], window: 1m, max_requests: 1}]`,
+		"Unknown algo":     `rules: [{name: a, routes: [/a], algorithm: leaky-bucket, window: 1m, max_requests: 1}]`,
+		"Missing limit":    `rules: [{name: a, routes: [/a], window: 1m}]`,
+		"Invalid duration": `rules: [{name: a, routes: [/a], window: soon, max_requests: 1}]`,
+	}
+	for name, policy := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseRateLimitPolicy([]byte(policy)); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestRateLimitRule_Matches(t *testing.T) {
+	policy, err := ParseRateLimitPolicy([]byte(testPolicyYAML))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	partners, weather, feedback := policy.Rules[0], policy.Rules[1], policy.Rules[2]
+
+	if !weather.matchesRoute(http.MethodGet, "/api/v1/weather/forecast") {
+		t.Error("Expected weather rule to match GET forecast")
+	}
+	if weather.matchesRoute(http.MethodPost, "/api/v1/weather") {
+		t.Error("Expected weather rule not to match POST")
+	}
+	if !feedback.matchesRoute(http.MethodDelete, "/api/v1/weather/feedback/{id}") {
+		t.Error("Expected wildcard to match the routes below it")
+	}
+	if feedback.matchesRoute(http.MethodGet, "/api/v1/weather/feedbacks") {
+		t.Error("Expected wildcard not to match a longer segment")
+	}
+
+	anonymous := httptest.NewRequest(http.MethodGet, "/", nil)
+	partner := anonymous.WithContext(WithTenant(anonymous.Context(), Tenant{ID: "acme", Plan: "partner"}))
+	if partners.matchesClient(anonymous) || !partners.matchesClient(partner) {
+		t.Error("Expected plan rule to match tenants of the plan only")
+	}
+	if !feedback.matchesClient(anonymous) || !feedback.matchesClient(partner) {
+		t.Error("Expected anonymous and tenant classes to match both")
+	}
+}
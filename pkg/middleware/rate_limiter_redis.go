@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/redis"
+	"strconv"
+	"time"
+)
+
+// windowCountScript counts a request in KEYS[1] if the count plus ARGV[1] times the count of the
+// previous window in KEYS[2] stays within ARGV[2]. A window key expires after ARGV[3] milliseconds.
+const windowCountScript = `
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+if previous * tonumber(ARGV[1]) + current + 1 > tonumber(ARGV[2]) then
+	return {0, current, previous}
+end
+current = redis.call('INCR', KEYS[1])
+if current == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return {1, current, previous}
+`
+
+// RedisCounterStore keeps window counts in Redis. The keys of a client share a hash tag, so
+// a script can read both windows on Redis Cluster.
+type RedisCounterStore struct {
+	client *redis.Client
+	script *redis.Script
+}
+
+func NewRedisCounterStore(client *redis.Client) *RedisCounterStore {
+	return &RedisCounterStore{
+		client: client,
+		script: redis.NewScript(windowCountScript),
+	}
+}
+
+func (rs *RedisCounterStore) AddWithinLimit(ctx context.Context, key string, windowStart time.Time, window time.Duration, previousWeight float64, limit int32) (WindowCount, error) {
+	keys := []string{
+		windowKey(key, windowStart),
+		windowKey(key, windowStart.Add(-window)),
+	}
+	// A window is read as the previous one during the next window, so it is kept for two.
+	ttl := 2 * window.Milliseconds()
+
+	reply, err := rs.script.Run(ctx, rs.client, keys,
+		strconv.FormatFloat(previousWeight, 'f', -1, 64),
+		strconv.FormatInt(int64(limit), 10),
+		strconv.FormatInt(ttl, 10))
+	if err != nil {
+		return WindowCount{}, err
+	}
+
+	values, ok := reply.([]any)
+	if !ok || len(values) != 3 {
+		return WindowCount{}, fmt.Errorf("unexpected rate limit script reply %v", reply)
+	}
+	counts := make([]int64, len(values))
+	for i, v := range values {
+		if counts[i], ok = v.(int64); !ok {
+			return WindowCount{}, fmt.Errorf("unexpected rate limit script reply %v", reply)
+		}
+	}
+	return WindowCount{Allowed: counts[0] == 1, Current: counts[1], Previous: counts[2]}, nil
+}
+
+func windowKey(key string, windowStart time.Time) string {
+	return "rate-limit:{" + key + "}:" + strconv.FormatInt(windowStart.UnixMilli(), 10)
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"github.com/DjordjeVuckovic/weather-radar/pkg/redis"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/redis/redistest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newRedisStore starts a stand-in server running the Go equivalent of windowCountScript.
+func newRedisStore(t *testing.T) (*redistest.Server, *RedisCounterStore) {
+	t.Helper()
+	srv := redistest.NewServer()
+	srv.RegisterScript(windowCountScript, runWindowCountScript)
+	client := redis.NewClient(srv.Addr(), redis.WithDialTimeout(100*time.Millisecond))
+	t.Cleanup(func() {
+		_ = client.Close()
+		srv.Close()
+	})
+	return srv, NewRedisCounterStore(client)
+}
+
+func runWindowCountScript(db *redistest.DB, keys, args []string) (any, error) {
+	current, err := counterValue(db, keys[0])
+	if err != nil {
+		return nil, err
+	}
+	previous, err := counterValue(db, keys[1])
+	if err != nil {
+		return nil, err
+	}
+	weight, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	if float64(previous)*weight+float64(current)+1 > float64(limit) {
+		return []any{int64(0), current, previous}, nil
+	}
+	if current, err = db.Incr(keys[0]); err != nil {
+		return nil, err
+	}
+	if current == 1 {
+		db.PExpire(keys[0], ttl)
+	}
+	return []any{int64(1), current, previous}, nil
+}
+
+func counterValue(db *redistest.DB, key string) (int64, error) {
+	value, ok := db.Get(key)
+	if !ok {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func TestRedisCounterStore(t *testing.T) {
+	t.Run("Share limit between replicas", func(t *testing.T) {
+		_, store := newRedisStore(t)
+		config := LimiterConfig{Name: "weather", Window: time.Minute, MaxRequests: 3, Store: store}
+		replicas := make([]Limiter, 2)
+		for i := range replicas {
+			limiter, err := NewLimiter(config)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer limiter.Stop()
+			replicas[i] = limiter
+		}
+
+		for i := 0; i < 3; i++ {
+			limit := checkLimit(t, replicas[i%2])
+			if limit.Exceeded || limit.Remaining != 2-i {
+				t.Fatalf("Expected request %d allowed with %d remaining, got %+v", i+1, 2-i, limit)
+			}
+		}
+		for _, replica := range replicas {
+			if limit := checkLimit(t, replica); !limit.Exceeded {
+				t.Fatalf("Expected the shared limit to be exceeded on every replica, got %+v", limit)
+			}
+		}
+	})
+
+	t.Run("Separate limiters by name", func(t *testing.T) {
+		_, store := newRedisStore(t)
+		weather := NewStoreLimiter(LimiterConfig{Name: "weather", Window: time.Minute, MaxRequests: 1, Store: store}, &failingLimiter{})
+		search := NewStoreLimiter(LimiterConfig{Name: "search", Window: time.Minute, MaxRequests: 1, Store: store}, &failingLimiter{})
+
+		if checkLimit(t, weather).Exceeded || checkLimit(t, search).Exceeded {
+			t.Fatal("Expected the first request of each limiter to be allowed")
+		}
+		if !checkLimit(t, weather).Exceeded {
+			t.Fatal("Expected the second weather request to be denied")
+		}
+	})
+
+	t.Run("Weight previous window", func(t *testing.T) {
+		_, store := newRedisStore(t)
+		clock := newTestClock()
+		limiter := NewStoreLimiter(LimiterConfig{
+			Name:        "weather",
+			Algorithm:   AlgorithmSlidingWindow,
+			Window:      10 * time.Second,
+			MaxRequests: 10,
+			Store:       store,
+		}, &failingLimiter{})
+		limiter.now = clock.Now
+
+		clock.Set(9 * time.Second)
+		for i := 0; i < 10; i++ {
+			checkLimit(t, limiter)
+		}
+
+		// 1s into the next window, 90% of the previous one still counts: 9 of 10 requests.
+		clock.Set(11 * time.Second)
+		limit := checkLimit(t, limiter)
+		if limit.Exceeded || limit.Remaining != 0 {
+			t.Fatalf("Expected a single request allowed, got %+v", limit)
+		}
+		if want := time.Unix(1_000_020, 0); !limit.Reset.Equal(want) {
+			t.Errorf("Expected reset at the end of the window %v, got %v", want, limit.Reset)
+		}
+		if limit := checkLimit(t, limiter); !limit.Exceeded {
+			t.Fatal("Expected burst at the boundary to be denied")
+		}
+	})
+
+	t.Run("Fall back to local limiter when unreachable", func(t *testing.T) {
+		srv, store := newRedisStore(t)
+		limiter, err := NewLimiter(LimiterConfig{Name: "weather", Window: time.Minute, MaxRequests: 2, Store: store})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer limiter.Stop()
+
+		checkLimit(t, limiter)
+		srv.Close()
+
+		// The local limiter starts counting on its own.
+		for i := 0; i < 2; i++ {
+			if limit := checkLimit(t, limiter); limit.Exceeded {
+				t.Fatalf("Expected request %d to be allowed locally, got %+v", i+1, limit)
+			}
+		}
+		if limit := checkLimit(t, limiter); !limit.Exceeded {
+			t.Fatalf("Expected the local limit to be exceeded, got %+v", limit)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RouteLimiter applies a rate limit policy to routes as they are registered, see
+// server.Server.UseRoute. The policy can be replaced while serving.
+type RouteLimiter struct {
+	base   LimiterConfig
+	policy atomic.Pointer[routePolicy]
+
+	// updateMx serializes updates, so the limiters of a replaced policy are stopped once.
+	updateMx sync.Mutex
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// routePolicy holds the limiter of every rule, nil for unlimited rules.
+type routePolicy struct {
+	rules    []RateLimitRule
+	limiters []Limiter
+}
+
+// NewRouteLimiter creates the limiters of the policy. Rules without an algorithm use the one of
+// base, as do all rules for client bounds and the store.
+func NewRouteLimiter(policy *RateLimitPolicy, base LimiterConfig) (*RouteLimiter, error) {
+	rl := &RouteLimiter{
+		base:   base,
+		stopCh: make(chan struct{}),
+	}
+	if err := rl.Update(policy); err != nil {
+		return nil, err
+	}
+	return rl, nil
+}
+
+// Update replaces the policy. Counts start over, unless they are kept in a store. On error the
+// current policy stays in place.
+func (rl *RouteLimiter) Update(policy *RateLimitPolicy) error {
+	next := &routePolicy{
+		rules:    policy.Rules,
+		limiters: make([]Limiter, len(policy.Rules)),
+	}
+	for i, rule := range policy.Rules {
+		if rule.Unlimited {
+			continue
+		}
+		limiter, err := rl.newLimiter(rule)
+		if err != nil {
+			next.stop()
+			return err
+		}
+		next.limiters[i] = limiter
+	}
+
+	rl.updateMx.Lock()
+	defer rl.updateMx.Unlock()
+	if previous := rl.policy.Swap(next); previous != nil {
+		previous.stop()
+	}
+	return nil
+}
+
+func (rl *RouteLimiter) newLimiter(rule RateLimitRule) (Limiter, error) {
+	config := rl.base
+	config.Name = rule.Name
+	config.Window = rule.Window
+	config.MaxRequests = rule.MaxRequests
+	if rule.Algorithm != "" {
+		config.Algorithm = rule.Algorithm
+	}
+	return NewLimiter(config)
+}
+
+// Route returns the rate limit middleware of a route. The rule is matched on every request, since
+// the client class is only known then and the policy may change.
+func (rl *RouteLimiter) Route(method, route string) server.MiddlewareFunc {
+	return func(next server.HandlerFunc) server.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			limiter := rl.policy.Load().limiterFor(method, route, r)
+			if limiter == nil {
+				return next(w, r)
+			}
+			return limitRequest(limiter, next, w, r)
+		}
+	}
+}
+
+// Watch reloads the policy file when it changes, checking every interval. An invalid file is
+// logged and the current policy kept.
+func (rl *RouteLimiter) Watch(path string, interval time.Duration) {
+	lastMod := modTime(path)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				mod := modTime(path)
+				if mod.Equal(lastMod) {
+					continue
+				}
+				lastMod = mod
+				rl.reload(path)
+			case <-rl.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (rl *RouteLimiter) reload(path string) {
+	policy, err := LoadRateLimitPolicy(path)
+	if err == nil {
+		err = rl.Update(policy)
+	}
+	if err != nil {
+		slog.Error("Failed to reload rate limit policy, keeping the current one",
+			slog.String("path", path),
+			slog.String("error", err.Error()))
+		return
+	}
+	slog.Info("Reloaded rate limit policy", slog.String("path", path), slog.Int("rules", len(policy.Rules)))
+}
+
+// Stop stops watching the policy file and the limiters.
+func (rl *RouteLimiter) Stop() {
+	rl.stopOnce.Do(func() {
+		close(rl.stopCh)
+		rl.updateMx.Lock()
+		defer rl.updateMx.Unlock()
+		rl.policy.Load().stop()
+	})
+}
+
+func (p *routePolicy) limiterFor(method, route string, r *http.Request) Limiter {
+	for i := range p.rules {
+		if p.rules[i].matchesRoute(method, route) && p.rules[i].matchesClient(r) {
+			return p.limiters[i]
+		}
+	}
+	return nil
+}
+
+func (p *routePolicy) stop() {
+	for _, limiter := range p.limiters {
+		if limiter != nil {
+			limiter.Stop()
+		}
+	}
+}
+
+// modTime returns the modification time of the file, or the zero time when it cannot be read.
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestRouteLimiter(t *testing.T, policyYAML string) *RouteLimiter {
+	t.Helper()
+	policy, err := ParseRateLimitPolicy([]byte(policyYAML))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rl, err := NewRouteLimiter(policy, LimiterConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(rl.Stop)
+	return rl
+}
+
+// sendRouted sends a request through the middleware the route limiter created for the route.
+func sendRouted(rl *RouteLimiter, method, route string, tenant *Tenant) int {
+	handler := rl.Route(method, route)(server.TestOKHandler())
+	req := httptest.NewRequest(method, "/", nil)
+	req.RemoteAddr = "127.0.0.1:1312"
+	if tenant != nil {
+		req = req.WithContext(WithTenant(req.Context(), *tenant))
+	}
+	rec := httptest.NewRecorder()
+	_ = handler(rec, req)
+	return rec.Code
+}
+
+func TestRouteLimiter(t *testing.T) {
+	t.Run("Share limit between routes of a rule", func(t *testing.T) {
+		rl := newTestRouteLimiter(t, testPolicyYAML)
+
+		for i := 0; i < 10; i++ {
+			route := []string{"/api/v1/weather", "/api/v1/weather/forecast"}[i%2]
+			if code := sendRouted(rl, http.MethodGet, route, nil); code != http.StatusOK {
+				t.Fatalf("Expected request %d allowed, got %d", i+1, code)
+			}
+		}
+		if code := sendRouted(rl, http.MethodGet, "/api/v1/weather", nil); code != http.StatusTooManyRequests {
+			t.Fatalf("Expected the shared limit exceeded, got %d", code)
+		}
+		if code := sendRouted(rl, http.MethodGet, "/api/v1/locations/search", nil); code != http.StatusOK {
+			t.Fatalf("Expected routes without a rule to be unlimited, got %d", code)
+		}
+	})
+
+	t.Run("Exempt unlimited client class", func(t *testing.T) {
+		rl := newTestRouteLimiter(t, testPolicyYAML)
+		partner := &Tenant{ID: "acme", Plan: "partner", RequestsPerMinute: 1}
+
+		for i := 0; i < 20; i++ {
+			if code := sendRouted(rl, http.MethodPost, "/api/v1/weather/feedback/{id}", partner); code != http.StatusOK {
+				t.Fatalf("Expected partner request %d allowed, got %d", i+1, code)
+			}
+		}
+	})
+
+	t.Run("Apply updated policy", func(t *testing.T) {
+		rl := newTestRouteLimiter(t, testPolicyYAML)
+		policy, err := ParseRateLimitPolicy([]byte(`rules: [{name: weather, routes: [/api/v1/weather], window: 1m, max_requests: 1}]`))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := rl.Update(policy); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		sendRouted(rl, http.MethodGet, "/api/v1/weather", nil)
+		if code := sendRouted(rl, http.MethodGet, "/api/v1/weather", nil); code != http.StatusTooManyRequests {
+			t.Fatalf("Expected the updated limit, got %d", code)
+		}
+	})
+
+	t.Run("Keep policy when update fails", func(t *testing.T) {
+		rl := newTestRouteLimiter(t, `rules: [{name: search, routes: [/search], window: 1m, max_requests: 1}]`)
+		invalid := &RateLimitPolicy{Rules: []RateLimitRule{{Name: "search", Routes: []string{"/search"}, Algorithm: "leaky-bucket", Window: time.Minute, MaxRequests: 5}}}
+		if err := rl.Update(invalid); err == nil {
+			t.Fatal("Expected an error for an unknown algorithm")
+		}
+
+		sendRouted(rl, http.MethodGet, "/search", nil)
+		if code := sendRouted(rl, http.MethodGet, "/search", nil); code != http.StatusTooManyRequests {
+			t.Fatalf("Expected the previous limit kept, got %d", code)
+		}
+	})
+}
+
+func TestRouteLimiter_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rate_limits.yaml")
+	writePolicy := func(policy string, modTime time.Time) {
+		if err := os.WriteFile(path, []byte(policy), 0o600); err != nil {
+			t.Fatalf("Failed to write policy: %v", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set modification time: %v", err)
+		}
+	}
+	start := time.Now().Add(-time.Hour)
+	writePolicy(`rules: [{name: search, routes: [/search], window: 1m, max_requests: 100}]`, start)
+
+	policy, err := LoadRateLimitPolicy(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rl, err := NewRouteLimiter(policy, LimiterConfig{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer rl.Stop()
+	rl.Watch(path, 10*time.Millisecond)
+
+	// An invalid file is ignored, the next valid one is applied.
+	writePolicy(`rules: [{name: search}]`, start.Add(time.Minute))
+	time.Sleep(50 * time.Millisecond)
+	writePolicy(`rules: [{name: search, routes: [/search], window: 1m, max_requests: 1}]`, start.Add(2*time.Minute))
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if code := sendRouted(rl, http.MethodGet, "/search", nil); code == http.StatusTooManyRequests {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the changed policy to be applied")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// storeTimeout bounds the latency a store adds to a request.
+	storeTimeout = 100 * time.Millisecond
+	// storeRetryInterval is how long requests are limited locally after the store failed.
+	storeRetryInterval = 5 * time.Second
+)
+
+// CounterStore keeps request counts of fixed windows shared by the replicas of the service.
+type CounterStore interface {
+	// AddWithinLimit counts a request in the window of the key starting at windowStart, if the
+	// count of the window plus previousWeight times the count of the window before stays within
+	// limit. The check and the increment are atomic.
+	AddWithinLimit(ctx context.Context, key string, windowStart time.Time, window time.Duration, previousWeight float64, limit int32) (WindowCount, error)
+}
+
+// WindowCount is the result of CounterStore.AddWithinLimit. Current includes the request when
+// it was allowed.
+type WindowCount struct {
+	Allowed  bool
+	Current  int64
+	Previous int64
+}
+
+// StoreLimiter counts requests in a CounterStore, so all replicas share the limit. While the
+// store is unreachable it falls back to a local limiter, which limits each replica on its own.
+type StoreLimiter struct {
+	name        string
+	window      time.Duration
+	maxRequests int32
+	weighted    bool
+	store       CounterStore
+	local       Limiter
+	now         func() time.Time
+
+	// unavailableUntil is when to try the store again, in Unix nanoseconds.
+	unavailableUntil atomic.Int64
+}
+
+// NewStoreLimiter creates a limiter of the fixed or sliding window algorithm of the config on
+// config.Store, falling back to local.
+func NewStoreLimiter(config LimiterConfig, local Limiter) *StoreLimiter {
+	return &StoreLimiter{
+		name:        config.Name,
+		window:      config.Window,
+		maxRequests: config.MaxRequests,
+		weighted:    config.Algorithm == AlgorithmSlidingWindow,
+		store:       config.Store,
+		local:       local,
+		now:         time.Now,
+	}
+}
+
+// Stop stops the local limiter.
+func (sl *StoreLimiter) Stop() {
+	sl.local.Stop()
+}
+
+func (sl *StoreLimiter) AddAndCheckLimit(r *http.Request) (Limit, error) {
+	now := sl.now()
+	if now.UnixNano() < sl.unavailableUntil.Load() {
+		return sl.local.AddAndCheckLimit(r)
+	}
+
+	limit, err := sl.addToStore(r, now)
+	if err != nil {
+		// Only the request that marks the store unavailable logs, not every one until the retry.
+		retryAt := now.Add(storeRetryInterval).UnixNano()
+		if sl.unavailableUntil.Swap(retryAt) <= now.UnixNano() {
+			slog.Warn("Rate limit store unavailable, limiting locally",
+				slog.String("limiter", sl.name),
+				slog.Duration("retry_in", storeRetryInterval),
+				slog.String("error", err.Error()))
+		}
+		return sl.local.AddAndCheckLimit(r)
+	}
+	return limit, nil
+}
+
+func (sl *StoreLimiter) addToStore(r *http.Request, nowTime time.Time) (Limit, error) {
+	maxRequests := maxRequestsFor(r, sl.window, sl.maxRequests)
+	now := nowTime.UnixNano()
+	window := sl.window.Nanoseconds()
+	windowStart := now - now%window
+
+	var previousWeight float64
+	if sl.weighted {
+		previousWeight = float64(window-(now-windowStart)) / float64(window)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), storeTimeout)
+	defer cancel()
+	count, err := sl.store.AddWithinLimit(ctx, sl.name+":"+ClientID(r), time.Unix(0, windowStart), sl.window, previousWeight, maxRequests)
+	if err != nil {
+		return Limit{}, err
+	}
+
+	estimated := float64(count.Previous)*previousWeight + float64(count.Current)
+	return Limit{
+		Exceeded:  !count.Allowed,
+		Limit:     int(maxRequests),
+		Remaining: max(0, int(float64(maxRequests)-estimated)),
+		Reset:     time.Unix(0, windowStart+window),
+		Window:    sl.window,
+	}, nil
+}
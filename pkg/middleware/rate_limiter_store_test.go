@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// flakyStore allows every request, or fails while down.
+type flakyStore struct {
+	down  bool
+	calls int
+}
+
+func (fs *flakyStore) AddWithinLimit(_ context.Context, _ string, _ time.Time, _ time.Duration, _ float64, _ int32) (WindowCount, error) {
+	fs.calls++
+	if fs.down {
+		return WindowCount{}, errors.New("connection refused")
+	}
+	return WindowCount{Allowed: true, Current: 1}, nil
+}
+
+// failingLimiter denies every request, marking where the local fallback was used.
+type failingLimiter struct {
+	calls int
+}
+
+func (fl *failingLimiter) AddAndCheckLimit(_ *http.Request) (Limit, error) {
+	fl.calls++
+	return Limit{Exceeded: true}, nil
+}
+
+func (fl *failingLimiter) Stop() {}
+
+func TestStoreLimiter(t *testing.T) {
+	t.Run("Retry store after interval", func(t *testing.T) {
+		clock := newTestClock()
+		store := &flakyStore{down: true}
+		local := &failingLimiter{}
+		limiter := NewStoreLimiter(LimiterConfig{Name: "weather", Window: time.Minute, MaxRequests: 10, Store: store}, local)
+		limiter.now = clock.Now
+
+		for i := 0; i < 3; i++ {
+			if limit := checkLimit(t, limiter); !limit.Exceeded {
+				t.Fatalf("Expected request %d to use the local limiter", i+1)
+			}
+		}
+		if store.calls != 1 || local.calls != 3 {
+			t.Fatalf("Expected the store tried once and 3 local requests, got %d and %d", store.calls, local.calls)
+		}
+
+		store.down = false
+		clock.Set(storeRetryInterval - time.Millisecond)
+		checkLimit(t, limiter)
+		if store.calls != 1 {
+			t.Fatalf("Expected the store not to be retried before the interval, got %d calls", store.calls)
+		}
+
+		clock.Set(storeRetryInterval)
+		if limit := checkLimit(t, limiter); limit.Exceeded {
+			t.Fatalf("Expected the store to be used again after the interval, got %+v", limit)
+		}
+		if store.calls != 2 {
+			t.Fatalf("Expected the store to be retried, got %d calls", store.calls)
+		}
+	})
+
+	t.Run("Reject unsupported config", func(t *testing.T) {
+		store := &flakyStore{}
+		if _, err := NewLimiter(LimiterConfig{Window: time.Minute, MaxRequests: 10, Store: store}); err == nil {
+			t.Error("Expected an error without a name")
+		}
+		for _, algorithm := range []LimiterAlgorithm{AlgorithmSlidingLog, AlgorithmTokenBucket} {
+			config := LimiterConfig{Name: "weather", Algorithm: algorithm, Window: time.Minute, MaxRequests: 10, Store: store}
+			if _, err := NewLimiter(config); err == nil {
+				t.Errorf("Expected an error for %s with a store", algorithm)
+			}
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SlidingWindowCounterLimiter estimates the requests of the last window from the counts of the
+// current and the previous fixed window, assuming the previous requests were evenly spread.
+type SlidingWindowCounterLimiter struct {
+	window      time.Duration
+	maxRequests int32
+	clients     *clientStore[slidingCounterClient]
+	now         func() time.Time
+}
+
+type slidingCounterClient struct {
+	mx            sync.Mutex
+	windowStart   int64
+	currentCount  int32
+	previousCount int32
+}
+
+func NewSlidingWindowCounterLimiter(config LimiterConfig) *SlidingWindowCounterLimiter {
+	return &SlidingWindowCounterLimiter{
+		window:      config.Window,
+		maxRequests: config.MaxRequests,
+		clients:     newClientStore[slidingCounterClient](config.Window, config.IdleTimeout, config.MaxClients),
+		now:         time.Now,
+	}
+}
+
+// Stop stops the cleanup of idle clients.
+func (sc *SlidingWindowCounterLimiter) Stop() {
+	sc.clients.Stop()
+}
+
+func (sc *SlidingWindowCounterLimiter) AddAndCheckLimit(r *http.Request) (Limit, error) {
+	maxRequests := maxRequestsFor(r, sc.window, sc.maxRequests)
+	nowTime := sc.now()
+	client := sc.clients.get(ClientID(r), nowTime, func() *slidingCounterClient {
+		return &slidingCounterClient{}
+	})
+
+	client.mx.Lock()
+	defer client.mx.Unlock()
+
+	now := nowTime.UnixNano()
+	window := sc.window.Nanoseconds()
+	windowStart := now - now%window
+	if windowStart != client.windowStart {
+		if windowStart-client.windowStart == window {
+			client.previousCount = client.currentCount
+		} else {
+			client.previousCount = 0
+		}
+		client.currentCount = 0
+		client.windowStart = windowStart
+	}
+
+	previousWeight := float64(window-(now-windowStart)) / float64(window)
+	estimated := float64(client.previousCount)*previousWeight + float64(client.currentCount)
+
+	// Rejected requests are not counted, so a client retrying too fast is not locked out.
+	exceeded := estimated+1 > float64(maxRequests)
+	if !exceeded {
+		client.currentCount++
+		estimated++
+	}
+
+	return Limit{
+		Exceeded:  exceeded,
+		Limit:     int(maxRequests),
+		Remaining: max(0, int(float64(maxRequests)-estimated)),
+		Reset:     time.Unix(0, windowStart+window),
+		Window:    sc.window,
+	}, nil
+}
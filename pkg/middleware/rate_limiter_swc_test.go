@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlidingWindowCounterLimiter(t *testing.T) {
+	t.Run("Deny requests exceeding limit", func(t *testing.T) {
+		limiter := NewSlidingWindowCounterLimiter(LimiterConfig{Window: 3 * time.Second, MaxRequests: 2})
+
+		for i := 0; i < 2; i++ {
+			if limit := checkLimit(t, limiter); limit.Exceeded {
+				t.Fatalf("Expected request %d to be allowed", i+1)
+			}
+		}
+		if limit := checkLimit(t, limiter); !limit.Exceeded || limit.Remaining != 0 {
+			t.Fatalf("Expected third request to be denied, got %+v", limit)
+		}
+	})
+
+	t.Run("Weight previous window at boundary", func(t *testing.T) {
+		clock := newTestClock()
+		limiter := NewSlidingWindowCounterLimiter(LimiterConfig{Window: 10 * time.Second, MaxRequests: 10})
+		limiter.now = clock.Now
+
+		clock.Set(9 * time.Second)
+		for i := 0; i < 10; i++ {
+			checkLimit(t, limiter)
+		}
+
+		// 1s into the next window, 90% of the previous one still counts: 9 of 10 requests.
+		clock.Set(11 * time.Second)
+		limit := checkLimit(t, limiter)
+		if limit.Exceeded || limit.Remaining != 0 {
+			t.Fatalf("Expected a single request allowed, got %+v", limit)
+		}
+		if want := time.Unix(1_000_020, 0); !limit.Reset.Equal(want) {
+			t.Errorf("Expected reset at the end of the window %v, got %v", want, limit.Reset)
+		}
+		if limit := checkLimit(t, limiter); !limit.Exceeded {
+			t.Fatalf("Expected burst at the boundary to be denied")
+		}
+
+		// Half way through, half of the previous window counts: 5 plus the 1 allowed request.
+		clock.Set(15 * time.Second)
+		allowed := 0
+		for i := 0; i < 10; i++ {
+			if !checkLimit(t, limiter).Exceeded {
+				allowed++
+			}
+		}
+		if allowed != 4 {
+			t.Fatalf("Expected 4 requests allowed half way through the window, got %d", allowed)
+		}
+	})
+
+	t.Run("Forget windows older than the previous one", func(t *testing.T) {
+		clock := newTestClock()
+		limiter := NewSlidingWindowCounterLimiter(LimiterConfig{Window: 10 * time.Second, MaxRequests: 2})
+		limiter.now = clock.Now
+
+		checkLimit(t, limiter)
+		checkLimit(t, limiter)
+
+		clock.Set(25 * time.Second)
+		if limit := checkLimit(t, limiter); limit.Exceeded || limit.Remaining != 1 {
+			t.Fatalf("Expected a full budget two windows later, got %+v", limit)
+		}
+	})
+}
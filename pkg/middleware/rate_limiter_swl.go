@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SlidingWindowLogLimiter allows a client MaxRequests in any window of the configured length.
+type SlidingWindowLogLimiter struct {
+	window      time.Duration
+	maxRequests int32
+	clients     *clientStore[slidingLogClient]
+	now         func() time.Time
+}
+
+type slidingLogClient struct {
+	mx sync.Mutex
+	// requests holds the unix nano times of the allowed requests of the last window, oldest first.
+	requests []int64
+}
+
+func NewSlidingWindowLogLimiter(config LimiterConfig) *SlidingWindowLogLimiter {
+	return &SlidingWindowLogLimiter{
+		window:      config.Window,
+		maxRequests: config.MaxRequests,
+		clients:     newClientStore[slidingLogClient](config.Window, config.IdleTimeout, config.MaxClients),
+		now:         time.Now,
+	}
+}
+
+// Stop stops the cleanup of idle clients.
+func (sl *SlidingWindowLogLimiter) Stop() {
+	sl.clients.Stop()
+}
+
+func (sl *SlidingWindowLogLimiter) AddAndCheckLimit(r *http.Request) (Limit, error) {
+	maxRequests := maxRequestsFor(r, sl.window, sl.maxRequests)
+	nowTime := sl.now()
+	client := sl.clients.get(ClientID(r), nowTime, func() *slidingLogClient {
+		return &slidingLogClient{}
+	})
+
+	client.mx.Lock()
+	defer client.mx.Unlock()
+
+	now := nowTime.UnixNano()
+	windowStart := now - sl.window.Nanoseconds()
+	expired := 0
+	for expired < len(client.requests) && client.requests[expired] <= windowStart {
+		expired++
+	}
+	client.requests = client.requests[expired:]
+
+	// Rejected requests are not logged, so a client retrying too fast is not locked out.
+	exceeded := len(client.requests) >= int(maxRequests)
+	if !exceeded {
+		client.requests = append(client.requests, now)
+	}
+
+	// The oldest request leaving the window frees the next slot.
+	reset := time.Unix(0, client.requests[0]).Add(sl.window)
+	return Limit{
+		Exceeded:  exceeded,
+		Limit:     int(maxRequests),
+		Remaining: max(0, int(maxRequests)-len(client.requests)),
+		Reset:     reset,
+		Window:    sl.window,
+	}, nil
+}
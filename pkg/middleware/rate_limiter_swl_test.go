@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSlidingWindowLogLimiter(t *testing.T) {
+	testAddr := "127.0.0.1:1312"
+
+	t.Run("Allow requests within limit", func(t *testing.T) {
+		handler := RateLimit(NewSlidingWindowLogLimiter(LimiterConfig{Window: 3 * time.Second, MaxRequests: 2}))(server.TestOKHandler())
+
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = testAddr
+			rec := httptest.NewRecorder()
+
+			_ = handler(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("Expected status OK, got %v", rec.Code)
+			}
+		}
+	})
+
+	t.Run("No burst at window boundary", func(t *testing.T) {
+		clock := newTestClock()
+		limiter := NewSlidingWindowLogLimiter(LimiterConfig{Window: 10 * time.Second, MaxRequests: 2})
+		limiter.now = clock.Now
+
+		clock.Set(9 * time.Second)
+		for i := 0; i < 2; i++ {
+			if limit := checkLimit(t, limiter); limit.Exceeded {
+				t.Fatalf("Expected request %d to be allowed", i+1)
+			}
+		}
+
+		// A fixed window would start over at 10s, the log still holds both requests.
+		clock.Set(10*time.Second + 500*time.Millisecond)
+		limit := checkLimit(t, limiter)
+		if !limit.Exceeded {
+			t.Fatalf("Expected request after the boundary to be denied")
+		}
+		if want := clock.now.Add(8500 * time.Millisecond); !limit.Reset.Equal(want) {
+			t.Errorf("Expected reset when the oldest request leaves the window at %v, got %v", want, limit.Reset)
+		}
+
+		clock.Set(19*time.Second + time.Millisecond)
+		if limit := checkLimit(t, limiter); limit.Exceeded || limit.Remaining != 1 {
+			t.Fatalf("Expected request allowed with 1 remaining once the log expired, got %+v", limit)
+		}
+	})
+
+	t.Run("Denied requests are not logged", func(t *testing.T) {
+		clock := newTestClock()
+		limiter := NewSlidingWindowLogLimiter(LimiterConfig{Window: 10 * time.Second, MaxRequests: 1})
+		limiter.now = clock.Now
+
+		checkLimit(t, limiter)
+		clock.Set(5 * time.Second)
+		checkLimit(t, limiter)
+
+		clock.Set(10*time.Second + time.Millisecond)
+		if limit := checkLimit(t, limiter); limit.Exceeded {
+			t.Fatalf("Expected request to be allowed once the allowed one expired")
+		}
+	})
+}
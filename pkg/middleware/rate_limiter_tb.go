@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TokenBucketLimiter gives every client a bucket of MaxRequests tokens, refilled evenly over the
+// window. A request takes a token, so a full bucket allows a burst of MaxRequests.
+type TokenBucketLimiter struct {
+	window      time.Duration
+	maxRequests int32
+	clients     *clientStore[tokenBucketClient]
+	now         func() time.Time
+}
+
+type tokenBucketClient struct {
+	mx         sync.Mutex
+	tokens     float64
+	lastRefill int64
+}
+
+func NewTokenBucketLimiter(config LimiterConfig) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		window:      config.Window,
+		maxRequests: config.MaxRequests,
+		clients:     newClientStore[tokenBucketClient](config.Window, config.IdleTimeout, config.MaxClients),
+		now:         time.Now,
+	}
+}
+
+// Stop stops the cleanup of idle clients.
+func (tb *TokenBucketLimiter) Stop() {
+	tb.clients.Stop()
+}
+
+func (tb *TokenBucketLimiter) AddAndCheckLimit(r *http.Request) (Limit, error) {
+	maxRequests := maxRequestsFor(r, tb.window, tb.maxRequests)
+	capacity := float64(maxRequests)
+	// tokensPerNano is the refill rate, the whole bucket refills in one window.
+	tokensPerNano := capacity / float64(tb.window.Nanoseconds())
+
+	nowTime := tb.now()
+	now := nowTime.UnixNano()
+	client := tb.clients.get(ClientID(r), nowTime, func() *tokenBucketClient {
+		return &tokenBucketClient{tokens: capacity, lastRefill: now}
+	})
+
+	client.mx.Lock()
+	defer client.mx.Unlock()
+
+	client.tokens = math.Min(capacity, client.tokens+float64(now-client.lastRefill)*tokensPerNano)
+	client.lastRefill = now
+
+	exceeded := client.tokens < 1
+	if !exceeded {
+		client.tokens--
+	}
+
+	// Rejected clients can retry once a token is back, others have their full budget back once
+	// the bucket is refilled.
+	missing := capacity - client.tokens
+	if exceeded {
+		missing = 1 - client.tokens
+	}
+	reset := time.Unix(0, now+int64(math.Ceil(missing/tokensPerNano)))
+
+	return Limit{
+		Exceeded:  exceeded,
+		Limit:     int(maxRequests),
+		Remaining: int(client.tokens),
+		Reset:     reset,
+		Window:    tb.window,
+	}, nil
+}
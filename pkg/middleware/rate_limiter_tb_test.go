@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucketLimiter(t *testing.T) {
+	t.Run("Allow burst up to capacity", func(t *testing.T) {
+		clock := newTestClock()
+		limiter := NewTokenBucketLimiter(LimiterConfig{Window: 10 * time.Second, MaxRequests: 2})
+		limiter.now = clock.Now
+
+		for i := 0; i < 2; i++ {
+			if limit := checkLimit(t, limiter); limit.Exceeded {
+				t.Fatalf("Expected request %d to be allowed", i+1)
+			}
+		}
+		limit := checkLimit(t, limiter)
+		if !limit.Exceeded || limit.Remaining != 0 {
+			t.Fatalf("Expected third request to be denied, got %+v", limit)
+		}
+		if want := clock.now.Add(5 * time.Second); !limit.Reset.Equal(want) {
+			t.Errorf("Expected reset when the next token is back at %v, got %v", want, limit.Reset)
+		}
+	})
+
+	t.Run("Refill evenly over the window", func(t *testing.T) {
+		clock := newTestClock()
+		limiter := NewTokenBucketLimiter(LimiterConfig{Window: 10 * time.Second, MaxRequests: 2})
+		limiter.now = clock.Now
+
+		checkLimit(t, limiter)
+		checkLimit(t, limiter)
+
+		// A fixed window would allow a new burst at the boundary, the bucket only has one token back.
+		clock.Set(5 * time.Second)
+		if limit := checkLimit(t, limiter); limit.Exceeded {
+			t.Fatalf("Expected request to be allowed after one refill interval")
+		}
+		if limit := checkLimit(t, limiter); !limit.Exceeded {
+			t.Fatalf("Expected request to be denied before the next refill")
+		}
+	})
+
+	t.Run("Do not refill above capacity", func(t *testing.T) {
+		clock := newTestClock()
+		limiter := NewTokenBucketLimiter(LimiterConfig{Window: 10 * time.Second, MaxRequests: 2})
+		limiter.now = clock.Now
+
+		checkLimit(t, limiter)
+		clock.Set(time.Hour)
+		allowed := 0
+		for i := 0; i < 5; i++ {
+			if !checkLimit(t, limiter).Exceeded {
+				allowed++
+			}
+		}
+		if allowed != 2 {
+			t.Fatalf("Expected a burst of 2 after an idle hour, got %d", allowed)
+		}
+	})
+}
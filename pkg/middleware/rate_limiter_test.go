@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"encoding/json"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fixedLimiter reports the same limit for every request.
+type fixedLimiter struct {
+	limit Limit
+}
+
+func (fl *fixedLimiter) AddAndCheckLimit(_ *http.Request) (Limit, error) {
+	return fl.limit, nil
+}
+
+func (fl *fixedLimiter) Stop() {}
+
+func TestRateLimit(t *testing.T) {
+	t.Run("Set rate limit headers", func(t *testing.T) {
+		reset := time.Now().Add(42 * time.Second)
+		limiter := &fixedLimiter{Limit{Limit: 10, Remaining: 7, Reset: reset, Window: time.Minute}}
+		rec := httptest.NewRecorder()
+
+		if err := RateLimit(limiter)(server.TestOKHandler())(rec, httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		want := map[string]string{
+			"RateLimit-Limit":       "10",
+			"RateLimit-Remaining":   "7",
+			"RateLimit-Reset":       "42",
+			"RateLimit-Policy":      "10;w=60",
+			"X-RateLimit-Limit":     "10",
+			"X-RateLimit-Remaining": "7",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset.Unix(), 10),
+		}
+		for name, value := range want {
+			if got := rec.Header().Get(name); got != value {
+				t.Errorf("Expected %s %s, got %q", name, value, got)
+			}
+		}
+		if rec.Header().Get("Retry-After") != "" {
+			t.Error("Expected no Retry-After for an allowed request")
+		}
+	})
+
+	t.Run("Reject with problem JSON and Retry-After", func(t *testing.T) {
+		limiter := &fixedLimiter{Limit{Exceeded: true, Limit: 10, Reset: time.Now().Add(2500 * time.Millisecond), Window: time.Minute}}
+		rec := httptest.NewRecorder()
+
+		if err := RateLimit(limiter)(server.TestOKHandler())(rec, httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("Expected status 429, got %d", rec.Code)
+		}
+		if got := rec.Header().Get("Content-Type"); got != "application/problem+json" {
+			t.Errorf("Expected problem JSON, got %s", got)
+		}
+		if got := rec.Header().Get("Retry-After"); got != "3" {
+			t.Errorf("Expected Retry-After rounded up to 3, got %q", got)
+		}
+		var problem result.Err
+		if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+			t.Fatalf("Failed to decode problem: %v", err)
+		}
+		if problem.Status != http.StatusTooManyRequests || problem.Title != result.TooManyRequests {
+			t.Errorf("Expected a too many requests problem, got %+v", problem)
+		}
+	})
+
+	t.Run("Retry after at least a second", func(t *testing.T) {
+		limiter := &fixedLimiter{Limit{Exceeded: true, Limit: 10, Reset: time.Now().Add(-time.Second)}}
+		rec := httptest.NewRecorder()
+
+		_ = RateLimit(limiter)(server.TestOKHandler())(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if got := rec.Header().Get("Retry-After"); got != "1" {
+			t.Errorf("Expected Retry-After 1, got %q", got)
+		}
+		if got := rec.Header().Get("RateLimit-Reset"); got != "0" {
+			t.Errorf("Expected RateLimit-Reset 0 for a past reset, got %q", got)
+		}
+	})
+}
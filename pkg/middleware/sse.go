@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/server"
+	"net/http"
+)
+
+// ServerSentEvents sets the headers of an event stream and stores the flusher in the request
+// context under CtxFlusherKey, like HTTPStreaming.
+func ServerSentEvents() server.MiddlewareFunc {
+	return func(next server.HandlerFunc) server.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			flusher, ok := w.(http.Flusher)
+			if !ok {
+				http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+				return fmt.Errorf("streaming not supported")
+			}
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			// Keeps reverse proxies such as nginx from buffering the events.
+			w.Header().Set("X-Accel-Buffering", "no")
+
+			ctx := context.WithValue(r.Context(), CtxFlusherKey, flusher)
+			return next(w, r.WithContext(ctx))
+		}
+	}
+}
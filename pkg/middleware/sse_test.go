@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServerSentEvents(t *testing.T) {
+	handler := ServerSentEvents()(func(w http.ResponseWriter, r *http.Request) error {
+		if _, ok := r.Context().Value(CtxFlusherKey).(http.Flusher); !ok {
+			t.Error("Expected the flusher in the request context")
+		}
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	if err := handler(rec, httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected Content-Type text/event-stream, got %s", ct)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("Expected Cache-Control no-cache, got %s", cc)
+	}
+}
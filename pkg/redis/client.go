@@ -0,0 +1,173 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPoolSize    = 10
+	defaultDialTimeout = 1 * time.Second
+)
+
+// Client is a minimal client of the Redis protocol, safe for concurrent use. Connections are
+// pooled and a connection is dropped after a network error.
+type Client struct {
+	addr        string
+	password    string
+	dialTimeout time.Duration
+	pool        chan *conn
+
+	mx     sync.Mutex
+	closed bool
+}
+
+type conn struct {
+	netConn net.Conn
+	r       *bufio.Reader
+	w       *bufio.Writer
+}
+
+type Option func(*Client)
+
+// WithPassword authenticates new connections with AUTH.
+func WithPassword(password string) Option {
+	return func(c *Client) {
+		c.password = password
+	}
+}
+
+// WithPoolSize sets the number of idle connections kept.
+func WithPoolSize(size int) Option {
+	return func(c *Client) {
+		c.pool = make(chan *conn, size)
+	}
+}
+
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.dialTimeout = timeout
+	}
+}
+
+// NewClient creates a client of the server at addr. Connections are opened on first use.
+func NewClient(addr string, opts ...Option) *Client {
+	c := &Client{
+		addr:        addr,
+		dialTimeout: defaultDialTimeout,
+		pool:        make(chan *conn, defaultPoolSize),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Do sends a command and returns its reply, see ReadReply. Error replies are returned as error.
+func (c *Client) Do(ctx context.Context, args ...string) (any, error) {
+	cn, err := c.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.do(ctx, args...)
+	if err != nil {
+		_ = cn.netConn.Close()
+		return nil, err
+	}
+	c.putConn(cn)
+
+	if replyErr, ok := reply.(Error); ok {
+		return nil, replyErr
+	}
+	return reply, nil
+}
+
+// Ping checks the server is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Close closes the idle connections. Connections in use are closed when they are returned.
+func (c *Client) Close() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.closed = true
+	for {
+		select {
+		case cn := <-c.pool:
+			_ = cn.netConn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (c *Client) getConn(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.pool:
+		return cn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: c.dialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{netConn: netConn, r: bufio.NewReader(netConn), w: bufio.NewWriter(netConn)}
+
+	if c.password != "" {
+		reply, err := cn.do(ctx, "AUTH", c.password)
+		if err == nil {
+			if replyErr, ok := reply.(Error); ok {
+				err = replyErr
+			}
+		}
+		if err != nil {
+			_ = netConn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+// putConn returns the connection to the pool, or closes it once the pool is full or closed.
+func (c *Client) putConn(cn *conn) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.closed {
+		_ = cn.netConn.Close()
+		return
+	}
+	select {
+	case c.pool <- cn:
+	default:
+		_ = cn.netConn.Close()
+	}
+}
+
+func (cn *conn) do(ctx context.Context, args ...string) (any, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Time{}
+	}
+	if err := cn.netConn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if err := WriteCommand(cn.w, args...); err != nil {
+		return nil, err
+	}
+	return ReadReply(cn.r)
+}
+
+// IsNoScript reports whether the error is the reply to EVALSHA of a script the server does not have.
+func IsNoScript(err error) bool {
+	var replyErr Error
+	return errors.As(err, &replyErr) && len(replyErr) >= 8 && replyErr[:8] == "NOSCRIPT"
+}
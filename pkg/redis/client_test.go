@@ -0,0 +1,160 @@
+package redis_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/redis"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/redis/redistest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_Do(t *testing.T) {
+	srv := redistest.NewServer()
+	defer srv.Close()
+	client := redis.NewClient(srv.Addr())
+	defer client.Close()
+	ctx := context.Background()
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Failed to ping: %v", err)
+	}
+
+	if _, err := client.Do(ctx, "SET", "city", "Novi Sad"); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+	reply, err := client.Do(ctx, "GET", "city")
+	if err != nil || reply != "Novi Sad" {
+		t.Fatalf("Expected Novi Sad, got %v, %v", reply, err)
+	}
+
+	reply, err = client.Do(ctx, "GET", "missing")
+	if err != nil || reply != nil {
+		t.Fatalf("Expected nil for a missing key, got %v, %v", reply, err)
+	}
+
+	for i := int64(1); i <= 3; i++ {
+		reply, err = client.Do(ctx, "INCR", "counter")
+		if err != nil || reply != i {
+			t.Fatalf("Expected counter %d, got %v, %v", i, reply, err)
+		}
+	}
+
+	_, err = client.Do(ctx, "INCR", "city")
+	var replyErr redis.Error
+	if !errors.As(err, &replyErr) {
+		t.Fatalf("Expected an error reply, got %v", err)
+	}
+
+	// The connection stays usable after an error reply.
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Failed to ping after an error reply: %v", err)
+	}
+}
+
+func TestClient_Auth(t *testing.T) {
+	srv := redistest.NewServer()
+	defer srv.Close()
+	srv.SetPassword("secret")
+	ctx := context.Background()
+
+	client := redis.NewClient(srv.Addr(), redis.WithPassword("secret"))
+	defer client.Close()
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Expected authenticated ping, got %v", err)
+	}
+
+	wrong := redis.NewClient(srv.Addr(), redis.WithPassword("wrong"))
+	defer wrong.Close()
+	if err := wrong.Ping(ctx); err == nil {
+		t.Fatal("Expected wrong password to fail")
+	}
+
+	anonymous := redis.NewClient(srv.Addr())
+	defer anonymous.Close()
+	if err := anonymous.Ping(ctx); err == nil {
+		t.Fatal("Expected ping without password to fail")
+	}
+}
+
+func TestClient_Close(t *testing.T) {
+	srv := redistest.NewServer()
+	defer srv.Close()
+	client := redis.NewClient(srv.Addr())
+	ctx := context.Background()
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Failed to ping: %v", err)
+	}
+	_ = client.Close()
+	// A connection returned after Close is closed, not pooled.
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Failed to ping after close: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for srv.Conns() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the connections closed, %d still open", srv.Conns())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestReadReply_LongArray(t *testing.T) {
+	// The header claims more items than the reply has, no room is reserved for them ahead.
+	_, err := redis.ReadReply(bufio.NewReader(strings.NewReader("*2000000000\r\n:1\r\n")))
+	if err == nil {
+		t.Fatal("Expected an error for a truncated array")
+	}
+}
+
+func TestClient_Unreachable(t *testing.T) {
+	srv := redistest.NewServer()
+	addr := srv.Addr()
+	srv.Close()
+
+	client := redis.NewClient(addr, redis.WithDialTimeout(100*time.Millisecond))
+	defer client.Close()
+	if err := client.Ping(context.Background()); err == nil {
+		t.Fatal("Expected ping of a closed server to fail")
+	}
+}
+
+func TestScript_Run(t *testing.T) {
+	const src = "return redis.call('INCRBY', KEYS[1], ARGV[1])"
+	srv := redistest.NewServer()
+	defer srv.Close()
+	srv.RegisterScript(src, func(db *redistest.DB, keys, args []string) (any, error) {
+		by, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		value, _ := db.Get(keys[0])
+		n, _ := strconv.ParseInt(value, 10, 64)
+		db.Set(keys[0], strconv.FormatInt(n+by, 10))
+		return n + by, nil
+	})
+
+	client := redis.NewClient(srv.Addr())
+	defer client.Close()
+	script := redis.NewScript(src)
+	ctx := context.Background()
+
+	for i, want := range []int64{5, 10} {
+		reply, err := script.Run(ctx, client, []string{"counter"}, "5")
+		if err != nil || reply != want {
+			t.Fatalf("Run %d: expected %d, got %v, %v", i+1, want, reply, err)
+		}
+	}
+
+	// The first run falls back to EVAL, afterward the server knows the script by its hash.
+	want := []string{"EVALSHA", "EVAL", "EVALSHA"}
+	if got := srv.Commands(); !slices.Equal(got, want) {
+		t.Fatalf("Expected commands %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// maxBulkLength bounds the bulk strings read, replies of the commands we use are small.
+const maxBulkLength = 512 * 1024 * 1024
+
+// maxArrayPrealloc bounds the items allocated ahead from an array header, longer arrays grow as
+// their items are read.
+const maxArrayPrealloc = 64
+
+// Error is an error reply of the server, the connection stays usable.
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// WriteCommand writes a command as a RESP array of bulk strings.
+func WriteCommand(w *bufio.Writer, args ...string) error {
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if _, err := fmt.Fprintf(w, "$%d\r\n%s\r\n", len(arg), arg); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// ReadReply reads a RESP reply. Simple and bulk strings are returned as string, a nil bulk string
+// or array as nil, integers as int64, arrays as []any and error replies as Error.
+func ReadReply(r *bufio.Reader) (any, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		return readBulk(r, line[1:])
+	case '*':
+		return readArray(r, line[1:])
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply type %q", line[0])
+	}
+}
+
+func readBulk(r *bufio.Reader, header string) (any, error) {
+	n, err := strconv.Atoi(header)
+	if err != nil || n > maxBulkLength {
+		return nil, fmt.Errorf("redis: invalid bulk length %q", header)
+	}
+	if n < 0 {
+		return nil, nil
+	}
+	buf := make([]byte, n+2)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return string(buf[:n]), nil
+}
+
+func readArray(r *bufio.Reader, header string) (any, error) {
+	n, err := strconv.Atoi(header)
+	if err != nil {
+		return nil, fmt.Errorf("redis: invalid array length %q", header)
+	}
+	if n < 0 {
+		return nil, nil
+	}
+	items := make([]any, 0, min(n, maxArrayPrealloc))
+	for i := 0; i < n; i++ {
+		item, err := ReadReply(r)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errors.New("redis: line does not end with CRLF")
+	}
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,398 @@
+package redistest
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/DjordjeVuckovic/weather-radar/pkg/redis"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status is a simple string reply, like OK.
+type Status string
+
+// ScriptFunc is the Go equivalent of a Lua script, run while the server holds its lock.
+type ScriptFunc func(db *DB, keys, args []string) (any, error)
+
+// Server speaks RESP and supports PING, AUTH, GET, SET, INCR, PEXPIRE, PTTL, DEL, EVAL and EVALSHA.
+// Scripts are not interpreted, a Go function must be registered for the source of every script.
+type Server struct {
+	ln       net.Listener
+	password string
+
+	mx       sync.Mutex
+	data     map[string]entry
+	scripts  map[string]ScriptFunc
+	sources  map[string]string
+	loaded   map[string]bool
+	commands []string
+	conns    map[net.Conn]struct{}
+	now      func() time.Time
+
+	wg sync.WaitGroup
+}
+
+type entry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// NewServer starts a server on a random local port. It panics when it cannot listen, like httptest.
+func NewServer() *Server {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("redistest: failed to listen: %v", err))
+	}
+	s := &Server{
+		ln:      ln,
+		data:    make(map[string]entry),
+		scripts: make(map[string]ScriptFunc),
+		sources: make(map[string]string),
+		loaded:  make(map[string]bool),
+		conns:   make(map[net.Conn]struct{}),
+		now:     time.Now,
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// SetPassword requires clients to AUTH with the password.
+func (s *Server) SetPassword(password string) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.password = password
+}
+
+// SetNow sets the clock keys expire by.
+func (s *Server) SetNow(now func() time.Time) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.now = now
+}
+
+// RegisterScript runs fn for EVAL of the source and EVALSHA of its hash. Like Redis, EVALSHA only
+// knows the script once it was run with EVAL.
+func (s *Server) RegisterScript(src string, fn ScriptFunc) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	hash := scriptHash(src)
+	s.scripts[hash] = fn
+	s.sources[src] = hash
+}
+
+// Commands returns the names of the commands received so far.
+func (s *Server) Commands() []string {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// Conns returns the number of open client connections.
+func (s *Server) Conns() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return len(s.conns)
+}
+
+// Close stops the server and closes the connections of its clients.
+func (s *Server) Close() {
+	_ = s.ln.Close()
+	s.mx.Lock()
+	for c := range s.conns {
+		_ = c.Close()
+	}
+	s.mx.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mx.Lock()
+		s.conns[c] = struct{}{}
+		s.mx.Unlock()
+
+		s.wg.Add(1)
+		go s.handleConn(c)
+	}
+}
+
+func (s *Server) handleConn(c net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mx.Lock()
+		delete(s.conns, c)
+		s.mx.Unlock()
+		_ = c.Close()
+	}()
+
+	r := bufio.NewReader(c)
+	w := bufio.NewWriter(c)
+	authenticated := false
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		reply := s.exec(args, &authenticated)
+		if err := writeReply(w, reply); err != nil {
+			return
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	reply, err := redis.ReadReply(r)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]any)
+	if !ok || len(items) == 0 {
+		return nil, errors.New("redistest: command is not an array")
+	}
+	args := make([]string, 0, len(items))
+	for _, item := range items {
+		arg, ok := item.(string)
+		if !ok {
+			return nil, errors.New("redistest: command argument is not a string")
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+func (s *Server) exec(args []string, authenticated *bool) any {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	name := strings.ToUpper(args[0])
+	s.commands = append(s.commands, name)
+
+	if name == "AUTH" {
+		if len(args) != 2 || args[1] != s.password {
+			return redis.Error("WRONGPASS invalid username-password pair")
+		}
+		*authenticated = true
+		return Status("OK")
+	}
+	if s.password != "" && !*authenticated {
+		return redis.Error("NOAUTH Authentication required.")
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		return redis.Error(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+	}
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		return wrongArgs(name)
+	}
+	return cmd.run(&DB{s: s}, args)
+}
+
+// command runs with the arguments including its name. A positive arity is the exact number of
+// arguments, a negative one the minimum.
+type command struct {
+	arity int
+	run   func(db *DB, args []string) any
+}
+
+var commands = map[string]command{
+	"PING": {1, func(db *DB, args []string) any {
+		return Status("PONG")
+	}},
+	"GET": {2, func(db *DB, args []string) any {
+		if value, ok := db.Get(args[1]); ok {
+			return value
+		}
+		return nil
+	}},
+	"SET": {3, func(db *DB, args []string) any {
+		db.Set(args[1], args[2])
+		return Status("OK")
+	}},
+	"INCR": {2, func(db *DB, args []string) any {
+		n, err := db.Incr(args[1])
+		if err != nil {
+			return redis.Error(err.Error())
+		}
+		return n
+	}},
+	"PEXPIRE": {3, func(db *DB, args []string) any {
+		ms, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return redis.Error("ERR value is not an integer or out of range")
+		}
+		if db.PExpire(args[1], ms) {
+			return int64(1)
+		}
+		return int64(0)
+	}},
+	"PTTL": {2, func(db *DB, args []string) any {
+		return db.PTTL(args[1])
+	}},
+	"DEL": {-2, func(db *DB, args []string) any {
+		return db.Del(args[1:]...)
+	}},
+	"EVAL": {-3, func(db *DB, args []string) any {
+		return db.s.execScript(db, args)
+	}},
+	"EVALSHA": {-3, func(db *DB, args []string) any {
+		return db.s.execScript(db, args)
+	}},
+}
+
+func (s *Server) execScript(db *DB, args []string) any {
+	numKeys, err := strconv.Atoi(args[2])
+	if err != nil || numKeys < 0 || numKeys > len(args)-3 {
+		return redis.Error("ERR Number of keys can't be greater than number of args")
+	}
+
+	hash := args[1]
+	if strings.EqualFold(args[0], "EVAL") {
+		known, ok := s.sources[args[1]]
+		if !ok {
+			return redis.Error("ERR redistest: no Go function registered for the script")
+		}
+		hash = known
+		s.loaded[hash] = true
+	} else if !s.loaded[hash] {
+		return redis.Error("NOSCRIPT No matching script. Please use EVAL.")
+	}
+
+	reply, err := s.scripts[hash](db, args[3:3+numKeys], args[3+numKeys:])
+	if err != nil {
+		return redis.Error("ERR " + err.Error())
+	}
+	return reply
+}
+
+func wrongArgs(name string) redis.Error {
+	return redis.Error(fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+}
+
+func writeReply(w io.Writer, reply any) error {
+	var err error
+	switch v := reply.(type) {
+	case nil:
+		_, err = io.WriteString(w, "$-1\r\n")
+	case Status:
+		_, err = fmt.Fprintf(w, "+%s\r\n", v)
+	case redis.Error:
+		_, err = fmt.Fprintf(w, "-%s\r\n", v)
+	case string:
+		_, err = fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v), v)
+	case int64:
+		_, err = fmt.Fprintf(w, ":%d\r\n", v)
+	case int:
+		_, err = fmt.Fprintf(w, ":%d\r\n", v)
+	case []any:
+		if _, err = fmt.Fprintf(w, "*%d\r\n", len(v)); err != nil {
+			return err
+		}
+		for _, item := range v {
+			if err = writeReply(w, item); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("redistest: unsupported reply type %T", reply)
+	}
+	return err
+}
+
+func scriptHash(src string) string {
+	sum := sha1.Sum([]byte(src))
+	return hex.EncodeToString(sum[:])
+}
+
+// DB gives scripts access to the keys of the server.
+type DB struct {
+	s *Server
+}
+
+func (db *DB) Get(key string) (string, bool) {
+	e, ok := db.s.data[key]
+	if !ok {
+		return "", false
+	}
+	if !e.expiresAt.IsZero() && !db.s.now().Before(e.expiresAt) {
+		delete(db.s.data, key)
+		return "", false
+	}
+	return e.value, true
+}
+
+// Set sets the value of the key and clears its expiry.
+func (db *DB) Set(key, value string) {
+	db.s.data[key] = entry{value: value}
+}
+
+func (db *DB) Incr(key string) (int64, error) {
+	value, ok := db.Get(key)
+	var n int64
+	if ok {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, errors.New("ERR value is not an integer or out of range")
+		}
+		n = parsed
+	}
+	n++
+	e := db.s.data[key]
+	e.value = strconv.FormatInt(n, 10)
+	db.s.data[key] = e
+	return n, nil
+}
+
+// PExpire sets the key to expire in ms milliseconds and reports whether the key exists.
+func (db *DB) PExpire(key string, ms int64) bool {
+	if _, ok := db.Get(key); !ok {
+		return false
+	}
+	e := db.s.data[key]
+	e.expiresAt = db.s.now().Add(time.Duration(ms) * time.Millisecond)
+	db.s.data[key] = e
+	return true
+}
+
+// PTTL returns the milliseconds until the key expires, -1 without expiry and -2 for missing keys.
+func (db *DB) PTTL(key string) int64 {
+	if _, ok := db.Get(key); !ok {
+		return -2
+	}
+	e := db.s.data[key]
+	if e.expiresAt.IsZero() {
+		return -1
+	}
+	return e.expiresAt.Sub(db.s.now()).Milliseconds()
+}
+
+func (db *DB) Del(keys ...string) int64 {
+	var deleted int64
+	for _, key := range keys {
+		if _, ok := db.Get(key); ok {
+			delete(db.s.data, key)
+			deleted++
+		}
+	}
+	return deleted
+}
@@ -0,0 +1,40 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+)
+
+// Script is a Lua script run atomically by the server. It is sent in full only when the server
+// does not have it cached yet.
+type Script struct {
+	src  string
+	hash string
+}
+
+func NewScript(src string) *Script {
+	// SHA-1 is how Redis identifies scripts, it is not used for security.
+	sum := sha1.Sum([]byte(src))
+	return &Script{src: src, hash: hex.EncodeToString(sum[:])}
+}
+
+func (s *Script) Hash() string {
+	return s.hash
+}
+
+// Run runs the script with EVALSHA, falling back to EVAL when the server does not know it.
+func (s *Script) Run(ctx context.Context, c *Client, keys []string, args ...string) (any, error) {
+	cmdArgs := make([]string, 0, 3+len(keys)+len(args))
+	cmdArgs = append(cmdArgs, "EVALSHA", s.hash, strconv.Itoa(len(keys)))
+	cmdArgs = append(cmdArgs, keys...)
+	cmdArgs = append(cmdArgs, args...)
+
+	reply, err := c.Do(ctx, cmdArgs...)
+	if IsNoScript(err) {
+		cmdArgs[0], cmdArgs[1] = "EVAL", s.src
+		return c.Do(ctx, cmdArgs...)
+	}
+	return reply, err
+}
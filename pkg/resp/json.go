@@ -3,7 +3,9 @@ package resp
 import (
 	"encoding/json"
 	results "github.com/DjordjeVuckovic/weather-radar/pkg/result"
+	"math"
 	"net/http"
+	"strconv"
 )
 
 func WriteJSON(w http.ResponseWriter, code int, body interface{}) error {
@@ -14,6 +16,10 @@ func WriteJSON(w http.ResponseWriter, code int, body interface{}) error {
 
 func WriteProblemJSON(w http.ResponseWriter, p *results.Err) error {
 	w.Header().Set("Content-Type", "application/problem+json")
+	if p.RetryAfter > 0 {
+		// Retry-After is in whole seconds, rounded up so clients do not retry too early.
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(p.RetryAfter.Seconds())), 10))
+	}
 	w.WriteHeader(p.Status)
 	return json.NewEncoder(w).Encode(p)
 }
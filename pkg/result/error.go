@@ -2,16 +2,20 @@ package result
 
 import (
 	"net/http"
+	"time"
 )
 
 type ErrTitle string
 
 const (
-	Validation     ErrTitle = "Validation problem"
-	NotFound       ErrTitle = "Not Found"
-	Conflict       ErrTitle = "Conflict"
-	UnAuthorized   ErrTitle = "Unauthorized"
-	GatewayTimeout ErrTitle = "Request Timeout"
+	Validation      ErrTitle = "Validation problem"
+	NotFound        ErrTitle = "Not Found"
+	Conflict        ErrTitle = "Conflict"
+	UnAuthorized    ErrTitle = "Unauthorized"
+	Forbidden       ErrTitle = "Forbidden"
+	GatewayTimeout  ErrTitle = "Request Timeout"
+	TooManyRequests ErrTitle = "Too Many Requests"
+	Unavailable     ErrTitle = "Service Unavailable"
 )
 
 type Err struct {
@@ -19,6 +23,8 @@ type Err struct {
 	Title  ErrTitle `json:"title"`
 	Detail string   `json:"detail"`
 	Type   string   `json:"type"`
+	// RetryAfter is sent as the Retry-After header when positive.
+	RetryAfter time.Duration `json:"-"`
 }
 
 func (e *Err) Error() string {
@@ -40,12 +46,18 @@ func getErrTitle(code int) ErrTitle {
 		return Validation
 	case http.StatusUnauthorized:
 		return UnAuthorized
+	case http.StatusForbidden:
+		return Forbidden
 	case http.StatusNotFound:
 		return NotFound
 	case http.StatusConflict:
 		return Conflict
 	case http.StatusGatewayTimeout:
 		return GatewayTimeout
+	case http.StatusTooManyRequests:
+		return TooManyRequests
+	case http.StatusServiceUnavailable:
+		return Unavailable
 
 	}
 	return "Internal Server Error"
@@ -57,10 +69,16 @@ func getErrType(code int) string {
 		return "https://tools.ietf.org/html/rfc7807#section-3.1"
 	case http.StatusUnauthorized:
 		return "https://tools.ietf.org/html/rfc7235#section-3.1"
+	case http.StatusForbidden:
+		return "https://tools.ietf.org/html/rfc7231#section-6.5.3"
 	case http.StatusNotFound:
 		return "https://tools.ietf.org/html/rfc7231#section-6.5.4"
 	case http.StatusConflict:
 		return "https://tools.ietf.org/html/rfc7231#section-6.5.8"
+	case http.StatusTooManyRequests:
+		return "https://tools.ietf.org/html/rfc6585#section-4"
+	case http.StatusServiceUnavailable:
+		return "https://tools.ietf.org/html/rfc7231#section-6.6.4"
 	}
 	return "https://tools.ietf.org/html/rfc7231#section-6.6.1"
 }
@@ -84,3 +102,14 @@ func TimeoutErr() *Err {
 func UnauthorizedErr(detail string) *Err {
 	return NewErr(http.StatusUnauthorized, detail)
 }
+
+// TooManyRequestsErr tells the client to retry after retryAfter.
+func TooManyRequestsErr(detail string, retryAfter time.Duration) *Err {
+	err := NewErr(http.StatusTooManyRequests, detail)
+	err.RetryAfter = retryAfter
+	return err
+}
+
+func ForbiddenErr(detail string) *Err {
+	return NewErr(http.StatusForbidden, detail)
+}
@@ -3,6 +3,7 @@ package result
 import (
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestNewErr(t *testing.T) {
@@ -47,6 +48,15 @@ func TestNewErr(t *testing.T) {
 				Detail: "Resource conflict",
 			},
 		},
+		{
+			status: http.StatusServiceUnavailable,
+			detail: "Try again later",
+			expected: Err{
+				Status: http.StatusServiceUnavailable,
+				Title:  Unavailable,
+				Detail: "Try again later",
+			},
+		},
 		{
 			status: http.StatusInternalServerError,
 			detail: "Unexpected error",
@@ -116,3 +126,25 @@ func TestUnauthorizedErr(t *testing.T) {
 		t.Errorf("Expected Status %d, got %d", http.StatusUnauthorized, err.Status)
 	}
 }
+
+func TestForbiddenErr(t *testing.T) {
+	err := ForbiddenErr("Forbidden access")
+
+	if err.Status != http.StatusForbidden || err.Title != Forbidden {
+		t.Errorf("Expected Status %d and title %s, got %d and %s", http.StatusForbidden, Forbidden, err.Status, err.Title)
+	}
+}
+
+func TestTooManyRequestsErr(t *testing.T) {
+	err := TooManyRequestsErr("Rate limit exceeded", 30*time.Second)
+
+	if err.Status != http.StatusTooManyRequests || err.Title != TooManyRequests {
+		t.Errorf("Expected Status %d and Title %s, got %d and %s", http.StatusTooManyRequests, TooManyRequests, err.Status, err.Title)
+	}
+	if err.Type != "https://tools.ietf.org/html/rfc6585#section-4" {
+		t.Errorf("Expected the RFC 6585 type, got %s", err.Type)
+	}
+	if err.RetryAfter != 30*time.Second {
+		t.Errorf("Expected RetryAfter 30s, got %v", err.RetryAfter)
+	}
+}
@@ -19,6 +19,8 @@ type Server struct {
 	mux        *http.ServeMux
 	httpServer *http.Server
 	middleware []MiddlewareFunc
+	// routeMiddleware creates middleware for each route, run after the global middleware.
+	routeMiddleware []RouteMiddlewareFunc
 
 	gracefulShutdownTimeout time.Duration
 	ShutdownSig             chan struct{}
@@ -30,6 +32,9 @@ type HandlerFunc func(http.ResponseWriter, *http.Request) error
 
 type MiddlewareFunc func(next HandlerFunc) HandlerFunc
 
+// RouteMiddlewareFunc returns the middleware of a route when it is registered, or nil for none.
+type RouteMiddlewareFunc func(method, route string) MiddlewareFunc
+
 const (
 	defaultGracefulShutdownTimeout = 10 * time.Second
 )
@@ -93,6 +98,12 @@ func (s *Server) Use(mw ...MiddlewareFunc) {
 	s.middleware = append(s.middleware, mw...)
 }
 
+// UseRoute adds middleware created for every route registered afterward, after the global
+// middleware and before the route's own.
+func (s *Server) UseRoute(mw ...RouteMiddlewareFunc) {
+	s.routeMiddleware = append(s.routeMiddleware, mw...)
+}
+
 func (s *Server) HandleFunc(pattern string, h func(http.ResponseWriter, *http.Request)) {
 
 	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
@@ -143,8 +154,19 @@ func (s *Server) SetupSwagger() {
 }
 
 func (s *Server) handle(method, route string, h HandlerFunc, mw ...MiddlewareFunc) {
-	pattern := method + " " + normalizePathSlash(route)
-	s.mux.HandleFunc(pattern, s.wrapMiddleware(h, mw...))
+	route = normalizePathSlash(route)
+	pattern := method + " " + route
+	s.mux.HandleFunc(pattern, s.wrapMiddleware(h, append(s.createRouteMiddleware(method, route), mw...)...))
+}
+
+func (s *Server) createRouteMiddleware(method, route string) []MiddlewareFunc {
+	var mw []MiddlewareFunc
+	for _, create := range s.routeMiddleware {
+		if m := create(method, route); m != nil {
+			mw = append(mw, m)
+		}
+	}
+	return mw
 }
 
 func (s *Server) wrapMiddleware(h HandlerFunc, mw ...MiddlewareFunc) http.HandlerFunc {
@@ -90,6 +90,39 @@ func TestServerWrapMiddlewareOrder(t *testing.T) {
 	}
 }
 
+func TestServerUseRoute(t *testing.T) {
+	s := NewServer(":1312")
+
+	var registered []string
+	s.UseRoute(func(method, route string) MiddlewareFunc {
+		registered = append(registered, method+" "+route)
+		if route != "/limited" {
+			return nil
+		}
+		return TestMiddleware("X-Route-Middleware", method+" "+route)
+	})
+	s.GET("limited", TestOKHandler())
+	s.POST("/other", TestOKHandler())
+
+	if len(registered) != 2 || registered[0] != "GET /limited" || registered[1] != "POST /other" {
+		t.Fatalf("Expected route middleware created for both routes, got %v", registered)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, req)
+	if got := rec.Header().Get("X-Route-Middleware"); got != "GET /limited" {
+		t.Errorf("Expected route middleware of GET /limited, got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/other", nil)
+	rec = httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Route-Middleware") != "" {
+		t.Errorf("Expected no route middleware for POST /other, got %d %v", rec.Code, rec.Header())
+	}
+}
+
 func TestServerNotFoundHandler(t *testing.T) {
 	s := NewServer(":1312")
 	s.SetupNotFoundHandler()
@@ -15,3 +15,25 @@ func UnixToLocal(unix int64, tzOffset int) string {
 
 	return localTime.Format("2006-01-02 15:04")
 }
+
+func UnixToLocalDate(unix int64, tzOffset int) string {
+	t := time.Unix(unix, 0).UTC()
+
+	localTime := t.Add(time.Duration(tzOffset) * time.Second)
+
+	return localTime.Format(time.DateOnly)
+}
+
+// ParseDate parses a date in the YYYY-MM-DD format as UTC midnight.
+func ParseDate(date string) (time.Time, error) {
+	return time.Parse(time.DateOnly, date)
+}
+
+// DatesBetween returns every calendar date from `from` to `to`, both inclusive.
+func DatesBetween(from, to time.Time) []time.Time {
+	var dates []time.Time
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d)
+	}
+	return dates
+}
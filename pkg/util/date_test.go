@@ -43,3 +43,69 @@ func TestUnixToUTC(t *testing.T) {
 		}
 	}
 }
+
+func TestUnixToLocalDate(t *testing.T) {
+	tests := []struct {
+		unix         int64
+		tzOffset     int
+		expectedDate string
+	}{
+		{
+			unix:         1733054400, // 2024-12-01 12:00:00 UTC
+			tzOffset:     3600,
+			expectedDate: "2024-12-01",
+		},
+		{
+			unix:         1733094000, // 2024-12-01 23:00:00 UTC
+			tzOffset:     3600,
+			expectedDate: "2024-12-02",
+		},
+		{
+			unix:         1733011200, // 2024-12-01 00:00:00 UTC
+			tzOffset:     -18000,
+			expectedDate: "2024-11-30",
+		},
+	}
+
+	for _, test := range tests {
+		result := UnixToLocalDate(test.unix, test.tzOffset)
+		if result != test.expectedDate {
+			t.Errorf("UnixToLocalDate(%d, %d) = %s; want %s", test.unix, test.tzOffset, result, test.expectedDate)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	date, err := ParseDate("2024-10-27")
+	if err != nil {
+		t.Fatalf("ParseDate returned error: %v", err)
+	}
+	if date.Year() != 2024 || date.Month() != 10 || date.Day() != 27 {
+		t.Errorf("ParseDate(2024-10-27) = %v", date)
+	}
+
+	if _, err := ParseDate("27.10.2024"); err == nil {
+		t.Error("Expected error for invalid date format")
+	}
+}
+
+func TestDatesBetween(t *testing.T) {
+	from, _ := ParseDate("2024-02-27")
+	to, _ := ParseDate("2024-03-01")
+
+	dates := DatesBetween(from, to)
+
+	expected := []string{"2024-02-27", "2024-02-28", "2024-02-29", "2024-03-01"}
+	if len(dates) != len(expected) {
+		t.Fatalf("DatesBetween returned %d dates; want %d", len(dates), len(expected))
+	}
+	for i, date := range dates {
+		if date.Format("2006-01-02") != expected[i] {
+			t.Errorf("DatesBetween[%d] = %s; want %s", i, date.Format("2006-01-02"), expected[i])
+		}
+	}
+
+	if dates := DatesBetween(to, from); len(dates) != 0 {
+		t.Errorf("Expected no dates for reversed range, got %d", len(dates))
+	}
+}